@@ -7,9 +7,12 @@ import (
 	"cluster-agent/internal"
 	"cluster-agent/internal/api/handlers"
 	"cluster-agent/internal/api/middleware"
+	"cluster-agent/internal/audit"
+	"cluster-agent/internal/auth"
 	cache2 "cluster-agent/internal/cache"
 	"cluster-agent/internal/config"
 	"cluster-agent/internal/consumers"
+	"cluster-agent/internal/events"
 	"cluster-agent/internal/k8s"
 	"cluster-agent/internal/producers"
 	"cluster-agent/internal/services"
@@ -18,21 +21,15 @@ import (
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 	corelisters "k8s.io/client-go/listers/core/v1"
-	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/cache"
-	"time"
 )
 
 func ProvideK8sInterface(client *k8s.Client) kubernetes.Interface {
 	return client.GetClientset()
 }
 
-func ProvideRestConfig(client *k8s.Client) *rest.Config {
-	return client.GetConfig()
-}
-
-func ProvideInformerFactory(clientset kubernetes.Interface) informers.SharedInformerFactory {
-	return informers.NewSharedInformerFactory(clientset, 12*time.Hour)
+func ProvideInformerFactory(clientset kubernetes.Interface, cfg *config.Config) informers.SharedInformerFactory {
+	return informers.NewSharedInformerFactory(clientset, cfg.InformerResync)
 }
 
 func ProvideEventInformer(factory informers.SharedInformerFactory) cache.SharedIndexInformer {
@@ -46,20 +43,35 @@ func ProvidePodLister(factory informers.SharedInformerFactory) corelisters.PodLi
 func InitializeApp() (*internal.App, func(), error) {
 	wire.Build(
 		config.NewConfig,
+		config.NewManager,
+		wire.Bind(new(services.ReloadService), new(*config.Manager)),
 		k8s.NewClient,
+		k8s.NewClientProvider,
 
 		ProvideInformerFactory,
 		ProvideEventInformer,
 		ProvidePodLister,
 		ProvideK8sInterface,
-		ProvideRestConfig,
 
 		cache2.NewRedisClient,
 		cache2.NewTopologyCache,
 		wire.Bind(new(topology.TopologyCacheStorage), new(*cache2.TopologyCache)),
+		cache2.NewRevocationCache,
+		wire.Bind(new(services.RevocationStorage), new(*cache2.RevocationCache)),
+		cache2.NewTicketCache,
+		wire.Bind(new(services.TicketStorage), new(*cache2.TicketCache)),
+		cache2.NewAPIKeyCache,
+		wire.Bind(new(services.APIKeyStorage), new(*cache2.APIKeyCache)),
+		cache2.NewEventSpoolCache,
+		wire.Bind(new(consumers.EventSpool), new(*cache2.EventSpoolCache)),
+		cache2.NewRateLimitCache,
+		wire.Bind(new(middleware.RateLimiter), new(*cache2.RateLimitCache)),
 
 		handlers.HandlerSet,
+		auth.NewKeySet,
 		middleware.NewAuthorizedMiddleware,
+		middleware.NewAuditMiddleware,
+		middleware.NewRateLimitMiddleware,
 
 		// Services
 		services.NewDeploymentService,
@@ -75,9 +87,19 @@ func InitializeApp() (*internal.App, func(), error) {
 		services.NewConfigMapService,
 		services.NewSecretService,
 		services.NewNetworkInspectorService,
+		services.NewRevocationService,
+		services.NewAuditService,
+		services.NewTicketService,
+		services.NewAPIKeyService,
+		services.NewEventService,
+		audit.NewSinks,
 		topology.NewTopologyService,
 
+		events.NewSinks,
+		consumers.NewEventStats,
 		consumers.NewEventBatcher,
+		consumers.NewEventAggregator,
+		wire.Bind(new(services.EventPipelineService), new(*consumers.EventBatcher)),
 		producers.NewEventCollector,
 
 		internal.NewApp,
@@ -10,9 +10,12 @@ import (
 	"cluster-agent/internal"
 	"cluster-agent/internal/api/handlers"
 	"cluster-agent/internal/api/middleware"
+	"cluster-agent/internal/audit"
+	"cluster-agent/internal/auth"
 	"cluster-agent/internal/cache"
 	"cluster-agent/internal/config"
 	"cluster-agent/internal/consumers"
+	"cluster-agent/internal/events"
 	"cluster-agent/internal/k8s"
 	"cluster-agent/internal/producers"
 	"cluster-agent/internal/services"
@@ -20,62 +23,113 @@ import (
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/listers/core/v1"
-	"k8s.io/client-go/rest"
 	cache2 "k8s.io/client-go/tools/cache"
-	"time"
 )
 
 // Injectors from wire.go:
 
 func InitializeApp() (*internal.App, func(), error) {
-	client, err := k8s.NewClient()
+	configConfig, err := config.NewConfig()
 	if err != nil {
 		return nil, nil, err
 	}
-	kubernetesInterface := ProvideK8sInterface(client)
-	podService := services.NewPodService(kubernetesInterface)
+	client, err := k8s.NewClient(configConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+	clientProvider := k8s.NewClientProvider(client, configConfig)
+	podService := services.NewPodService(clientProvider)
 	podHandler := handlers.NewPodHandler(podService)
-	deploymentService := services.NewDeploymentService(kubernetesInterface)
+	deploymentService := services.NewDeploymentService(clientProvider)
 	deploymentHandler := handlers.NewDeploymentHandler(deploymentService)
-	namespaceService := services.NewNamespaceService(kubernetesInterface)
+	namespaceService := services.NewNamespaceService(clientProvider)
 	namespaceHandler := handlers.NewNamespaceHandler(namespaceService)
-	kubernetesServiceService := services.NewServiceService(kubernetesInterface)
+	kubernetesServiceService := services.NewServiceService(clientProvider)
 	serviceHandler := handlers.NewServiceHandler(kubernetesServiceService)
-	nodeService := services.NewNodeService(kubernetesInterface)
+	nodeService := services.NewNodeService(clientProvider)
 	nodeHandler := handlers.NewNodeHandler(nodeService)
-	restConfig := ProvideRestConfig(client)
-	terminalService := services.NewTerminalService(kubernetesInterface, restConfig)
+	manager := config.NewManager(configConfig)
+	terminalService := services.NewTerminalService(clientProvider, manager)
 	terminalHandler := handlers.NewTerminalHandler(terminalService)
-	configConfig := config.NewConfig()
 	redisClient, cleanup, err := cache.NewRedisClient(configConfig)
 	if err != nil {
 		return nil, nil, err
 	}
-	topologyCache := cache.NewTopologyCache(redisClient)
+	topologyCache := cache.NewTopologyCache(redisClient, configConfig)
 	service := topology.NewTopologyService(topologyCache)
-	sharedInformerFactory := ProvideInformerFactory(kubernetesInterface)
+	kubernetesInterface := ProvideK8sInterface(client)
+	sharedInformerFactory := ProvideInformerFactory(kubernetesInterface, configConfig)
 	snapshotService := services.NewSnapshotService(sharedInformerFactory)
 	topologyHandler := handlers.NewTopologyHandler(service, snapshotService)
-	podLogsService := services.NewPodLogsService(kubernetesInterface)
+	podLogsService := services.NewPodLogsService(clientProvider)
 	podLogsHandler := handlers.NewPodLogsHandler(podLogsService)
-	configMapService := services.NewConfigMapService(kubernetesInterface)
+	configMapService := services.NewConfigMapService(clientProvider)
 	configMapHandler := handlers.NewConfigMapHandler(configMapService)
-	secretService := services.NewSecretService(kubernetesInterface)
+	secretService := services.NewSecretService(clientProvider, manager)
 	secretHandler := handlers.NewSecretHandler(secretService)
-	ingressService := services.NewIngressService(kubernetesInterface)
+	ingressService := services.NewIngressService(clientProvider)
 	ingressHandler := handlers.NewIngressHandler(ingressService)
 	podLister := ProvidePodLister(sharedInformerFactory)
-	pvcService := services.NewPVCService(kubernetesInterface, podLister)
+	pvcService := services.NewPVCService(clientProvider, podLister)
 	pvcHandler := handlers.NewPvcHandler(pvcService)
-	networkInspectorService := services.NewNetworkInspectorService(kubernetesInterface, restConfig)
+	networkInspectorService := services.NewNetworkInspectorService(clientProvider)
 	networkInspectorHandler := handlers.NewNetworkInspectorHandler(networkInspectorService)
-	handlerContainer := handlers.NewHandlerContainer(podHandler, deploymentHandler, namespaceHandler, serviceHandler, nodeHandler, terminalHandler, topologyHandler, podLogsHandler, configMapHandler, secretHandler, ingressHandler, pvcHandler, networkInspectorHandler)
-	authorizedMiddleware := middleware.NewAuthorizedMiddleware(configConfig)
-	eventBatcher := consumers.NewEventBatcher(configConfig)
+	revocationCache := cache.NewRevocationCache(redisClient)
+	revocationService := services.NewRevocationService(revocationCache, manager)
+	revocationHandler := handlers.NewRevocationHandler(revocationService)
+	v, cleanup2, err := audit.NewSinks(manager, redisClient)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	auditService := services.NewAuditService(v)
+	auditHandler := handlers.NewAuditHandler(auditService)
+	ticketCache := cache.NewTicketCache(redisClient)
+	ticketService := services.NewTicketService(ticketCache)
+	ticketHandler := handlers.NewTicketHandler(ticketService)
+	apiKeyCache := cache.NewAPIKeyCache(redisClient)
+	apiKeyService := services.NewAPIKeyService(apiKeyCache)
+	apiKeyHandler := handlers.NewAPIKeyHandler(apiKeyService)
+	routeCatalog := handlers.NewRouteCatalog()
+	meHandler := handlers.NewMeHandler(routeCatalog)
+	configHandler := handlers.NewConfigHandler(manager)
+	v2, cleanup3, err := events.NewSinks(manager, redisClient)
+	if err != nil {
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	eventSpoolCache := cache.NewEventSpoolCache(redisClient, configConfig)
+	eventStats := consumers.NewEventStats()
+	eventBatcher := consumers.NewEventBatcher(manager, v2, eventSpoolCache, eventStats)
+	eventPipelineHandler := handlers.NewEventPipelineHandler(eventBatcher)
 	sharedIndexInformer := ProvideEventInformer(sharedInformerFactory)
-	eventCollector := producers.NewEventCollector(eventBatcher, sharedIndexInformer)
-	app := internal.NewApp(handlerContainer, authorizedMiddleware, eventCollector, eventBatcher, sharedInformerFactory)
+	eventService := services.NewEventService(sharedIndexInformer, manager)
+	eventHandler := handlers.NewEventHandler(eventService)
+	handlerContainer := handlers.NewHandlerContainer(podHandler, deploymentHandler, namespaceHandler, serviceHandler, nodeHandler, terminalHandler, topologyHandler, podLogsHandler, configMapHandler, secretHandler, ingressHandler, pvcHandler, networkInspectorHandler, revocationHandler, auditHandler, ticketHandler, apiKeyHandler, meHandler, configHandler, eventPipelineHandler, eventHandler)
+	keySet, err := auth.NewKeySet(manager)
+	if err != nil {
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	authorizedMiddleware := middleware.NewAuthorizedMiddleware(manager, keySet, revocationService, ticketService, apiKeyService)
+	auditMiddleware := middleware.NewAuditMiddleware(auditService)
+	rateLimitCache := cache.NewRateLimitCache(redisClient)
+	rateLimitMiddleware := middleware.NewRateLimitMiddleware(manager, rateLimitCache)
+	eventAggregator := consumers.NewEventAggregator(configConfig, eventBatcher, eventStats)
+	eventCollector := producers.NewEventCollector(eventAggregator, sharedIndexInformer, manager, eventStats)
+	app, err := internal.NewApp(handlerContainer, authorizedMiddleware, auditMiddleware, rateLimitMiddleware, eventCollector, eventAggregator, eventBatcher, sharedInformerFactory, keySet, auditService, routeCatalog, manager, configConfig)
+	if err != nil {
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
 	return app, func() {
+		cleanup3()
+		cleanup2()
 		cleanup()
 	}, nil
 }
@@ -86,12 +140,8 @@ func ProvideK8sInterface(client *k8s.Client) kubernetes.Interface {
 	return client.GetClientset()
 }
 
-func ProvideRestConfig(client *k8s.Client) *rest.Config {
-	return client.GetConfig()
-}
-
-func ProvideInformerFactory(clientset kubernetes.Interface) informers.SharedInformerFactory {
-	return informers.NewSharedInformerFactory(clientset, 12*time.Hour)
+func ProvideInformerFactory(clientset kubernetes.Interface, cfg *config.Config) informers.SharedInformerFactory {
+	return informers.NewSharedInformerFactory(clientset, cfg.InformerResync)
 }
 
 func ProvideEventInformer(factory informers.SharedInformerFactory) cache2.SharedIndexInformer {
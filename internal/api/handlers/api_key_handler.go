@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"cluster-agent/internal/api/middleware"
+	"cluster-agent/internal/api/responses"
+	"cluster-agent/internal/models"
+	"cluster-agent/internal/services"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type APIKeyHandler struct {
+	service services.APIKeyService
+}
+
+func NewAPIKeyHandler(service services.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{
+		service: service,
+	}
+}
+
+// Create issues a key with at most the caller's own permissions. The key is
+// only returned in this response.
+func (h *APIKeyHandler) Create(c *gin.Context) {
+	claims := middleware.GetUserClaims(c)
+	if claims == nil {
+		c.JSON(http.StatusUnauthorized, responses.Error("missing jwt token claims"))
+		return
+	}
+
+	var request models.CreateAPIKeyParams
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, responses.Error(err.Error()))
+		return
+	}
+
+	for _, permission := range request.Permissions {
+		if !claims.CanGrant(permission, request.Namespaces) {
+			c.JSON(http.StatusForbidden, responses.Error("cannot grant permission "+permission.String()))
+			return
+		}
+	}
+
+	key, err := h.service.Create(c.Request.Context(), claims.UserId, request)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, responses.Error(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusCreated, responses.Success(key))
+}
+
+func (h *APIKeyHandler) List(c *gin.Context) {
+	keys, err := h.service.List(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, responses.Error(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, responses.Success(keys))
+}
+
+func (h *APIKeyHandler) Revoke(c *gin.Context) {
+	err := h.service.Revoke(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, services.ErrNotFound) {
+			c.JSON(http.StatusNotFound, responses.Error(err.Error()))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, responses.Error(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, responses.Success("OK"))
+}
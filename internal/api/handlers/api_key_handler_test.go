@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"bytes"
+	"cluster-agent/internal/auth"
+	"cluster-agent/internal/auth/permissions"
+	"cluster-agent/internal/models"
+	"cluster-agent/internal/services"
+	"cluster-agent/internal/services/mock"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	testifyMock "github.com/stretchr/testify/mock"
+)
+
+func TestAPIKeyHandler_Create(t *testing.T) {
+	type testCase struct {
+		name          string
+		claims        *auth.UserClaims
+		inputBody     string
+		mockBehavior  func(m *mock.APIKeyServiceMock)
+		expectedCode  int
+		expectedError string
+	}
+
+	admin := &auth.UserClaims{UserId: "42", Permissions: []permissions.Permission{"*"}}
+	deployer := &auth.UserClaims{UserId: "7", Permissions: []permissions.Permission{"deployments:*@payments"}}
+
+	tests := []testCase{
+		{
+			name:      "Create key",
+			claims:    admin,
+			inputBody: `{"name": "ci", "permissions": ["deployments:scale"]}`,
+			mockBehavior: func(m *mock.APIKeyServiceMock) {
+				m.On("Create", testifyMock.Anything, "42", models.CreateAPIKeyParams{
+					Name:        "ci",
+					Permissions: []permissions.Permission{permissions.DeploymentsScale},
+				}).Return(&models.CreatedAPIKey{APIKey: models.APIKey{Id: "abc"}, Key: "abc.secret"}, nil)
+			},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:      "Delegate within own namespace",
+			claims:    deployer,
+			inputBody: `{"name": "ci", "permissions": ["deployments:scale"], "namespaces": ["payments"]}`,
+			mockBehavior: func(m *mock.APIKeyServiceMock) {
+				m.On("Create", testifyMock.Anything, "7", testifyMock.Anything).
+					Return(&models.CreatedAPIKey{APIKey: models.APIKey{Id: "abc"}, Key: "abc.secret"}, nil)
+			},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:          "Escalate to all namespaces",
+			claims:        deployer,
+			inputBody:     `{"name": "ci", "permissions": ["deployments:scale"]}`,
+			mockBehavior:  func(m *mock.APIKeyServiceMock) {},
+			expectedCode:  http.StatusForbidden,
+			expectedError: "cannot grant permission deployments:scale",
+		},
+		{
+			name:          "Escalate to broader wildcard",
+			claims:        deployer,
+			inputBody:     `{"name": "ci", "permissions": ["*@payments"]}`,
+			mockBehavior:  func(m *mock.APIKeyServiceMock) {},
+			expectedCode:  http.StatusForbidden,
+			expectedError: "cannot grant permission *@payments",
+		},
+		{
+			name:          "Missing permissions",
+			claims:        admin,
+			inputBody:     `{"name": "ci"}`,
+			mockBehavior:  func(m *mock.APIKeyServiceMock) {},
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "required",
+		},
+		{
+			name:      "Storage error",
+			claims:    admin,
+			inputBody: `{"name": "ci", "permissions": ["pods:view"]}`,
+			mockBehavior: func(m *mock.APIKeyServiceMock) {
+				m.On("Create", testifyMock.Anything, "42", testifyMock.Anything).Return(nil, assert.AnError)
+			},
+			expectedCode:  http.StatusInternalServerError,
+			expectedError: "assert.AnError general error for testing",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := new(mock.APIKeyServiceMock)
+			tc.mockBehavior(svc)
+
+			r := setupRouter()
+			r.POST("/apikeys", func(c *gin.Context) {
+				c.Set("claims", tc.claims)
+				c.Next()
+			}, NewAPIKeyHandler(svc).Create)
+
+			w := performRequest(r, "POST", "/apikeys", bytes.NewBufferString(tc.inputBody))
+
+			assert.Equal(t, tc.expectedCode, w.Code)
+
+			if tc.expectedError != "" {
+				assert.Contains(t, w.Body.String(), tc.expectedError)
+			} else {
+				response := parseResponse[models.CreatedAPIKey](t, w)
+				assert.Equal(t, "abc.secret", response.Data.Key)
+			}
+
+			svc.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAPIKeyHandler_List(t *testing.T) {
+	svc := new(mock.APIKeyServiceMock)
+	svc.On("List", testifyMock.Anything).Return([]models.APIKey{{Id: "abc", Name: "ci"}}, nil)
+
+	r := setupRouter()
+	r.GET("/apikeys", NewAPIKeyHandler(svc).List)
+
+	w := performRequest(r, "GET", "/apikeys", nil)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	response := parseResponse[[]models.APIKey](t, w)
+	assert.Len(t, response.Data, 1)
+	assert.NotContains(t, w.Body.String(), "secret_hash")
+
+	svc.AssertExpectations(t)
+}
+
+func TestAPIKeyHandler_Revoke(t *testing.T) {
+	type testCase struct {
+		name         string
+		revokeErr    error
+		expectedCode int
+	}
+
+	tests := []testCase{
+		{name: "Revoked", revokeErr: nil, expectedCode: http.StatusOK},
+		{name: "Not Found", revokeErr: services.ErrNotFound, expectedCode: http.StatusNotFound},
+		{name: "Storage error", revokeErr: assert.AnError, expectedCode: http.StatusInternalServerError},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := new(mock.APIKeyServiceMock)
+			svc.On("Revoke", testifyMock.Anything, "abc").Return(tc.revokeErr)
+
+			r := setupRouter()
+			r.DELETE("/apikeys/:id", NewAPIKeyHandler(svc).Revoke)
+
+			w := performRequest(r, "DELETE", "/apikeys/abc", nil)
+
+			assert.Equal(t, tc.expectedCode, w.Code)
+			svc.AssertExpectations(t)
+		})
+	}
+}
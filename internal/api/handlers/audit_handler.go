@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"cluster-agent/internal/api/middleware"
+	"cluster-agent/internal/api/responses"
+	"cluster-agent/internal/models"
+	"cluster-agent/internal/services"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuditHandler struct {
+	service services.AuditService
+}
+
+func NewAuditHandler(service services.AuditService) *AuditHandler {
+	return &AuditHandler{
+		service: service,
+	}
+}
+
+func (h *AuditHandler) Query(c *gin.Context) {
+	var query models.AuditQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, responses.Error(err.Error()))
+		return
+	}
+
+	query.Scope = middleware.GetNamespaceScope(c)
+
+	entries, err := h.service.Query(c.Request.Context(), query)
+	if err != nil {
+		if errors.Is(err, services.ErrAuditQueryUnsupported) {
+			c.JSON(http.StatusNotImplemented, responses.Error(err.Error()))
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, responses.Error(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, responses.Success(entries))
+}
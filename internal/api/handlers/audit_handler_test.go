@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"cluster-agent/internal/auth"
+	"cluster-agent/internal/models"
+	"cluster-agent/internal/services"
+	"cluster-agent/internal/services/mock"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	testifyMock "github.com/stretchr/testify/mock"
+)
+
+func TestAuditHandler_Query(t *testing.T) {
+	type testCase struct {
+		name          string
+		queryString   string
+		mockBehavior  func(m *mock.AuditServiceMock)
+		expectedCode  int
+		expectedError string
+		expectedData  []models.AuditEntry
+	}
+
+	entry := models.AuditEntry{UserId: "42", Action: "deployments.delete"}
+
+	tests := []testCase{
+		{
+			name:        "Success",
+			queryString: "?user_id=42&action=deployments.delete&since=2025-01-01T00:00:00Z&limit=10",
+			mockBehavior: func(m *mock.AuditServiceMock) {
+				m.On("Query", testifyMock.Anything, models.AuditQuery{
+					UserId: "42",
+					Action: "deployments.delete",
+					Since:  time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+					Limit:  10,
+				}).Return([]models.AuditEntry{entry}, nil)
+			},
+			expectedCode: http.StatusOK,
+			expectedData: []models.AuditEntry{entry},
+		},
+		{
+			name:          "Invalid limit",
+			queryString:   "?limit=5000",
+			mockBehavior:  func(m *mock.AuditServiceMock) {},
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "Limit",
+		},
+		{
+			name:        "No queryable sink",
+			queryString: "",
+			mockBehavior: func(m *mock.AuditServiceMock) {
+				m.On("Query", testifyMock.Anything, models.AuditQuery{}).
+					Return([]models.AuditEntry(nil), services.ErrAuditQueryUnsupported)
+			},
+			expectedCode:  http.StatusNotImplemented,
+			expectedError: services.ErrAuditQueryUnsupported.Error(),
+		},
+		{
+			name:        "Internal error",
+			queryString: "",
+			mockBehavior: func(m *mock.AuditServiceMock) {
+				m.On("Query", testifyMock.Anything, models.AuditQuery{}).
+					Return([]models.AuditEntry(nil), assert.AnError)
+			},
+			expectedCode:  http.StatusInternalServerError,
+			expectedError: "assert.AnError general error for testing",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := new(mock.AuditServiceMock)
+			tc.mockBehavior(svc)
+
+			r := setupRouter()
+			r.GET("/audit", NewAuditHandler(svc).Query)
+
+			w := performRequest(r, "GET", "/audit"+tc.queryString, nil)
+
+			assert.Equal(t, tc.expectedCode, w.Code)
+
+			if tc.expectedError != "" {
+				assert.Contains(t, w.Body.String(), tc.expectedError)
+			} else {
+				resp := parseResponse[[]models.AuditEntry](t, w)
+				assert.Equal(t, tc.expectedData, resp.Data)
+			}
+
+			svc.AssertExpectations(t)
+		})
+	}
+}
+
+// The scope goes to the service, so sinks apply it before the limit.
+func TestAuditHandler_Query_NamespaceScope(t *testing.T) {
+	scope := auth.NewNamespaceScope("payments")
+	entry := models.AuditEntry{UserId: "42", Target: models.AuditTarget{Namespace: "payments"}}
+
+	svc := new(mock.AuditServiceMock)
+	svc.On("Query", testifyMock.Anything, models.AuditQuery{Limit: 1, Scope: scope}).
+		Return([]models.AuditEntry{entry}, nil)
+
+	r := setupRouter()
+	r.GET("/audit", withNamespaceScope(scope), NewAuditHandler(svc).Query)
+
+	w := performRequest(r, "GET", "/audit?limit=1", nil)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	resp := parseResponse[[]models.AuditEntry](t, w)
+	assert.Equal(t, []models.AuditEntry{entry}, resp.Data)
+
+	svc.AssertExpectations(t)
+}
@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"cluster-agent/internal/api/responses"
+	"cluster-agent/internal/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ConfigHandler struct {
+	service services.ReloadService
+}
+
+func NewConfigHandler(service services.ReloadService) *ConfigHandler {
+	return &ConfigHandler{
+		service: service,
+	}
+}
+
+// Status reports the active configuration version and the last reload, so
+// operators can check that every agent picked up a change.
+func (h *ConfigHandler) Status(c *gin.Context) {
+	c.JSON(http.StatusOK, responses.Success(h.service.Status()))
+}
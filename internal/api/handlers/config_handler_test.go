@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"cluster-agent/internal/config"
+	"cluster-agent/internal/services/mock"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigHandler_Status(t *testing.T) {
+	loadedAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	mockService := new(mock.ReloadServiceMock)
+	mockService.On("Status").Return(config.ReloadStatus{
+		Version:         3,
+		Checksum:        "abc123",
+		Files:           []string{"/etc/agent/config.yaml"},
+		LoadedAt:        loadedAt,
+		Trigger:         "SIGHUP",
+		LastAttempt:     loadedAt,
+		RestartRequired: []string{"LISTEN_ADDR"},
+	})
+
+	r := setupRouter()
+	r.GET("/config/status", NewConfigHandler(mockService).Status)
+
+	w := performRequest(r, "GET", "/config/status", nil)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	response := parseResponse[config.ReloadStatus](t, w)
+	assert.Equal(t, int64(3), response.Data.Version)
+	assert.Equal(t, "abc123", response.Data.Checksum)
+	assert.Equal(t, "SIGHUP", response.Data.Trigger)
+	assert.True(t, loadedAt.Equal(response.Data.LoadedAt))
+	assert.Equal(t, []string{"LISTEN_ADDR"}, response.Data.RestartRequired)
+
+	mockService.AssertExpectations(t)
+}
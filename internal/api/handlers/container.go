@@ -17,6 +17,15 @@ var HandlerSet = wire.NewSet(
 	NewIngressHandler,
 	NewPvcHandler,
 	NewNetworkInspectorHandler,
+	NewRevocationHandler,
+	NewAuditHandler,
+	NewTicketHandler,
+	NewAPIKeyHandler,
+	NewMeHandler,
+	NewConfigHandler,
+	NewEventPipelineHandler,
+	NewEventHandler,
+	NewRouteCatalog,
 )
 
 type HandlerContainer struct {
@@ -33,6 +42,14 @@ type HandlerContainer struct {
 	Ingresses        *IngressHandler
 	Pvcs             *PvcHandler
 	NetworkInspector *NetworkInspectorHandler
+	Revocation       *RevocationHandler
+	Audit            *AuditHandler
+	Ticket           *TicketHandler
+	APIKey           *APIKeyHandler
+	Me               *MeHandler
+	Config           *ConfigHandler
+	EventPipeline    *EventPipelineHandler
+	Events           *EventHandler
 }
 
 func NewHandlerContainer(
@@ -49,6 +66,14 @@ func NewHandlerContainer(
 	ingresses *IngressHandler,
 	pvcs *PvcHandler,
 	networkInspector *NetworkInspectorHandler,
+	revocation *RevocationHandler,
+	audit *AuditHandler,
+	ticket *TicketHandler,
+	apiKey *APIKeyHandler,
+	me *MeHandler,
+	config *ConfigHandler,
+	eventPipeline *EventPipelineHandler,
+	events *EventHandler,
 ) *HandlerContainer {
 	return &HandlerContainer{
 		Pod:              pod,
@@ -64,5 +89,13 @@ func NewHandlerContainer(
 		Ingresses:        ingresses,
 		Pvcs:             pvcs,
 		NetworkInspector: networkInspector,
+		Revocation:       revocation,
+		Audit:            audit,
+		Ticket:           ticket,
+		APIKey:           apiKey,
+		Me:               me,
+		Config:           config,
+		EventPipeline:    eventPipeline,
+		Events:           events,
 	}
 }
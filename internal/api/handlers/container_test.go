@@ -20,6 +20,14 @@ func TestNewHandlerContainer(t *testing.T) {
 	ingressHandler := &IngressHandler{}
 	pvcHandler := &PvcHandler{}
 	networkInspectorHandler := &NetworkInspectorHandler{}
+	revocationHandler := &RevocationHandler{}
+	auditHandler := &AuditHandler{}
+	ticketHandler := &TicketHandler{}
+	apiKeyHandler := &APIKeyHandler{}
+	meHandler := &MeHandler{}
+	configHandler := &ConfigHandler{}
+	eventPipelineHandler := &EventPipelineHandler{}
+	eventHandler := &EventHandler{}
 
 	container := NewHandlerContainer(
 		podHandler,
@@ -35,6 +43,14 @@ func TestNewHandlerContainer(t *testing.T) {
 		ingressHandler,
 		pvcHandler,
 		networkInspectorHandler,
+		revocationHandler,
+		auditHandler,
+		ticketHandler,
+		apiKeyHandler,
+		meHandler,
+		configHandler,
+		eventPipelineHandler,
+		eventHandler,
 	)
 
 	assert.NotNil(t, container)
@@ -51,4 +67,12 @@ func TestNewHandlerContainer(t *testing.T) {
 	assert.Equal(t, ingressHandler, container.Ingresses)
 	assert.Equal(t, pvcHandler, container.Pvcs)
 	assert.Equal(t, networkInspectorHandler, container.NetworkInspector)
+	assert.Equal(t, revocationHandler, container.Revocation)
+	assert.Equal(t, auditHandler, container.Audit)
+	assert.Equal(t, ticketHandler, container.Ticket)
+	assert.Equal(t, apiKeyHandler, container.APIKey)
+	assert.Equal(t, meHandler, container.Me)
+	assert.Equal(t, configHandler, container.Config)
+	assert.Equal(t, eventPipelineHandler, container.EventPipeline)
+	assert.Equal(t, eventHandler, container.Events)
 }
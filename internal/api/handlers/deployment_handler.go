@@ -4,7 +4,6 @@ import (
 	"cluster-agent/internal/api/responses"
 	"cluster-agent/internal/models"
 	"cluster-agent/internal/services"
-	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -22,12 +21,9 @@ func NewDeploymentHandler(deploymentService services.DeploymentService) *Deploym
 }
 
 func (handler *DeploymentHandler) List(c *gin.Context) {
-	namespace := c.Query("namespace")
-
-	deployments, err := handler.deploymentService.GetDeployments(c.Request.Context(), namespace)
-
+	deployments, err := listInScope(c, handler.deploymentService.GetDeployments)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, responses.Error(err.Error()))
+		c.JSON(errorStatus(err), responses.Error(err.Error()))
 		return
 	}
 
@@ -40,12 +36,7 @@ func (handler *DeploymentHandler) Get(c *gin.Context) {
 
 	deployment, err := handler.deploymentService.GetDeployment(c.Request.Context(), namespace, name)
 	if err != nil {
-		if errors.Is(err, services.ErrNotFound) {
-			c.JSON(http.StatusNotFound, responses.Error(err.Error()))
-			return
-		}
-
-		c.JSON(http.StatusInternalServerError, responses.Error(err.Error()))
+		c.JSON(errorStatus(err), responses.Error(err.Error()))
 		return
 	}
 
@@ -60,9 +51,14 @@ func (handler *DeploymentHandler) Create(c *gin.Context) {
 		return
 	}
 
+	if !namespaceAllowed(c, deployment.Namespace) {
+		c.JSON(http.StatusForbidden, responses.Error(namespaceForbidden(deployment.Namespace)))
+		return
+	}
+
 	err := handler.deploymentService.CreateDeployment(c.Request.Context(), &deployment)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, responses.Error(err.Error()))
+		c.JSON(errorStatus(err), responses.Error(err.Error()))
 		return
 	}
 
@@ -74,12 +70,7 @@ func (handler *DeploymentHandler) Delete(c *gin.Context) {
 	name := c.Param("name")
 	err := handler.deploymentService.DeleteDeployment(c.Request.Context(), namespace, name)
 	if err != nil {
-		if errors.Is(err, services.ErrNotFound) {
-			c.JSON(http.StatusNotFound, responses.Error(err.Error()))
-			return
-		}
-
-		c.JSON(http.StatusInternalServerError, responses.Error(err.Error()))
+		c.JSON(errorStatus(err), responses.Error(err.Error()))
 		return
 	}
 
@@ -93,14 +84,14 @@ func (handler *DeploymentHandler) ScaleDeployment(c *gin.Context) {
 		return
 	}
 
+	if !namespaceAllowed(c, request.Namespace) {
+		c.JSON(http.StatusForbidden, responses.Error(namespaceForbidden(request.Namespace)))
+		return
+	}
+
 	err := handler.deploymentService.ScaleDeployment(c.Request.Context(), request)
 	if err != nil {
-		if errors.Is(err, services.ErrNotFound) {
-			c.JSON(http.StatusNotFound, responses.Error(err.Error()))
-			return
-		}
-
-		c.JSON(http.StatusInternalServerError, responses.Error(err.Error()))
+		c.JSON(errorStatus(err), responses.Error(err.Error()))
 		return
 	}
 
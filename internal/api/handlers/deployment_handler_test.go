@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"bytes"
+	"cluster-agent/internal/auth"
 	"cluster-agent/internal/models"
 	"cluster-agent/internal/services"
 	"cluster-agent/internal/services/mock"
@@ -344,3 +345,45 @@ func TestScaleDeployment(t *testing.T) {
 		})
 	}
 }
+
+func TestDeploymentHandler_NamespaceScope(t *testing.T) {
+	type testCase struct {
+		name         string
+		method       string
+		inputBody    string
+		expectedCode int
+	}
+
+	tests := []testCase{
+		{
+			name:         "Create in namespace not granted",
+			method:       "POST",
+			inputBody:    `{"metadata": {"name": "test-app", "namespace": "billing"}}`,
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "Scale in namespace not granted",
+			method:       "PATCH",
+			inputBody:    `{"namespace": "billing", "name": "app", "replicas": 3}`,
+			expectedCode: http.StatusForbidden,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := new(mock.DeploymentServiceMock)
+			handler := NewDeploymentHandler(svc)
+
+			r := setupRouter()
+			r.Use(withNamespaceScope(auth.NewNamespaceScope("payments")))
+			r.POST("/deployments", handler.Create)
+			r.PATCH("/deployments", handler.ScaleDeployment)
+
+			w := performRequest(r, tc.method, "/deployments", bytes.NewBufferString(tc.inputBody))
+
+			assert.Equal(t, tc.expectedCode, w.Code)
+			assert.Contains(t, w.Body.String(), "permission not granted in namespace billing")
+			svc.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"cluster-agent/internal/api/middleware"
+	"cluster-agent/internal/api/responses"
+	"cluster-agent/internal/models"
+	"cluster-agent/internal/services"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// eventStreamKeepAlive keeps idle streams from being closed by proxies.
+const eventStreamKeepAlive = 30 * time.Second
+
+type EventHandler struct {
+	service services.EventService
+}
+
+func NewEventHandler(service services.EventService) *EventHandler {
+	return &EventHandler{
+		service: service,
+	}
+}
+
+func (h *EventHandler) List(c *gin.Context) {
+	var query models.EventQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, responses.Error(err.Error()))
+		return
+	}
+
+	query.Scope = middleware.GetNamespaceScope(c)
+
+	events, err := h.service.List(c.Request.Context(), query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, responses.Error(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, responses.Success(events))
+}
+
+// Stream sends new and repeated events as Server-Sent Events until the client
+// disconnects. It takes the same filters as List and authenticates with a
+// ticket, since a browser EventSource cannot set the Authorization header.
+// The ticket is single use, so the client requests a new one before it
+// reconnects.
+func (h *EventHandler) Stream(c *gin.Context) {
+	var query models.EventQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, responses.Error(err.Error()))
+		return
+	}
+
+	query.Scope = middleware.GetNamespaceScope(c)
+	ctx := c.Request.Context()
+
+	events, err := h.service.Watch(ctx, query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, responses.Error(err.Error()))
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+
+	keepAlive := time.NewTicker(eventStreamKeepAlive)
+	defer keepAlive.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case event := <-events:
+			c.SSEvent("event", event)
+			return true
+
+		case <-keepAlive.C:
+			_, err := io.WriteString(w, ": keep-alive\n\n")
+			return err == nil
+
+		case <-ctx.Done():
+			return false
+		}
+	})
+}
@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"bufio"
+	"cluster-agent/internal/auth"
+	"cluster-agent/internal/models"
+	"cluster-agent/internal/services/mock"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	testifyMock "github.com/stretchr/testify/mock"
+)
+
+func TestEventHandler_List(t *testing.T) {
+	type testCase struct {
+		name          string
+		queryString   string
+		mockBehavior  func(m *mock.EventServiceMock)
+		expectedCode  int
+		expectedError string
+		expectedData  []models.ClusterEvent
+	}
+
+	event := models.ClusterEvent{
+		InvolvedObject: models.EventObjectRef{Kind: "Pod", Namespace: "default", Name: "api"},
+		Reason:         "BackOff",
+		Type:           "Warning",
+	}
+
+	tests := []testCase{
+		{
+			name:        "Success",
+			queryString: "?namespace=default&kind=Pod&name=api&type=Warning&reason=BackOff&limit=10",
+			mockBehavior: func(m *mock.EventServiceMock) {
+				m.On("List", testifyMock.Anything, models.EventQuery{
+					Namespace: "default",
+					Kind:      "Pod",
+					Name:      "api",
+					Type:      "Warning",
+					Reason:    "BackOff",
+					Limit:     10,
+				}).Return([]models.ClusterEvent{event}, nil)
+			},
+			expectedCode: http.StatusOK,
+			expectedData: []models.ClusterEvent{event},
+		},
+		{
+			name:          "Invalid type",
+			queryString:   "?type=Error",
+			mockBehavior:  func(m *mock.EventServiceMock) {},
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "Type",
+		},
+		{
+			name:          "Invalid limit",
+			queryString:   "?limit=5000",
+			mockBehavior:  func(m *mock.EventServiceMock) {},
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "Limit",
+		},
+		{
+			name:        "Internal error",
+			queryString: "",
+			mockBehavior: func(m *mock.EventServiceMock) {
+				m.On("List", testifyMock.Anything, models.EventQuery{}).
+					Return([]models.ClusterEvent(nil), assert.AnError)
+			},
+			expectedCode:  http.StatusInternalServerError,
+			expectedError: "assert.AnError general error for testing",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := new(mock.EventServiceMock)
+			tc.mockBehavior(svc)
+
+			r := setupRouter()
+			r.GET("/events", NewEventHandler(svc).List)
+
+			w := performRequest(r, "GET", "/events"+tc.queryString, nil)
+
+			assert.Equal(t, tc.expectedCode, w.Code)
+
+			if tc.expectedError != "" {
+				assert.Contains(t, w.Body.String(), tc.expectedError)
+			} else {
+				resp := parseResponse[[]models.ClusterEvent](t, w)
+				assert.Equal(t, tc.expectedData, resp.Data)
+			}
+
+			svc.AssertExpectations(t)
+		})
+	}
+}
+
+// The scope goes to the service, which applies it before the limit.
+func TestEventHandler_List_NamespaceScope(t *testing.T) {
+	scope := auth.NewNamespaceScope("payments")
+	event := models.ClusterEvent{InvolvedObject: models.EventObjectRef{Kind: "Pod", Namespace: "payments", Name: "api"}}
+
+	svc := new(mock.EventServiceMock)
+	svc.On("List", testifyMock.Anything, models.EventQuery{Limit: 1, Scope: scope}).
+		Return([]models.ClusterEvent{event}, nil)
+
+	r := setupRouter()
+	r.GET("/events", withNamespaceScope(scope), NewEventHandler(svc).List)
+
+	w := performRequest(r, "GET", "/events?limit=1", nil)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	resp := parseResponse[[]models.ClusterEvent](t, w)
+	assert.Equal(t, []models.ClusterEvent{event}, resp.Data)
+
+	svc.AssertExpectations(t)
+}
+
+func TestEventHandler_Stream(t *testing.T) {
+	scope := auth.NewNamespaceScope("payments")
+	events := make(chan models.ClusterEvent, 1)
+	events <- models.ClusterEvent{InvolvedObject: models.EventObjectRef{Kind: "Pod", Namespace: "payments", Name: "api"}, Reason: "BackOff"}
+
+	svc := new(mock.EventServiceMock)
+	svc.On("Watch", testifyMock.Anything, models.EventQuery{Reason: "BackOff", Scope: scope}).
+		Return((<-chan models.ClusterEvent)(events), nil)
+
+	r := setupRouter()
+	r.GET("/events/stream", withNamespaceScope(scope), NewEventHandler(svc).Stream)
+
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	req, _ := http.NewRequestWithContext(ctx, "GET", server.URL+"/events/stream?reason=BackOff", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("could not open event stream: %v", err)
+	}
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, resp.Header.Get("Content-Type"), "text/event-stream")
+
+	reader := bufio.NewReader(resp.Body)
+	eventLine, err := reader.ReadString('\n')
+	assert.NoError(t, err)
+	assert.Equal(t, "event:event\n", eventLine)
+
+	dataLine, err := reader.ReadString('\n')
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(dataLine, "data:"))
+	assert.Contains(t, dataLine, `"name":"api"`)
+
+	svc.AssertExpectations(t)
+}
+
+func TestEventHandler_Stream_ServiceError(t *testing.T) {
+	svc := new(mock.EventServiceMock)
+	svc.On("Watch", testifyMock.Anything, models.EventQuery{}).
+		Return(nil, assert.AnError)
+
+	r := setupRouter()
+	r.GET("/events/stream", NewEventHandler(svc).Stream)
+
+	w := performRequest(r, "GET", "/events/stream", nil)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), "assert.AnError general error for testing")
+
+	svc.AssertExpectations(t)
+}
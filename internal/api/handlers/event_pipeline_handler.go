@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"cluster-agent/internal/api/responses"
+	"cluster-agent/internal/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type EventPipelineHandler struct {
+	service services.EventPipelineService
+}
+
+func NewEventPipelineHandler(service services.EventPipelineService) *EventPipelineHandler {
+	return &EventPipelineHandler{
+		service: service,
+	}
+}
+
+// Stats reports how many events were forwarded, spooled or lost.
+func (h *EventPipelineHandler) Stats(c *gin.Context) {
+	stats, err := h.service.Stats(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, responses.Error(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, responses.Success(stats))
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"cluster-agent/internal/models"
+	"cluster-agent/internal/services/mock"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	testifyMock "github.com/stretchr/testify/mock"
+)
+
+func TestEventPipelineHandler_Stats(t *testing.T) {
+	type testCase struct {
+		name          string
+		mockBehavior  func(m *mock.EventPipelineServiceMock)
+		expectedCode  int
+		expectedStats *models.EventPipelineStats
+		expectedError string
+	}
+
+	stats := &models.EventPipelineStats{
+		Filtered:       3,
+		FilteredByRule: map[string]int64{"system-noise": 3},
+		Received:       120,
+		Sinks: map[string]models.EventSinkStats{
+			"http":  {Sent: 100, Spooled: 20, SpoolOverflow: 5, SpoolPending: 1},
+			"redis": {Sent: 120},
+		},
+	}
+
+	tests := []testCase{
+		{
+			name: "Success",
+			mockBehavior: func(m *mock.EventPipelineServiceMock) {
+				m.On("Stats", testifyMock.Anything).Return(stats, nil)
+			},
+			expectedCode:  http.StatusOK,
+			expectedStats: stats,
+		},
+		{
+			name: "Spool unavailable",
+			mockBehavior: func(m *mock.EventPipelineServiceMock) {
+				m.On("Stats", testifyMock.Anything).Return((*models.EventPipelineStats)(nil), assert.AnError)
+			},
+			expectedCode:  http.StatusInternalServerError,
+			expectedError: assert.AnError.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mock.EventPipelineServiceMock)
+			tt.mockBehavior(mockService)
+
+			r := setupRouter()
+			r.GET("/events/pipeline", NewEventPipelineHandler(mockService).Stats)
+
+			w := performRequest(r, "GET", "/events/pipeline", nil)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+
+			if tt.expectedStats != nil {
+				response := parseResponse[models.EventPipelineStats](t, w)
+				assert.Equal(t, *tt.expectedStats, response.Data)
+			}
+
+			if tt.expectedError != "" {
+				response := parseResponse[any](t, w)
+				assert.Equal(t, tt.expectedError, response.Error)
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -3,7 +3,6 @@ package handlers
 import (
 	"cluster-agent/internal/api/responses"
 	"cluster-agent/internal/services"
-	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -14,24 +13,19 @@ type IngressHandler struct{ service services.IngressService }
 func NewIngressHandler(s services.IngressService) *IngressHandler { return &IngressHandler{service: s} }
 
 func (h *IngressHandler) List(c *gin.Context) {
-	namespace := c.Query("namespace")
-
-	data, err := h.service.List(c.Request.Context(), namespace)
+	data, err := listInScope(c, h.service.List)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, responses.Error(err.Error()))
+		c.JSON(errorStatus(err), responses.Error(err.Error()))
 		return
 	}
+
 	c.JSON(http.StatusOK, responses.Success(data))
 }
 
 func (h *IngressHandler) Get(c *gin.Context) {
 	data, err := h.service.Get(c.Request.Context(), c.Param("namespace"), c.Param("name"))
 	if err != nil {
-		if errors.Is(err, services.ErrNotFound) {
-			c.JSON(http.StatusNotFound, responses.Error(err.Error()))
-			return
-		}
-		c.JSON(http.StatusInternalServerError, responses.Error(err.Error()))
+		c.JSON(errorStatus(err), responses.Error(err.Error()))
 		return
 	}
 	c.JSON(http.StatusOK, responses.Success(data))
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"cluster-agent/internal/api/middleware"
+	"cluster-agent/internal/api/responses"
+	"cluster-agent/internal/auth/permissions"
+	"cluster-agent/internal/models"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RouteCatalog collects the routes registered at startup. It is filled before
+// the server starts and only read afterwards.
+type RouteCatalog struct {
+	routes []models.RouteInfo
+}
+
+func NewRouteCatalog() *RouteCatalog {
+	return &RouteCatalog{}
+}
+
+func (c *RouteCatalog) Add(route models.RouteInfo) {
+	c.routes = append(c.routes, route)
+}
+
+func (c *RouteCatalog) Routes() []models.RouteInfo {
+	return c.routes
+}
+
+type MeHandler struct {
+	catalog *RouteCatalog
+}
+
+func NewMeHandler(catalog *RouteCatalog) *MeHandler {
+	return &MeHandler{
+		catalog: catalog,
+	}
+}
+
+// Get describes the caller: its claims, the permissions it effectively holds
+// after roles and wildcards, and which routes it may call.
+func (h *MeHandler) Get(c *gin.Context) {
+	claims := middleware.GetUserClaims(c)
+	if claims == nil {
+		c.JSON(http.StatusUnauthorized, responses.Error("missing jwt token claims"))
+		return
+	}
+
+	effective := make([]models.EffectivePermission, 0)
+	for _, permission := range permissions.All() {
+		scope := claims.Scope(permission)
+		if scope.IsEmpty() {
+			continue
+		}
+
+		effective = append(effective, models.EffectivePermission{
+			Permission:    permission,
+			AllNamespaces: scope.IsAll(),
+			Namespaces:    scope.Namespaces(),
+		})
+	}
+
+	routes := make([]models.RouteAccess, 0, len(h.catalog.Routes()))
+	for _, route := range h.catalog.Routes() {
+		allowed := true
+		if route.Permission != "" {
+			scope := claims.Scope(route.Permission)
+			allowed = !scope.IsEmpty() && (!route.ClusterScoped || scope.IsAll())
+		}
+
+		routes = append(routes, models.RouteAccess{RouteInfo: route, Allowed: allowed})
+	}
+
+	c.JSON(http.StatusOK, responses.Success(models.CallerInfo{
+		UserId:      claims.UserId,
+		Claims:      claims,
+		Permissions: effective,
+		Routes:      routes,
+	}))
+}
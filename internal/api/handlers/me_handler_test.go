@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"cluster-agent/internal/auth"
+	"cluster-agent/internal/auth/permissions"
+	"cluster-agent/internal/models"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMeHandler_Get(t *testing.T) {
+	catalog := NewRouteCatalog()
+	catalog.Add(models.RouteInfo{Method: "GET", Path: "/api/v1/me"})
+	catalog.Add(models.RouteInfo{Method: "GET", Path: "/api/v1/pods", Permission: permissions.PodsView})
+	catalog.Add(models.RouteInfo{Method: "GET", Path: "/api/v1/nodes", Permission: permissions.NodesView, ClusterScoped: true})
+	catalog.Add(models.RouteInfo{Method: "POST", Path: "/api/v1/deployments", Permission: permissions.DeploymentsCreate})
+
+	claims := &auth.UserClaims{
+		UserId:      "42",
+		Permissions: []permissions.Permission{"*:view@payments", "deployments:view"},
+	}
+
+	r := setupRouter()
+	r.GET("/me", func(c *gin.Context) {
+		c.Set("claims", claims)
+		c.Next()
+	}, NewMeHandler(catalog).Get)
+
+	w := performRequest(r, "GET", "/me", nil)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	response := parseResponse[models.CallerInfo](t, w)
+	assert.Equal(t, "42", response.Data.UserId)
+
+	effective := make(map[permissions.Permission]models.EffectivePermission)
+	for _, permission := range response.Data.Permissions {
+		effective[permission.Permission] = permission
+	}
+
+	assert.Equal(t, []string{"payments"}, effective[permissions.PodsView].Namespaces)
+	assert.False(t, effective[permissions.PodsView].AllNamespaces)
+	assert.True(t, effective[permissions.DeploymentsView].AllNamespaces)
+	assert.NotContains(t, effective, permissions.DeploymentsCreate)
+
+	allowed := make(map[string]bool)
+	for _, route := range response.Data.Routes {
+		allowed[route.Method+" "+route.Path] = route.Allowed
+	}
+
+	assert.Equal(t, map[string]bool{
+		"GET /api/v1/me":           true,
+		"GET /api/v1/pods":         true,
+		"GET /api/v1/nodes":        false,
+		"POST /api/v1/deployments": false,
+	}, allowed)
+}
+
+func TestMeHandler_Get_MissingClaims(t *testing.T) {
+	r := setupRouter()
+	r.GET("/me", NewMeHandler(NewRouteCatalog()).Get)
+
+	w := performRequest(r, "GET", "/me", nil)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"cluster-agent/internal/api/middleware"
 	"cluster-agent/internal/api/responses"
 	"cluster-agent/internal/services"
 	"net/http"
@@ -18,10 +19,21 @@ func NewNamespaceHandler(namespaceService services.NamespaceService) *NamespaceH
 	}
 }
 
+// List returns the namespaces the caller was granted any permission in, so
+// namespace-scoped callers do not learn about the others. Those are answered
+// from their grants: with K8S_IMPERSONATION their RBAC usually does not allow
+// listing namespaces.
 func (handler *NamespaceHandler) List(c *gin.Context) {
+	if claims := middleware.GetUserClaims(c); claims != nil {
+		if scope := claims.GrantedNamespaces(); !scope.IsAll() {
+			c.JSON(http.StatusOK, responses.Success(scope.Namespaces()))
+			return
+		}
+	}
+
 	result, err := handler.namespaceService.GetNamespaces(c.Request.Context())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, responses.Error(err.Error()))
+		c.JSON(errorStatus(err), responses.Error(err.Error()))
 		return
 	}
 
@@ -1,33 +1,81 @@
 package handlers
 
 import (
+	"cluster-agent/internal/auth"
+	"cluster-agent/internal/auth/permissions"
 	"cluster-agent/internal/services/mock"
+	"fmt"
 	"net/http"
 	"testing"
 
+	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	testifyMock "github.com/stretchr/testify/mock"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 func TestNamespaceHandler_List(t *testing.T) {
 	type testCase struct {
-		name          string
-		mockBehavior  func(m *mock.NamespaceServiceMock)
-		expectedCode  int
-		expectedError string
+		name               string
+		claims             *auth.UserClaims
+		mockBehavior       func(m *mock.NamespaceServiceMock)
+		expectedCode       int
+		expectedError      string
+		expectedNamespaces []string
+	}
+
+	namespaces := func(m *mock.NamespaceServiceMock) {
+		m.On("GetNamespaces", testifyMock.Anything).
+			Return([]string{"default", "kube-system", "payments"}, nil)
+	}
+
+	// Impersonated callers limited to some namespaces may not list them.
+	forbidden := func(m *mock.NamespaceServiceMock) {
+		m.On("GetNamespaces", testifyMock.Anything).
+			Return([]string(nil), k8serrors.NewForbidden(schema.GroupResource{Resource: "namespaces"}, "", fmt.Errorf("cannot list namespaces"))).
+			Maybe()
 	}
 
 	tests := []testCase{
 		{
-			name: "Success",
-			mockBehavior: func(m *mock.NamespaceServiceMock) {
-				m.On("GetNamespaces", testifyMock.Anything).
-					Return([]string{"default", "kube-system"}, nil)
+			name:               "Success",
+			claims:             &auth.UserClaims{UserId: "42", Permissions: []permissions.Permission{permissions.PodsView}},
+			mockBehavior:       namespaces,
+			expectedCode:       http.StatusOK,
+			expectedNamespaces: []string{"default", "kube-system", "payments"},
+		},
+		{
+			name: "Namespaced grants",
+			claims: &auth.UserClaims{UserId: "42", Permissions: []permissions.Permission{
+				permissions.PodsView.Scoped("payments"),
+				permissions.DeploymentsView.Scoped("default"),
+			}},
+			mockBehavior:       forbidden,
+			expectedCode:       http.StatusOK,
+			expectedNamespaces: []string{"default", "payments"},
+		},
+		{
+			name: "Cluster-wide grant limited by the namespaces claim",
+			claims: &auth.UserClaims{
+				UserId:      "42",
+				Permissions: []permissions.Permission{permissions.PodsView},
+				Namespaces:  []string{"payments"},
 			},
-			expectedCode: http.StatusOK,
+			mockBehavior:       forbidden,
+			expectedCode:       http.StatusOK,
+			expectedNamespaces: []string{"payments"},
 		},
 		{
-			name: "Error",
+			name:               "No grants",
+			claims:             &auth.UserClaims{UserId: "42"},
+			mockBehavior:       forbidden,
+			expectedCode:       http.StatusOK,
+			expectedNamespaces: []string{},
+		},
+		{
+			name:   "Error",
+			claims: &auth.UserClaims{UserId: "42", Permissions: []permissions.Permission{permissions.PodsView}},
 			mockBehavior: func(m *mock.NamespaceServiceMock) {
 				m.On("GetNamespaces", testifyMock.Anything).
 					Return([]string(nil), assert.AnError)
@@ -44,7 +92,10 @@ func TestNamespaceHandler_List(t *testing.T) {
 
 			handler := NewNamespaceHandler(svc)
 			r := setupRouter()
-			r.GET("/namespaces", handler.List)
+			r.GET("/namespaces", func(c *gin.Context) {
+				c.Set("claims", tc.claims)
+				c.Next()
+			}, handler.List)
 
 			w := performRequest(r, "GET", "/namespaces", nil)
 
@@ -52,9 +103,15 @@ func TestNamespaceHandler_List(t *testing.T) {
 
 			if tc.expectedError != "" {
 				assert.Contains(t, w.Body.String(), tc.expectedError)
+			} else {
+				response := parseResponse[[]string](t, w)
+				assert.Equal(t, tc.expectedNamespaces, response.Data)
 			}
 
 			svc.AssertExpectations(t)
+			if !tc.claims.GrantedNamespaces().IsAll() {
+				svc.AssertNotCalled(t, "GetNamespaces", testifyMock.Anything)
+			}
 		})
 	}
 }
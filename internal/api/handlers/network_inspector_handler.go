@@ -26,7 +26,7 @@ func (h *NetworkInspectorHandler) GetConnections(c *gin.Context) {
 
 	connections, err := h.service.GetPodNetworkConnections(c.Request.Context(), namespace, name, container)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, responses.Error(fmt.Sprintf("failed to get network connections: %v", err)))
+		c.JSON(errorStatus(err), responses.Error(fmt.Sprintf("failed to get network connections: %v", err)))
 		return
 	}
 
@@ -21,7 +21,7 @@ func NewNodeHandler(nodeService services.NodeService) *NodeHandler {
 func (h *NodeHandler) List(c *gin.Context) {
 	nodes, err := h.nodeService.GetNodes(c.Request.Context())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, responses.Error(err.Error()))
+		c.JSON(errorStatus(err), responses.Error(err.Error()))
 		return
 	}
 
@@ -3,7 +3,6 @@ package handlers
 import (
 	"cluster-agent/internal/api/responses"
 	"cluster-agent/internal/services"
-	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -20,11 +19,9 @@ func NewPodHandler(podService services.PodService) *PodHandler {
 }
 
 func (handler *PodHandler) List(c *gin.Context) {
-	namespace := c.Query("namespace")
-
-	pods, err := handler.podService.GetPods(c.Request.Context(), namespace)
+	pods, err := listInScope(c, handler.podService.GetPods)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, responses.Error(err.Error()))
+		c.JSON(errorStatus(err), responses.Error(err.Error()))
 		return
 	}
 
@@ -37,11 +34,7 @@ func (handler *PodHandler) Get(c *gin.Context) {
 
 	pod, err := handler.podService.GetPod(c.Request.Context(), namespace, name)
 	if err != nil {
-		if errors.Is(err, services.ErrNotFound) {
-			c.JSON(http.StatusNotFound, responses.Error(err.Error()))
-			return
-		}
-		c.JSON(http.StatusInternalServerError, responses.Error(err.Error()))
+		c.JSON(errorStatus(err), responses.Error(err.Error()))
 		return
 	}
 
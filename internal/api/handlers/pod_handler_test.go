@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"cluster-agent/internal/auth"
 	"cluster-agent/internal/models"
 	"cluster-agent/internal/services"
 	"cluster-agent/internal/services/mock"
@@ -10,6 +11,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	testifyMock "github.com/stretchr/testify/mock"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 func TestPodHandler_List(t *testing.T) {
@@ -74,6 +77,59 @@ func TestPodHandler_List(t *testing.T) {
 	}
 }
 
+func TestPodHandler_List_NamespaceScope(t *testing.T) {
+	forbidden := k8serrors.NewForbidden(schema.GroupResource{Resource: "pods"}, "", fmt.Errorf("cannot list pods at the cluster scope"))
+
+	svc := new(mock.PodServiceMock)
+	// Impersonated callers limited to some namespaces may not list cluster-wide.
+	svc.On("GetPods", testifyMock.Anything, "").Return([]models.PodListInfo(nil), forbidden).Maybe()
+	svc.On("GetPods", testifyMock.Anything, "frontend").
+		Return([]models.PodListInfo{{Name: "web", Namespace: "frontend"}}, nil)
+	svc.On("GetPods", testifyMock.Anything, "payments").
+		Return([]models.PodListInfo{{Name: "api", Namespace: "payments"}, {Name: "worker", Namespace: "payments"}}, nil)
+
+	r := setupRouter()
+	r.GET("/pods", withNamespaceScope(auth.NewNamespaceScope("payments", "frontend")), NewPodHandler(svc).List)
+
+	t.Run("Lists each granted namespace", func(t *testing.T) {
+		w := performRequest(r, "GET", "/pods", nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		resp := parseResponse[[]models.PodListInfo](t, w)
+		assert.Equal(t, []models.PodListInfo{
+			{Name: "web", Namespace: "frontend"},
+			{Name: "api", Namespace: "payments"},
+			{Name: "worker", Namespace: "payments"},
+		}, resp.Data)
+		svc.AssertNotCalled(t, "GetPods", testifyMock.Anything, "")
+	})
+
+	t.Run("Lists only the requested namespace", func(t *testing.T) {
+		w := performRequest(r, "GET", "/pods?namespace=payments", nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		resp := parseResponse[[]models.PodListInfo](t, w)
+		assert.Len(t, resp.Data, 2)
+	})
+
+	t.Run("Error in one namespace fails the list", func(t *testing.T) {
+		svc := new(mock.PodServiceMock)
+		svc.On("GetPods", testifyMock.Anything, "frontend").Return([]models.PodListInfo(nil), forbidden)
+
+		r := setupRouter()
+		r.GET("/pods", withNamespaceScope(auth.NewNamespaceScope("payments", "frontend")), NewPodHandler(svc).List)
+
+		w := performRequest(r, "GET", "/pods", nil)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		svc.AssertNotCalled(t, "GetPods", testifyMock.Anything, "payments")
+	})
+
+	svc.AssertExpectations(t)
+}
+
 func TestPodHandler_Get(t *testing.T) {
 	type testCase struct {
 		name         string
@@ -107,6 +163,18 @@ func TestPodHandler_Get(t *testing.T) {
 			expectedCode: http.StatusNotFound,
 			expectError:  true,
 		},
+		{
+			name:      "Forbidden by Kubernetes RBAC",
+			namespace: "default",
+			podName:   "denied",
+			mockBehavior: func(m *mock.PodServiceMock) {
+				m.On("GetPod", testifyMock.Anything, "default", "denied").
+					Return((*models.PodDetails)(nil), fmt.Errorf("failed to get pod: %w",
+						k8serrors.NewForbidden(schema.GroupResource{Resource: "pods"}, "denied", assert.AnError)))
+			},
+			expectedCode: http.StatusForbidden,
+			expectError:  true,
+		},
 		{
 			name:      "Internal error",
 			namespace: "default",
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"cluster-agent/internal/api/responses"
+	"cluster-agent/internal/models"
+	"cluster-agent/internal/services"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RevocationHandler struct {
+	service services.RevocationService
+}
+
+func NewRevocationHandler(service services.RevocationService) *RevocationHandler {
+	return &RevocationHandler{
+		service: service,
+	}
+}
+
+func (h *RevocationHandler) Revoke(c *gin.Context) {
+	var request models.RevokeTokenParams
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, responses.Error(err.Error()))
+		return
+	}
+
+	var err error
+
+	switch {
+	case request.TokenId != "":
+		err = h.service.RevokeToken(c.Request.Context(), request.TokenId, request.ExpiresAt)
+	case request.UserId != "":
+		err = h.service.RevokeUser(c.Request.Context(), request.UserId, request.Before)
+	default:
+		c.JSON(http.StatusBadRequest, responses.Error("either jti or user_id is required"))
+		return
+	}
+
+	if errors.Is(err, services.ErrRevocationExpiryRequired) {
+		c.JSON(http.StatusBadRequest, responses.Error(err.Error()))
+		return
+	}
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, responses.Error(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, responses.Success("OK"))
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"bytes"
+	"cluster-agent/internal/services/mock"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	testifyMock "github.com/stretchr/testify/mock"
+)
+
+func TestRevocationHandler_Revoke(t *testing.T) {
+	type testCase struct {
+		name          string
+		inputBody     string
+		mockBehavior  func(m *mock.RevocationServiceMock)
+		expectedCode  int
+		expectedError string
+	}
+
+	expiresAt := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []testCase{
+		{
+			name:      "Revoke token",
+			inputBody: `{"jti": "token-1", "expires_at": "2030-01-01T00:00:00Z"}`,
+			mockBehavior: func(m *mock.RevocationServiceMock) {
+				m.On("RevokeToken", testifyMock.Anything, "token-1", expiresAt).Return(nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:      "Revoke user",
+			inputBody: `{"user_id": "42"}`,
+			mockBehavior: func(m *mock.RevocationServiceMock) {
+				m.On("RevokeUser", testifyMock.Anything, "42", time.Time{}).Return(nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:          "Missing target",
+			inputBody:     `{}`,
+			mockBehavior:  func(m *mock.RevocationServiceMock) {},
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "either jti or user_id is required",
+		},
+		{
+			name:         "Bad Request (Invalid JSON)",
+			inputBody:    `{invalid-json}`,
+			mockBehavior: func(m *mock.RevocationServiceMock) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:          "Token without expiry",
+			inputBody:     `{"jti": "token-1"}`,
+			mockBehavior:  func(m *mock.RevocationServiceMock) {},
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "ExpiresAt",
+		},
+		{
+			name:      "Storage error",
+			inputBody: `{"jti": "token-1", "expires_at": "2030-01-01T00:00:00Z"}`,
+			mockBehavior: func(m *mock.RevocationServiceMock) {
+				m.On("RevokeToken", testifyMock.Anything, "token-1", expiresAt).Return(assert.AnError)
+			},
+			expectedCode:  http.StatusInternalServerError,
+			expectedError: "assert.AnError general error for testing",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := new(mock.RevocationServiceMock)
+			tc.mockBehavior(svc)
+
+			r := setupRouter()
+			r.POST("/tokens/revoke", NewRevocationHandler(svc).Revoke)
+
+			w := performRequest(r, "POST", "/tokens/revoke", bytes.NewBufferString(tc.inputBody))
+
+			assert.Equal(t, tc.expectedCode, w.Code)
+
+			if tc.expectedError != "" {
+				assert.Contains(t, w.Body.String(), tc.expectedError)
+			}
+
+			svc.AssertExpectations(t)
+		})
+	}
+}
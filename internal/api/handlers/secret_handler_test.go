@@ -137,3 +137,66 @@ func TestSecretHandler_Get(t *testing.T) {
 		})
 	}
 }
+
+func TestSecretHandler_Reveal(t *testing.T) {
+	type testCase struct {
+		name          string
+		key           string
+		mockBehavior  func(m *mock.SecretServiceMock)
+		expectedCode  int
+		expectedValue string
+	}
+
+	tests := []testCase{
+		{
+			name: "Success",
+			key:  "password",
+			mockBehavior: func(m *mock.SecretServiceMock) {
+				m.On("Reveal", testifyMock.Anything, "default", "my-secret", "password").
+					Return(&models.SecretValue{Key: "password", Value: []byte("hunter2")}, nil)
+			},
+			expectedCode:  http.StatusOK,
+			expectedValue: "hunter2",
+		},
+		{
+			name: "Missing key",
+			key:  "missing",
+			mockBehavior: func(m *mock.SecretServiceMock) {
+				m.On("Reveal", testifyMock.Anything, "default", "my-secret", "missing").
+					Return((*models.SecretValue)(nil), fmt.Errorf("%w: key %q", services.ErrNotFound, "missing"))
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name: "Internal error",
+			key:  "password",
+			mockBehavior: func(m *mock.SecretServiceMock) {
+				m.On("Reveal", testifyMock.Anything, "default", "my-secret", "password").
+					Return((*models.SecretValue)(nil), assert.AnError)
+			},
+			expectedCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := new(mock.SecretServiceMock)
+			tc.mockBehavior(svc)
+
+			handler := NewSecretHandler(svc)
+			r := setupRouter()
+			r.GET("/:namespace/:name/keys/:key", handler.Reveal)
+
+			w := performRequest(r, "GET", "/default/my-secret/keys/"+tc.key, nil)
+
+			assert.Equal(t, tc.expectedCode, w.Code)
+
+			if tc.expectedValue != "" {
+				response := parseResponse[models.SecretValue](t, w)
+				assert.Equal(t, tc.expectedValue, string(response.Data.Value))
+			}
+
+			svc.AssertExpectations(t)
+		})
+	}
+}
@@ -3,7 +3,6 @@ package handlers
 import (
 	"cluster-agent/internal/api/responses"
 	"cluster-agent/internal/services"
-	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -20,11 +19,9 @@ func NewServiceHandler(service services.KubernetesServiceService) *ServiceHandle
 }
 
 func (handler *ServiceHandler) List(c *gin.Context) {
-	namespace := c.Query("namespace")
-
-	result, err := handler.service.List(c.Request.Context(), namespace)
+	result, err := listInScope(c, handler.service.List)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, responses.Error(err.Error()))
+		c.JSON(errorStatus(err), responses.Error(err.Error()))
 		return
 	}
 
@@ -34,11 +31,7 @@ func (handler *ServiceHandler) List(c *gin.Context) {
 func (handler *ServiceHandler) Get(c *gin.Context) {
 	data, err := handler.service.Get(c.Request.Context(), c.Param("namespace"), c.Param("name"))
 	if err != nil {
-		if errors.Is(err, services.ErrNotFound) {
-			c.JSON(http.StatusNotFound, responses.Error(err.Error()))
-			return
-		}
-		c.JSON(http.StatusInternalServerError, responses.Error(err.Error()))
+		c.JSON(errorStatus(err), responses.Error(err.Error()))
 		return
 	}
 
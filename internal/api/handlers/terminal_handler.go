@@ -4,9 +4,12 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
+	"net/http"
 	"sync"
 
+	"cluster-agent/internal/api/responses"
 	"cluster-agent/internal/services"
 
 	"github.com/gin-gonic/gin"
@@ -35,6 +38,18 @@ func (h *TerminalHandler) Exec(c *gin.Context) {
 	namespace := c.Param("namespace")
 	podName := c.Param("name")
 	container := c.Query("container")
+	command := c.QueryArray("command")
+
+	// Policy violations are answered before the upgrade, so clients get a
+	// plain 403 with the reason instead of a closed socket.
+	if err := h.service.Authorize(namespace, command); err != nil {
+		if errors.Is(err, services.ErrExecNotAllowed) {
+			c.JSON(http.StatusForbidden, responses.Error(err.Error()))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, responses.Error(err.Error()))
+		return
+	}
 
 	ws, err := upgrader.Upgrade(c.Writer, c.Request, nil)
 	if err != nil {
@@ -43,7 +58,7 @@ func (h *TerminalHandler) Exec(c *gin.Context) {
 
 	defer ws.Close()
 
-	exec, err := h.service.GetAuthExecutor(namespace, podName, container)
+	exec, err := h.service.GetAuthExecutor(c.Request.Context(), namespace, podName, container, command)
 	if err != nil {
 		ws.WriteMessage(websocket.TextMessage, []byte("Error init executor: "+err.Error()))
 		return
@@ -2,9 +2,11 @@ package handlers
 
 import (
 	"bytes"
+	"cluster-agent/internal/services"
 	"cluster-agent/internal/services/mock"
 	"context"
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -15,6 +17,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
 	"github.com/stretchr/testify/assert"
+	testifyMock "github.com/stretchr/testify/mock"
 	"k8s.io/client-go/tools/remotecommand"
 )
 
@@ -27,6 +30,7 @@ func TestNewTerminalHandler(t *testing.T) {
 
 func TestTerminalHandler_Exec_UpgradeFailure(t *testing.T) {
 	svc := new(mock.TerminalServiceMock)
+	svc.On("Authorize", "default", []string(nil)).Return(nil)
 	handler := NewTerminalHandler(svc)
 
 	gin.SetMode(gin.TestMode)
@@ -42,7 +46,8 @@ func TestTerminalHandler_Exec_UpgradeFailure(t *testing.T) {
 
 func TestTerminalHandler_Exec_ServiceError(t *testing.T) {
 	svc := new(mock.TerminalServiceMock)
-	svc.On("GetAuthExecutor", "default", "my-pod", "main").
+	svc.On("Authorize", "default", []string(nil)).Return(nil)
+	svc.On("GetAuthExecutor", testifyMock.Anything, "default", "my-pod", "main", []string(nil)).
 		Return(nil, assert.AnError)
 
 	handler := NewTerminalHandler(svc)
@@ -68,6 +73,61 @@ func TestTerminalHandler_Exec_ServiceError(t *testing.T) {
 	svc.AssertExpectations(t)
 }
 
+func TestTerminalHandler_Exec_PolicyDenied(t *testing.T) {
+	type testCase struct {
+		name          string
+		query         string
+		command       []string
+		authorizeErr  error
+		expectedCode  int
+		expectedError string
+	}
+
+	tests := []testCase{
+		{
+			name:          "Shell denied",
+			query:         "",
+			command:       nil,
+			authorizeErr:  fmt.Errorf("%w: interactive shells are disabled in namespace default, pass a command", services.ErrExecNotAllowed),
+			expectedCode:  http.StatusForbidden,
+			expectedError: "interactive shells are disabled",
+		},
+		{
+			name:          "Command denied",
+			query:         "?command=rm&command=-rf&command=/",
+			command:       []string{"rm", "-rf", "/"},
+			authorizeErr:  fmt.Errorf("%w: command %q is not allowed in namespace default", services.ErrExecNotAllowed, "rm"),
+			expectedCode:  http.StatusForbidden,
+			expectedError: "is not allowed in namespace default",
+		},
+		{
+			name:          "Policy error",
+			query:         "",
+			command:       nil,
+			authorizeErr:  assert.AnError,
+			expectedCode:  http.StatusInternalServerError,
+			expectedError: "assert.AnError general error for testing",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := new(mock.TerminalServiceMock)
+			svc.On("Authorize", "default", tc.command).Return(tc.authorizeErr)
+
+			r := setupRouter()
+			r.GET("/:namespace/:name/exec", NewTerminalHandler(svc).Exec)
+
+			w := performRequest(r, "GET", "/default/my-pod/exec"+tc.query, nil)
+
+			assert.Equal(t, tc.expectedCode, w.Code)
+			assert.Contains(t, w.Body.String(), tc.expectedError)
+
+			svc.AssertExpectations(t)
+		})
+	}
+}
+
 type MockExecutor struct {
 	streamErr error
 	output    string
@@ -88,7 +148,8 @@ func TestTerminalHandler_Exec_StreamError(t *testing.T) {
 	mockExec := &MockExecutor{streamErr: assert.AnError}
 
 	svc := new(mock.TerminalServiceMock)
-	svc.On("GetAuthExecutor", "default", "my-pod", "main").
+	svc.On("Authorize", "default", []string(nil)).Return(nil)
+	svc.On("GetAuthExecutor", testifyMock.Anything, "default", "my-pod", "main", []string(nil)).
 		Return(mockExec, nil)
 
 	handler := NewTerminalHandler(svc)
@@ -125,7 +186,8 @@ func TestTerminalHandler_Exec_Success(t *testing.T) {
 	mockExec := &MockExecutor{output: "hello terminal"}
 
 	svc := new(mock.TerminalServiceMock)
-	svc.On("GetAuthExecutor", "default", "my-pod", "main").
+	svc.On("Authorize", "default", []string(nil)).Return(nil)
+	svc.On("GetAuthExecutor", testifyMock.Anything, "default", "my-pod", "main", []string(nil)).
 		Return(mockExec, nil)
 
 	handler := NewTerminalHandler(svc)
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"cluster-agent/internal/api/middleware"
+	"cluster-agent/internal/api/responses"
+	"cluster-agent/internal/models"
+	"cluster-agent/internal/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TicketHandler struct {
+	service services.TicketService
+}
+
+func NewTicketHandler(service services.TicketService) *TicketHandler {
+	return &TicketHandler{
+		service: service,
+	}
+}
+
+// Create issues a ticket for the caller. Permissions are checked again when
+// the ticket is redeemed on the WebSocket route.
+func (h *TicketHandler) Create(c *gin.Context) {
+	claims := middleware.GetUserClaims(c)
+	if claims == nil {
+		c.JSON(http.StatusUnauthorized, responses.Error("missing jwt token claims"))
+		return
+	}
+
+	var request models.CreateTicketParams
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, responses.Error(err.Error()))
+		return
+	}
+
+	ticket, err := h.service.Issue(c.Request.Context(), claims, request)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, responses.Error(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusCreated, responses.Success(ticket))
+}
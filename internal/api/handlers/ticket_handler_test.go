@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"bytes"
+	"cluster-agent/internal/auth"
+	"cluster-agent/internal/models"
+	"cluster-agent/internal/services/mock"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	testifyMock "github.com/stretchr/testify/mock"
+)
+
+func TestTicketHandler_Create(t *testing.T) {
+	type testCase struct {
+		name          string
+		claims        *auth.UserClaims
+		inputBody     string
+		mockBehavior  func(m *mock.TicketServiceMock)
+		expectedCode  int
+		expectedError string
+	}
+
+	claims := &auth.UserClaims{UserId: "42"}
+	params := models.CreateTicketParams{
+		Route:     models.TicketRoutePodExec,
+		Namespace: "default",
+		Pod:       "my-pod",
+	}
+
+	tests := []testCase{
+		{
+			name:      "Issue ticket",
+			claims:    claims,
+			inputBody: `{"route": "pods.exec", "namespace": "default", "pod": "my-pod"}`,
+			mockBehavior: func(m *mock.TicketServiceMock) {
+				m.On("Issue", testifyMock.Anything, claims, params).
+					Return(&models.WebSocketTicket{Ticket: "abc", ExpiresAt: time.Now()}, nil)
+			},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:      "Issue event stream ticket",
+			claims:    claims,
+			inputBody: `{"route": "events.stream"}`,
+			mockBehavior: func(m *mock.TicketServiceMock) {
+				m.On("Issue", testifyMock.Anything, claims, models.CreateTicketParams{Route: models.TicketRouteEvents}).
+					Return(&models.WebSocketTicket{Ticket: "abc", ExpiresAt: time.Now()}, nil)
+			},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:          "Event stream ticket bound to a pod",
+			claims:        claims,
+			inputBody:     `{"route": "events.stream", "namespace": "default", "pod": "my-pod"}`,
+			mockBehavior:  func(m *mock.TicketServiceMock) {},
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "excluded_if",
+		},
+		{
+			name:          "Unknown route",
+			claims:        claims,
+			inputBody:     `{"route": "pods.delete", "namespace": "default", "pod": "my-pod"}`,
+			mockBehavior:  func(m *mock.TicketServiceMock) {},
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "oneof",
+		},
+		{
+			name:          "Missing pod",
+			claims:        claims,
+			inputBody:     `{"route": "pods.logs", "namespace": "default"}`,
+			mockBehavior:  func(m *mock.TicketServiceMock) {},
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "required",
+		},
+		{
+			name:          "Missing claims",
+			inputBody:     `{"route": "pods.exec", "namespace": "default", "pod": "my-pod"}`,
+			mockBehavior:  func(m *mock.TicketServiceMock) {},
+			expectedCode:  http.StatusUnauthorized,
+			expectedError: "missing jwt token claims",
+		},
+		{
+			name:      "Storage error",
+			claims:    claims,
+			inputBody: `{"route": "pods.exec", "namespace": "default", "pod": "my-pod"}`,
+			mockBehavior: func(m *mock.TicketServiceMock) {
+				m.On("Issue", testifyMock.Anything, claims, params).Return(nil, assert.AnError)
+			},
+			expectedCode:  http.StatusInternalServerError,
+			expectedError: "assert.AnError general error for testing",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := new(mock.TicketServiceMock)
+			tc.mockBehavior(svc)
+
+			r := setupRouter()
+			r.POST("/tickets", func(c *gin.Context) {
+				if tc.claims != nil {
+					c.Set("claims", tc.claims)
+				}
+				c.Next()
+			}, NewTicketHandler(svc).Create)
+
+			w := performRequest(r, "POST", "/tickets", bytes.NewBufferString(tc.inputBody))
+
+			assert.Equal(t, tc.expectedCode, w.Code)
+
+			if tc.expectedError != "" {
+				assert.Contains(t, w.Body.String(), tc.expectedError)
+			} else {
+				response := parseResponse[models.WebSocketTicket](t, w)
+				assert.Equal(t, "abc", response.Data.Ticket)
+			}
+
+			svc.AssertExpectations(t)
+		})
+	}
+}
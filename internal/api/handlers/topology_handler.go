@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"cluster-agent/internal/api/middleware"
 	"cluster-agent/internal/api/responses"
 	"cluster-agent/internal/services"
 	"cluster-agent/internal/services/topology"
@@ -27,6 +28,11 @@ func NewTopologyHandler(
 func (h *TopologyHandler) Get(c *gin.Context) {
 	namespace := c.Query("namespace")
 
+	if namespace == "" && !middleware.GetNamespaceScope(c).IsAll() {
+		c.JSON(http.StatusBadRequest, responses.Error("namespace is required for namespace-scoped permissions"))
+		return
+	}
+
 	snapshot, err := h.snapshotter.TakeClusterSnapshot(namespace)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, responses.Error(err.Error()))
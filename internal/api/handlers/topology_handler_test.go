@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"cluster-agent/internal/auth"
 	"cluster-agent/internal/models"
 	"cluster-agent/internal/services/graph"
 	"cluster-agent/internal/services/mock"
@@ -101,3 +102,19 @@ func TestTopologyHandler_Get(t *testing.T) {
 		})
 	}
 }
+
+func TestTopologyHandler_Get_NamespaceRequiredForScopedPermission(t *testing.T) {
+	snapshotSvc := new(mock.SnapshotServiceMock)
+	topologySvc := new(mock.TopologyServiceMock)
+
+	r := setupRouter()
+	r.GET("/topology", withNamespaceScope(auth.NewNamespaceScope("payments")), NewTopologyHandler(topologySvc, snapshotSvc).Get)
+
+	w := performRequest(r, "GET", "/topology", nil)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "namespace is required")
+
+	snapshotSvc.AssertExpectations(t)
+	topologySvc.AssertExpectations(t)
+}
@@ -1,7 +1,13 @@
 package handlers
 
 import (
+	"cluster-agent/internal/api/middleware"
+	"cluster-agent/internal/services"
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"net/http"
 )
 
@@ -12,3 +18,50 @@ var upgrader = websocket.Upgrader{
 		return true
 	},
 }
+
+// listInScope lists the namespace in the request's query, or every namespace
+// the caller was granted access to by the route's permission. A caller limited
+// to some namespaces gets one list per namespace instead of a cluster-wide
+// one, which impersonated callers are not allowed to make.
+func listInScope[T any](c *gin.Context, list func(ctx context.Context, namespace string) ([]T, error)) ([]T, error) {
+	ctx := c.Request.Context()
+	namespace := c.Query("namespace")
+	scope := middleware.GetNamespaceScope(c)
+
+	if namespace != "" || scope.IsAll() {
+		return list(ctx, namespace)
+	}
+
+	result := make([]T, 0)
+	for _, granted := range scope.Namespaces() {
+		items, err := list(ctx, granted)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, items...)
+	}
+
+	return result, nil
+}
+
+func namespaceAllowed(c *gin.Context, namespace string) bool {
+	return middleware.GetNamespaceScope(c).Allows(namespace)
+}
+
+func namespaceForbidden(namespace string) string {
+	return "permission not granted in namespace " + namespace
+}
+
+// errorStatus maps a service error to a response code. Kubernetes answers
+// Forbidden when K8S_IMPERSONATION is on and the caller's RBAC denies the call.
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrNotFound):
+		return http.StatusNotFound
+	case k8serrors.IsForbidden(err):
+		return http.StatusForbidden
+	default:
+		return http.StatusInternalServerError
+	}
+}
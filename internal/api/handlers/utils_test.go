@@ -1,7 +1,9 @@
 package handlers
 
 import (
+	"cluster-agent/internal/api/middleware"
 	"cluster-agent/internal/api/responses"
+	"cluster-agent/internal/auth"
 	"encoding/json"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -29,3 +31,10 @@ func setupRouter() *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	return gin.New()
 }
+
+func withNamespaceScope(scope *auth.NamespaceScope) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		middleware.SetNamespaceScope(c, scope)
+		c.Next()
+	}
+}
@@ -0,0 +1,133 @@
+package middleware
+
+import (
+	"bytes"
+	"cluster-agent/internal/models"
+	"cluster-agent/internal/services"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxAuditedBody bounds how much of a request body is buffered for hashing.
+const maxAuditedBody = 1 << 20
+
+type AuditMiddleware struct {
+	service services.AuditService
+}
+
+func NewAuditMiddleware(service services.AuditService) *AuditMiddleware {
+	return &AuditMiddleware{
+		service: service,
+	}
+}
+
+// Record audits the request under the given action once the handler chain has
+// finished. The caller is read once the chain returns, so it may run before
+// the authentication middleware; it must run before HasPermission so denied
+// attempts are recorded too.
+func (m *AuditMiddleware) Record(action string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		body, digest := readBody(c)
+
+		c.Next()
+
+		entry := models.AuditEntry{
+			Time:       start.UTC(),
+			Action:     action,
+			Method:     c.Request.Method,
+			Route:      c.FullPath(),
+			Target:     auditTarget(c, body),
+			BodyDigest: digest,
+			Status:     c.Writer.Status(),
+			Outcome:    auditOutcome(c.Writer.Status()),
+			DurationMs: time.Since(start).Milliseconds(),
+			ClientIP:   c.ClientIP(),
+		}
+
+		if claims := GetUserClaims(c); claims != nil {
+			entry.UserId = claims.UserId
+		}
+
+		m.service.Record(entry)
+	}
+}
+
+// readBody buffers the request body, returning it with its SHA-256 digest and
+// restoring it for the handler.
+func readBody(c *gin.Context) ([]byte, string) {
+	if c.Request.Body == nil || c.Request.Body == http.NoBody {
+		return nil, ""
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxAuditedBody))
+	if err != nil {
+		return nil, ""
+	}
+
+	c.Request.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), c.Request.Body))
+
+	if len(body) == 0 {
+		return nil, ""
+	}
+
+	sum := sha256.Sum256(body)
+	return body, "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// auditTarget prefers path params and falls back to the namespace and name in
+// JSON bodies, which covers both ScaleDeploymentParams and raw manifests.
+func auditTarget(c *gin.Context, body []byte) models.AuditTarget {
+	target := models.AuditTarget{
+		Namespace: c.Param("namespace"),
+		Name:      c.Param("name"),
+		Container: c.Query("container"),
+		Key:       c.Param("key"),
+		Command:   c.QueryArray("command"),
+	}
+
+	if target.Namespace != "" || len(body) == 0 {
+		return target
+	}
+
+	var object struct {
+		Namespace string `json:"namespace"`
+		Name      string `json:"name"`
+		Metadata  struct {
+			Namespace string `json:"namespace"`
+			Name      string `json:"name"`
+		} `json:"metadata"`
+	}
+
+	if err := json.Unmarshal(body, &object); err != nil {
+		return target
+	}
+
+	target.Namespace = object.Namespace
+	target.Name = object.Name
+
+	if target.Name == "" {
+		target.Namespace = object.Metadata.Namespace
+		target.Name = object.Metadata.Name
+	}
+
+	return target
+}
+
+func auditOutcome(status int) models.AuditOutcome {
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return models.AuditOutcomeDenied
+	case status >= 400:
+		return models.AuditOutcomeFailure
+	default:
+		return models.AuditOutcomeSuccess
+	}
+}
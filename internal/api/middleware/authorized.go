@@ -4,19 +4,39 @@ import (
 	"cluster-agent/internal/auth"
 	"cluster-agent/internal/auth/permissions"
 	"cluster-agent/internal/config"
+	"cluster-agent/internal/k8s"
+	"cluster-agent/internal/models"
+	"cluster-agent/internal/services"
+	"crypto/x509"
+	"errors"
 	"github.com/gin-gonic/gin"
 	"net/http"
-	"slices"
 	"strings"
 )
 
+const apiKeyHeader = "X-API-Key"
+
 type AuthorizedMiddleware struct {
-	cfg *config.Config
+	configs     *config.Manager
+	keys        auth.KeySet
+	revocations services.RevocationService
+	tickets     services.TicketService
+	apiKeys     services.APIKeyService
 }
 
-func NewAuthorizedMiddleware(cfg *config.Config) *AuthorizedMiddleware {
+func NewAuthorizedMiddleware(
+	configs *config.Manager,
+	keys auth.KeySet,
+	revocations services.RevocationService,
+	tickets services.TicketService,
+	apiKeys services.APIKeyService,
+) *AuthorizedMiddleware {
 	return &AuthorizedMiddleware{
-		cfg: cfg,
+		configs:     configs,
+		keys:        keys,
+		revocations: revocations,
+		tickets:     tickets,
+		apiKeys:     apiKeys,
 	}
 }
 
@@ -30,27 +50,139 @@ func (m *AuthorizedMiddleware) Handle() gin.HandlerFunc {
 		}
 
 		if tokenStr == "" {
-			tokenStr = c.Query("token")
-		}
+			if apiKey := c.GetHeader(apiKeyHeader); apiKey != "" {
+				m.handleAPIKey(c, apiKey)
+				return
+			}
+
+			if cert := clientCertificate(c); cert != nil {
+				m.handleCertificate(c, cert)
+				return
+			}
 
-		if tokenStr == "" {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing auth token"})
 			return
 		}
 
+		cfg := m.configs.Current()
 		claims := &auth.UserClaims{}
-		parsedToken, err := auth.ParseToken(tokenStr, claims, m.cfg.JWTPublicKey)
+		parsedToken, err := auth.ParseToken(tokenStr, claims, m.keys, auth.NewValidationOptions(cfg))
+
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": auth.ErrorReason(err)})
+			return
+		}
 
-		if err != nil || !parsedToken.Valid {
+		if !parsedToken.Valid {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
 			return
 		}
 
-		c.Set("claims", claims)
+		// Fail closed: a leaked token must not regain access while Redis is down.
+		revoked, err := m.revocations.IsRevoked(c.Request.Context(), claims)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "token revocation check unavailable"})
+			return
+		}
+
+		if revoked {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "token revoked"})
+			return
+		}
+
+		claims.ApplyRoles(cfg.Roles)
+
+		m.authenticate(c, claims)
+		c.Next()
+	}
+}
+
+// HandleTicket authenticates WebSocket routes, which browsers cannot send an
+// Authorization header to. It accepts only a single-use ticket issued for
+// this route and the pod in the path, never a bearer token in the query.
+func (m *AuthorizedMiddleware) HandleTicket(route models.TicketRoute) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ticket := c.Query("ticket")
+		if ticket == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing ticket"})
+			return
+		}
+
+		binding := models.CreateTicketParams{
+			Route:     route,
+			Namespace: c.Param("namespace"),
+			Pod:       c.Param("name"),
+		}
+
+		claims, err := m.tickets.Redeem(c.Request.Context(), ticket, binding)
+		if err != nil {
+			if errors.Is(err, services.ErrInvalidTicket) {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid ticket"})
+				return
+			}
+
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "ticket check unavailable"})
+			return
+		}
+
+		m.authenticate(c, claims)
 		c.Next()
 	}
 }
 
+// handleAPIKey authenticates machine clients by a key from the API key
+// endpoints. Keys carry their own permissions, revoking deletes the key.
+func (m *AuthorizedMiddleware) handleAPIKey(c *gin.Context, apiKey string) {
+	claims, err := m.apiKeys.Authenticate(c.Request.Context(), apiKey)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidAPIKey) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid api key"})
+			return
+		}
+
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "api key check unavailable"})
+		return
+	}
+
+	m.authenticate(c, claims)
+	c.Next()
+}
+
+// handleCertificate authenticates in-cluster automation by its client
+// certificate, which the TLS handshake has already verified against the CA.
+func (m *AuthorizedMiddleware) handleCertificate(c *gin.Context, cert *x509.Certificate) {
+	cfg := m.configs.Current()
+	claims, ok := auth.ClaimsFromCertificate(cert, cfg.ClientIdentities)
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unknown client certificate"})
+		return
+	}
+
+	claims.ApplyRoles(cfg.Roles)
+
+	m.authenticate(c, claims)
+	c.Next()
+}
+
+func clientCertificate(c *gin.Context) *x509.Certificate {
+	state := c.Request.TLS
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.PeerCertificates) == 0 {
+		return nil
+	}
+
+	return state.PeerCertificates[0]
+}
+
+func (m *AuthorizedMiddleware) authenticate(c *gin.Context, claims *auth.UserClaims) {
+	c.Set("claims", claims)
+	identity := k8s.NewIdentity(m.configs.Current().K8sImpersonationUserPrefix, claims.UserId, claims.Groups)
+	c.Request = c.Request.WithContext(k8s.WithIdentity(c.Request.Context(), identity))
+}
+
+// HasPermission checks the permission against the namespace addressed by the
+// request (the :namespace path param or the namespace query). Requests without
+// a namespace pass when the permission is granted anywhere; the resolved scope
+// is stored so list handlers can filter their results.
 func (m *AuthorizedMiddleware) HasPermission(permission permissions.Permission) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		claims := GetUserClaims(c)
@@ -60,7 +192,39 @@ func (m *AuthorizedMiddleware) HasPermission(permission permissions.Permission)
 			return
 		}
 
-		if !slices.Contains(claims.Permissions, permission) {
+		scope := claims.Scope(permission)
+		if scope.IsEmpty() {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "invalid permission"})
+			return
+		}
+
+		namespace := c.Param("namespace")
+		if namespace == "" {
+			namespace = c.Query("namespace")
+		}
+
+		if namespace != "" && !scope.Allows(namespace) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "permission not granted in namespace " + namespace})
+			return
+		}
+
+		SetNamespaceScope(c, scope)
+		c.Next()
+	}
+}
+
+// HasClusterPermission guards cluster-scoped resources, which require the
+// permission to be granted across all namespaces.
+func (m *AuthorizedMiddleware) HasClusterPermission(permission permissions.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims := GetUserClaims(c)
+
+		if claims == nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing jwt token claims"})
+			return
+		}
+
+		if !claims.Scope(permission).IsAll() {
 			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "invalid permission"})
 			return
 		}
@@ -78,3 +242,19 @@ func GetUserClaims(c *gin.Context) *auth.UserClaims {
 
 	return nil
 }
+
+func SetNamespaceScope(c *gin.Context, scope *auth.NamespaceScope) {
+	c.Set("namespace_scope", scope)
+}
+
+// GetNamespaceScope returns the scope resolved by HasPermission, or nil
+// (unrestricted) when the route is not guarded by it.
+func GetNamespaceScope(c *gin.Context) *auth.NamespaceScope {
+	if val, exists := c.Get("namespace_scope"); exists {
+		if scope, ok := val.(*auth.NamespaceScope); ok {
+			return scope
+		}
+	}
+
+	return nil
+}
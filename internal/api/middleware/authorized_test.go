@@ -0,0 +1,557 @@
+package middleware
+
+import (
+	"cluster-agent/internal/auth"
+	"cluster-agent/internal/auth/permissions"
+	"cluster-agent/internal/config"
+	"cluster-agent/internal/k8s"
+	"cluster-agent/internal/models"
+	"cluster-agent/internal/services"
+	"cluster-agent/internal/services/mock"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	testifyMock "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type authorizedTest struct {
+	middleware  *AuthorizedMiddleware
+	key         *rsa.PrivateKey
+	revocations *mock.RevocationServiceMock
+	tickets     *mock.TicketServiceMock
+	apiKeys     *mock.APIKeyServiceMock
+}
+
+func newAuthorizedTest(t *testing.T) *authorizedTest {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	configs := config.NewManager(&config.Config{
+		JWTPublicKey:               &key.PublicKey,
+		JWTIssuer:                  "https://auth.example.com",
+		JWTAudience:                "cluster-agent",
+		JWTLeeway:                  30 * time.Second,
+		JWTRequireExpiration:       true,
+		K8sImpersonationUserPrefix: "agent:",
+		ClientIdentities: []config.ClientIdentity{{
+			CommonName:  "deployer",
+			UserId:      "deployer",
+			Permissions: []permissions.Permission{permissions.PodsView},
+		}},
+	})
+
+	test := &authorizedTest{
+		key:         key,
+		revocations: new(mock.RevocationServiceMock),
+		tickets:     new(mock.TicketServiceMock),
+		apiKeys:     new(mock.APIKeyServiceMock),
+	}
+	test.middleware = NewAuthorizedMiddleware(configs, auth.NewConfigKeySet(configs), test.revocations, test.tickets, test.apiKeys)
+
+	return test
+}
+
+// token signs claims for user 42 that pass validation, after modify.
+func (a *authorizedTest) token(t *testing.T, modify func(claims *auth.UserClaims)) string {
+	t.Helper()
+
+	now := time.Now()
+	claims := &auth.UserClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        "token-1",
+			Issuer:    "https://auth.example.com",
+			Audience:  jwt.ClaimStrings{"cluster-agent"},
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+			IssuedAt:  jwt.NewNumericDate(now.Add(-time.Minute)),
+		},
+		UserId: "42",
+	}
+	if modify != nil {
+		modify(claims)
+	}
+
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodRS256, claims).SignedString(a.key)
+	require.NoError(t, err)
+	return signed
+}
+
+func performRequest(r http.Handler, method, path string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func bearer(token string) map[string]string {
+	return map[string]string{"Authorization": "Bearer " + token}
+}
+
+func errorMessage(t *testing.T, w *httptest.ResponseRecorder) string {
+	t.Helper()
+
+	var body struct {
+		Error string `json:"error"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	return body.Error
+}
+
+// router guards namespaced pod routes, a cluster-scoped node route and
+// ticket-only log and event stream routes the way app.go does.
+func (a *authorizedTest) router() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+
+	ok := func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	}
+
+	api := r.Group("/api", a.middleware.Handle())
+	api.GET("/pods", a.middleware.HasPermission(permissions.PodsView), ok)
+	api.GET("/pods/:namespace/:name", a.middleware.HasPermission(permissions.PodsView), ok)
+	api.GET("/nodes", a.middleware.HasClusterPermission(permissions.NodesView), ok)
+
+	ws := r.Group("/ws")
+	ws.GET("/pods/:namespace/:name/logs", a.middleware.HandleTicket(models.TicketRoutePodLogs), a.middleware.HasPermission(permissions.PodsView), ok)
+	ws.GET("/events/stream", a.middleware.HandleTicket(models.TicketRouteEvents), a.middleware.HasPermission(permissions.EventsView), ok)
+
+	return r
+}
+
+func TestAuthorizedMiddleware_ImpersonatedIdentity(t *testing.T) {
+	test := newAuthorizedTest(t)
+	test.revocations.On("IsRevoked", testifyMock.Anything, testifyMock.Anything).Return(false, nil)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+
+	var identity k8s.Identity
+	r.GET("/whoami", test.middleware.Handle(), func(c *gin.Context) {
+		identity, _ = k8s.IdentityFrom(c.Request.Context())
+		c.Status(http.StatusOK)
+	})
+
+	token := test.token(t, func(claims *auth.UserClaims) {
+		claims.Groups = []string{"developers", "system:masters"}
+	})
+	w := performRequest(r, http.MethodGet, "/whoami", bearer(token))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, k8s.Identity{
+		User:   "agent:42",
+		Groups: []string{"agent:developers", "agent:system:masters"},
+	}, identity)
+}
+
+func TestAuthorizedMiddleware_NamespaceScope(t *testing.T) {
+	test := newAuthorizedTest(t)
+	test.revocations.On("IsRevoked", testifyMock.Anything, testifyMock.Anything).Return(false, nil)
+	r := test.router()
+
+	scoped := test.token(t, func(claims *auth.UserClaims) {
+		claims.Permissions = []permissions.Permission{
+			permissions.PodsView.Scoped("payments"),
+			permissions.NodesView.Scoped("payments"),
+		}
+	})
+	clusterWide := test.token(t, func(claims *auth.UserClaims) {
+		claims.Permissions = []permissions.Permission{permissions.PodsView, permissions.NodesView}
+	})
+
+	type testCase struct {
+		name          string
+		token         string
+		path          string
+		expectedCode  int
+		expectedError string
+	}
+
+	tests := []testCase{
+		{
+			name:         "Granted namespace in the path",
+			token:        scoped,
+			path:         "/api/pods/payments/web",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:          "Other namespace in the path",
+			token:         scoped,
+			path:          "/api/pods/other/web",
+			expectedCode:  http.StatusForbidden,
+			expectedError: "permission not granted in namespace other",
+		},
+		{
+			name:         "Granted namespace in the query",
+			token:        scoped,
+			path:         "/api/pods?namespace=payments",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:          "Other namespace in the query",
+			token:         scoped,
+			path:          "/api/pods?namespace=other",
+			expectedCode:  http.StatusForbidden,
+			expectedError: "permission not granted in namespace other",
+		},
+		{
+			name:         "List without a namespace",
+			token:        scoped,
+			path:         "/api/pods",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:          "Cluster route rejects a namespace-scoped grant",
+			token:         scoped,
+			path:          "/api/nodes",
+			expectedCode:  http.StatusForbidden,
+			expectedError: "invalid permission",
+		},
+		{
+			name:         "Cluster route accepts a cluster-wide grant",
+			token:        clusterWide,
+			path:         "/api/nodes",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Cluster-wide grant reaches any namespace",
+			token:        clusterWide,
+			path:         "/api/pods/other/web",
+			expectedCode: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := performRequest(r, http.MethodGet, tt.path, bearer(tt.token))
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			if tt.expectedError != "" {
+				assert.Equal(t, tt.expectedError, errorMessage(t, w))
+			}
+		})
+	}
+}
+
+func TestAuthorizedMiddleware_Revocation(t *testing.T) {
+	type testCase struct {
+		name          string
+		revoked       bool
+		err           error
+		expectedCode  int
+		expectedError string
+	}
+
+	tests := []testCase{
+		{
+			name:         "Not revoked",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:          "Revoked token",
+			revoked:       true,
+			expectedCode:  http.StatusUnauthorized,
+			expectedError: "token revoked",
+		},
+		{
+			name:          "Revocation store error fails closed",
+			err:           errors.New("redis: connection refused"),
+			expectedCode:  http.StatusServiceUnavailable,
+			expectedError: "token revocation check unavailable",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			test := newAuthorizedTest(t)
+			test.revocations.On("IsRevoked", testifyMock.Anything, testifyMock.MatchedBy(func(claims *auth.UserClaims) bool {
+				return claims.ID == "token-1"
+			})).Return(tt.revoked, tt.err)
+
+			token := test.token(t, func(claims *auth.UserClaims) {
+				claims.Permissions = []permissions.Permission{permissions.PodsView}
+			})
+			w := performRequest(test.router(), http.MethodGet, "/api/pods", bearer(token))
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			if tt.expectedError != "" {
+				assert.Equal(t, tt.expectedError, errorMessage(t, w))
+			}
+			test.revocations.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuthorizedMiddleware_InvalidToken(t *testing.T) {
+	test := newAuthorizedTest(t)
+	r := test.router()
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	forger := &authorizedTest{key: otherKey}
+
+	type testCase struct {
+		name          string
+		headers       map[string]string
+		expectedError string
+	}
+
+	tests := []testCase{
+		{
+			name:          "Missing token",
+			expectedError: "missing auth token",
+		},
+		{
+			name:          "Malformed token",
+			headers:       bearer("not-a-jwt"),
+			expectedError: "malformed token",
+		},
+		{
+			name: "Expired token",
+			headers: bearer(test.token(t, func(claims *auth.UserClaims) {
+				claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Hour))
+			})),
+			expectedError: "token expired",
+		},
+		{
+			name:          "Signed by another key",
+			headers:       bearer(forger.token(t, nil)),
+			expectedError: "invalid token signature",
+		},
+		{
+			name: "Wrong issuer",
+			headers: bearer(test.token(t, func(claims *auth.UserClaims) {
+				claims.Issuer = "https://evil.example.com"
+			})),
+			expectedError: "invalid token issuer",
+		},
+		{
+			name: "Wrong audience",
+			headers: bearer(test.token(t, func(claims *auth.UserClaims) {
+				claims.Audience = jwt.ClaimStrings{"another-service"}
+			})),
+			expectedError: "invalid token audience",
+		},
+		{
+			name: "Missing expiration",
+			headers: bearer(test.token(t, func(claims *auth.UserClaims) {
+				claims.ExpiresAt = nil
+			})),
+			expectedError: "token is missing a required claim",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := performRequest(r, http.MethodGet, "/api/pods", tt.headers)
+
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+			assert.Equal(t, tt.expectedError, errorMessage(t, w))
+		})
+	}
+
+	test.revocations.AssertNotCalled(t, "IsRevoked", testifyMock.Anything, testifyMock.Anything)
+}
+
+func TestAuthorizedMiddleware_Ticket(t *testing.T) {
+	claims := &auth.UserClaims{UserId: "42", Permissions: []permissions.Permission{permissions.PodsView}}
+	binding := models.CreateTicketParams{Route: models.TicketRoutePodLogs, Namespace: "payments", Pod: "web"}
+
+	t.Run("Ticket bound to the route and pod", func(t *testing.T) {
+		test := newAuthorizedTest(t)
+		test.tickets.On("Redeem", testifyMock.Anything, "ticket-1", binding).Return(claims, nil)
+
+		w := performRequest(test.router(), http.MethodGet, "/ws/pods/payments/web/logs?ticket=ticket-1", nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		test.tickets.AssertExpectations(t)
+	})
+
+	t.Run("Path does not match the binding", func(t *testing.T) {
+		test := newAuthorizedTest(t)
+		test.tickets.On("Redeem", testifyMock.Anything, "ticket-1", models.CreateTicketParams{
+			Route:     models.TicketRoutePodLogs,
+			Namespace: "payments",
+			Pod:       "db",
+		}).Return(nil, services.ErrInvalidTicket)
+
+		w := performRequest(test.router(), http.MethodGet, "/ws/pods/payments/db/logs?ticket=ticket-1", nil)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Equal(t, "invalid ticket", errorMessage(t, w))
+		test.tickets.AssertExpectations(t)
+	})
+
+	t.Run("Missing ticket", func(t *testing.T) {
+		test := newAuthorizedTest(t)
+
+		w := performRequest(test.router(), http.MethodGet, "/ws/pods/payments/web/logs", bearer(test.token(t, nil)))
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Equal(t, "missing ticket", errorMessage(t, w))
+	})
+
+	t.Run("Ticket store error", func(t *testing.T) {
+		test := newAuthorizedTest(t)
+		test.tickets.On("Redeem", testifyMock.Anything, "ticket-1", binding).Return(nil, errors.New("redis: connection refused"))
+
+		w := performRequest(test.router(), http.MethodGet, "/ws/pods/payments/web/logs?ticket=ticket-1", nil)
+
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Equal(t, "ticket check unavailable", errorMessage(t, w))
+	})
+
+	t.Run("Ticket is not accepted on other routes", func(t *testing.T) {
+		test := newAuthorizedTest(t)
+
+		w := performRequest(test.router(), http.MethodGet, "/api/pods/payments/web?ticket=ticket-1", nil)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Equal(t, "missing auth token", errorMessage(t, w))
+		test.tickets.AssertNotCalled(t, "Redeem", testifyMock.Anything, testifyMock.Anything, testifyMock.Anything)
+	})
+}
+
+func TestAuthorizedMiddleware_EventStreamTicket(t *testing.T) {
+	test := newAuthorizedTest(t)
+	claims := &auth.UserClaims{UserId: "42", Permissions: []permissions.Permission{permissions.EventsView}}
+	binding := models.CreateTicketParams{Route: models.TicketRouteEvents}
+	test.tickets.On("Redeem", testifyMock.Anything, "ticket-1", binding).Return(claims, nil).Once()
+	test.tickets.On("Redeem", testifyMock.Anything, "ticket-1", binding).Return(nil, services.ErrInvalidTicket).Once()
+
+	w := performRequest(test.router(), http.MethodGet, "/ws/events/stream?ticket=ticket-1", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// A closed stream does not give the ticket back; the client reconnects
+	// with a new one.
+	w = performRequest(test.router(), http.MethodGet, "/ws/events/stream?ticket=ticket-1", nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, "invalid ticket", errorMessage(t, w))
+	test.tickets.AssertExpectations(t)
+}
+
+func TestAuthorizedMiddleware_APIKey(t *testing.T) {
+	type testCase struct {
+		name          string
+		claims        *auth.UserClaims
+		err           error
+		expectedCode  int
+		expectedError string
+	}
+
+	tests := []testCase{
+		{
+			name:         "Valid key",
+			claims:       &auth.UserClaims{UserId: "apikey:ci", Permissions: []permissions.Permission{permissions.PodsView}},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:          "Valid key without the permission",
+			claims:        &auth.UserClaims{UserId: "apikey:ci", Permissions: []permissions.Permission{permissions.NodesView}},
+			expectedCode:  http.StatusForbidden,
+			expectedError: "invalid permission",
+		},
+		{
+			name:          "Unknown or revoked key",
+			err:           services.ErrInvalidAPIKey,
+			expectedCode:  http.StatusUnauthorized,
+			expectedError: "invalid api key",
+		},
+		{
+			name:          "Key store error",
+			err:           errors.New("redis: connection refused"),
+			expectedCode:  http.StatusServiceUnavailable,
+			expectedError: "api key check unavailable",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			test := newAuthorizedTest(t)
+			test.apiKeys.On("Authenticate", testifyMock.Anything, "ci.secret").Return(tt.claims, tt.err)
+
+			w := performRequest(test.router(), http.MethodGet, "/api/pods", map[string]string{apiKeyHeader: "ci.secret"})
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			if tt.expectedError != "" {
+				assert.Equal(t, tt.expectedError, errorMessage(t, w))
+			}
+			test.apiKeys.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuthorizedMiddleware_ClientCertificate(t *testing.T) {
+	certificate := func(commonName string) *x509.Certificate {
+		return &x509.Certificate{Subject: pkix.Name{CommonName: commonName}}
+	}
+
+	type testCase struct {
+		name          string
+		state         *tls.ConnectionState
+		expectedCode  int
+		expectedError string
+	}
+
+	tests := []testCase{
+		{
+			name: "Verified certificate with a mapping",
+			state: &tls.ConnectionState{
+				PeerCertificates: []*x509.Certificate{certificate("deployer")},
+				VerifiedChains:   [][]*x509.Certificate{{certificate("deployer")}},
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name: "Unverified certificate",
+			state: &tls.ConnectionState{
+				PeerCertificates: []*x509.Certificate{certificate("deployer")},
+			},
+			expectedCode:  http.StatusUnauthorized,
+			expectedError: "missing auth token",
+		},
+		{
+			name: "Verified certificate without a mapping",
+			state: &tls.ConnectionState{
+				PeerCertificates: []*x509.Certificate{certificate("stranger")},
+				VerifiedChains:   [][]*x509.Certificate{{certificate("stranger")}},
+			},
+			expectedCode:  http.StatusUnauthorized,
+			expectedError: "unknown client certificate",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			test := newAuthorizedTest(t)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/pods", nil)
+			req.TLS = tt.state
+			w := httptest.NewRecorder()
+			test.router().ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			if tt.expectedError != "" {
+				assert.Equal(t, tt.expectedError, errorMessage(t, w))
+			}
+		})
+	}
+}
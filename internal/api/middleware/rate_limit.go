@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"cluster-agent/internal/config"
+	"context"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimiter counts requests in a sliding window shared by all replicas.
+type RateLimiter interface {
+	// Allow records a request under key and reports whether it fits in the
+	// limit, how many requests remain and, when rejected, when to retry.
+	Allow(ctx context.Context, key string, limit int, window time.Duration) (bool, int, time.Duration, error)
+}
+
+type RateLimitMiddleware struct {
+	configs *config.Manager
+	limiter RateLimiter
+}
+
+func NewRateLimitMiddleware(configs *config.Manager, limiter RateLimiter) *RateLimitMiddleware {
+	return &RateLimitMiddleware{
+		configs: configs,
+		limiter: limiter,
+	}
+}
+
+// Limit throttles each caller per route group, using the group's configured
+// limit or the "default" one. It must run after Handle so the caller is known.
+// Requests pass when Redis is unavailable; limits protect the Kubernetes API
+// budget and are not a security boundary.
+func (m *RateLimitMiddleware) Limit(group string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims := GetUserClaims(c)
+		if claims == nil {
+			c.Next()
+			return
+		}
+
+		m.limit(c, group, group+":"+claims.UserId)
+	}
+}
+
+// LimitClient throttles by client address instead, for ticket routes: it runs
+// before the ticket is redeemed, so a rejected request does not use it up.
+func (m *RateLimitMiddleware) LimitClient(group string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		m.limit(c, group, group+":ip:"+c.ClientIP())
+	}
+}
+
+func (m *RateLimitMiddleware) limit(c *gin.Context, group, key string) {
+	limits := m.configs.Current().RateLimits
+	limit, ok := limits[group]
+	if !ok {
+		limit, ok = limits[config.DefaultRateLimit]
+	}
+
+	if !ok || limit.Requests <= 0 {
+		c.Next()
+		return
+	}
+
+	allowed, remaining, retryAfter, err := m.limiter.Allow(c.Request.Context(), key, limit.Requests, limit.Window)
+	if err != nil {
+		log.Printf("Rate limit check failed for %s: %v", key, err)
+		c.Next()
+		return
+	}
+
+	c.Header("X-RateLimit-Limit", strconv.Itoa(limit.Requests))
+	c.Header("X-RateLimit-Remaining", strconv.Itoa(remaining))
+
+	if !allowed {
+		seconds := int(math.Ceil(retryAfter.Seconds()))
+		c.Header("Retry-After", strconv.Itoa(max(seconds, 1)))
+		c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "rate limit exceeded"})
+		return
+	}
+
+	c.Next()
+}
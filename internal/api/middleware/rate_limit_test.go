@@ -0,0 +1,178 @@
+package middleware
+
+import (
+	"cluster-agent/internal/auth"
+	"cluster-agent/internal/config"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeRateLimiter struct {
+	allowed    bool
+	remaining  int
+	retryAfter time.Duration
+	err        error
+
+	keys []string
+}
+
+func (l *fakeRateLimiter) Allow(_ context.Context, key string, limit int, window time.Duration) (bool, int, time.Duration, error) {
+	l.keys = append(l.keys, key)
+	return l.allowed, l.remaining, l.retryAfter, l.err
+}
+
+func TestRateLimitMiddleware_Limit(t *testing.T) {
+	type testCase struct {
+		name            string
+		limits          map[string]config.RateLimit
+		claims          *auth.UserClaims
+		limiter         *fakeRateLimiter
+		expectedCode    int
+		expectedKeys    []string
+		expectedHeaders map[string]string
+	}
+
+	limits := map[string]config.RateLimit{
+		config.DefaultRateLimit: {Requests: 600, Window: time.Minute},
+		"pods":                  {Requests: 10, Window: time.Minute},
+	}
+	caller := &auth.UserClaims{UserId: "42"}
+
+	tests := []testCase{
+		{
+			name:         "Allowed",
+			limits:       limits,
+			claims:       caller,
+			limiter:      &fakeRateLimiter{allowed: true, remaining: 9},
+			expectedCode: http.StatusOK,
+			expectedKeys: []string{"pods:42"},
+			expectedHeaders: map[string]string{
+				"X-RateLimit-Limit":     "10",
+				"X-RateLimit-Remaining": "9",
+				"Retry-After":           "",
+			},
+		},
+		{
+			name:         "Rejected with Retry-After rounded up",
+			limits:       limits,
+			claims:       caller,
+			limiter:      &fakeRateLimiter{retryAfter: 1500 * time.Millisecond},
+			expectedCode: http.StatusTooManyRequests,
+			expectedKeys: []string{"pods:42"},
+			expectedHeaders: map[string]string{
+				"X-RateLimit-Limit":     "10",
+				"X-RateLimit-Remaining": "0",
+				"Retry-After":           "2",
+			},
+		},
+		{
+			name:            "Rejected asks for at least one second",
+			limits:          limits,
+			claims:          caller,
+			limiter:         &fakeRateLimiter{retryAfter: 0},
+			expectedCode:    http.StatusTooManyRequests,
+			expectedKeys:    []string{"pods:42"},
+			expectedHeaders: map[string]string{"Retry-After": "1"},
+		},
+		{
+			name:         "Fails open on a Redis error",
+			limits:       limits,
+			claims:       caller,
+			limiter:      &fakeRateLimiter{err: errors.New("connection refused")},
+			expectedCode: http.StatusOK,
+			expectedKeys: []string{"pods:42"},
+			expectedHeaders: map[string]string{
+				"X-RateLimit-Limit": "",
+				"Retry-After":       "",
+			},
+		},
+		{
+			name:            "Group without its own limit uses the default",
+			limits:          map[string]config.RateLimit{config.DefaultRateLimit: {Requests: 600, Window: time.Minute}},
+			claims:          caller,
+			limiter:         &fakeRateLimiter{allowed: true, remaining: 599},
+			expectedCode:    http.StatusOK,
+			expectedKeys:    []string{"pods:42"},
+			expectedHeaders: map[string]string{"X-RateLimit-Limit": "600"},
+		},
+		{
+			name:         "No configured limit",
+			claims:       caller,
+			limiter:      &fakeRateLimiter{},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Limit of zero disables the group",
+			limits:       map[string]config.RateLimit{"pods": {Requests: 0, Window: time.Minute}},
+			claims:       caller,
+			limiter:      &fakeRateLimiter{},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Unauthenticated request is not counted",
+			limits:       limits,
+			limiter:      &fakeRateLimiter{},
+			expectedCode: http.StatusOK,
+		},
+	}
+
+	gin.SetMode(gin.TestMode)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configs := config.NewManager(&config.Config{RateLimits: tt.limits})
+			limit := NewRateLimitMiddleware(configs, tt.limiter)
+
+			r := gin.New()
+			r.GET("/pods", func(c *gin.Context) {
+				if tt.claims != nil {
+					c.Set("claims", tt.claims)
+				}
+			}, limit.Limit("pods"), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/pods", nil))
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			assert.Equal(t, tt.expectedKeys, tt.limiter.keys)
+			for header, expected := range tt.expectedHeaders {
+				assert.Equal(t, expected, w.Header().Get(header), header)
+			}
+		})
+	}
+}
+
+func TestRateLimitMiddleware_LimitClient(t *testing.T) {
+	configs := config.NewManager(&config.Config{RateLimits: map[string]config.RateLimit{
+		"events": {Requests: 10, Window: time.Minute},
+	}})
+	limiter := &fakeRateLimiter{retryAfter: time.Second}
+	limit := NewRateLimitMiddleware(configs, limiter)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+
+	reached := false
+	r.GET("/events/stream", limit.LimitClient("events"), func(c *gin.Context) {
+		reached = true
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/events/stream", nil)
+	req.RemoteAddr = "192.0.2.7:51234"
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+	assert.Equal(t, []string{"events:ip:192.0.2.7"}, limiter.keys)
+	assert.False(t, reached, "later handlers, such as the ticket redemption, do not run")
+}
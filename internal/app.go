@@ -2,9 +2,13 @@ package internal
 
 import (
 	"cluster-agent/internal/api/middleware"
+	"cluster-agent/internal/auth"
 	"cluster-agent/internal/auth/permissions"
+	"cluster-agent/internal/config"
 	"cluster-agent/internal/consumers"
+	"cluster-agent/internal/models"
 	"cluster-agent/internal/producers"
+	"cluster-agent/internal/services"
 	"context"
 	"errors"
 	"fmt"
@@ -27,143 +31,244 @@ type App struct {
 	Router               *gin.Engine
 	Handlers             *handlers.HandlerContainer
 	EventCollector       *producers.EventCollector
+	EventAggregator      *consumers.EventAggregator
 	EventBatcher         *consumers.EventBatcher
 	InformerFactory      informers.SharedInformerFactory
+	KeySet               auth.KeySet
+	AuditService         services.AuditService
+	ConfigManager        *config.Manager
 	authorizedMiddleware *middleware.AuthorizedMiddleware
+	auditMiddleware      *middleware.AuditMiddleware
+	rateLimitMiddleware  *middleware.RateLimitMiddleware
+	routes               *handlers.RouteCatalog
+	cfg                  *config.Config
 }
 
 func NewApp(
 	h *handlers.HandlerContainer,
 	authorizedMiddleware *middleware.AuthorizedMiddleware,
+	auditMiddleware *middleware.AuditMiddleware,
+	rateLimitMiddleware *middleware.RateLimitMiddleware,
 	collector *producers.EventCollector,
+	aggregator *consumers.EventAggregator,
 	batcher *consumers.EventBatcher,
 	factory informers.SharedInformerFactory,
-) *App {
+	keySet auth.KeySet,
+	auditService services.AuditService,
+	routes *handlers.RouteCatalog,
+	configs *config.Manager,
+	cfg *config.Config,
+) (*App, error) {
+	router, err := newRouter(cfg)
+	if err != nil {
+		return nil, err
+	}
+
 	app := &App{
-		Router:               gin.Default(),
+		Router:               router,
 		Handlers:             h,
 		authorizedMiddleware: authorizedMiddleware,
+		auditMiddleware:      auditMiddleware,
+		rateLimitMiddleware:  rateLimitMiddleware,
 		EventCollector:       collector,
+		EventAggregator:      aggregator,
 		EventBatcher:         batcher,
 		InformerFactory:      factory,
+		KeySet:               keySet,
+		AuditService:         auditService,
+		ConfigManager:        configs,
+		routes:               routes,
+		cfg:                  cfg,
 	}
 
 	app.setRoutes()
 
-	return app
+	return app, nil
+}
+
+// newRouter trusts forwarding headers only from the configured proxies; gin
+// would otherwise take the client address from any peer's X-Forwarded-For.
+func newRouter(cfg *config.Config) (*gin.Engine, error) {
+	router := gin.Default()
+	if err := router.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		return nil, fmt.Errorf("invalid trusted proxies: %w", err)
+	}
+
+	return router, nil
 }
 
 func (app *App) setRoutes() {
+	app.setWebSocketRoutes()
+
 	v1 := app.Router.Group("/api/v1")
 	v1.Use(app.authorizedMiddleware.Handle())
 	{
-		pods := v1.Group("/pods")
+		me := v1.Group("/me")
 		{
-			pods.GET("",
-				app.authorizedMiddleware.HasPermission(permissions.PodsView),
-				app.Handlers.Pod.List,
-			)
-			pods.GET("/:namespace/:name",
-				app.authorizedMiddleware.HasPermission(permissions.PodsView),
-				app.Handlers.Pod.Get,
-			)
+			app.handle(me, http.MethodGet, "", public(), app.Handlers.Me.Get)
+		}
 
-			pods.GET("/:namespace/:name/logs",
-				app.authorizedMiddleware.HasPermission(permissions.PodsView),
-				app.Handlers.PodLogs.StreamLogs,
-			)
-			pods.GET("/:namespace/:name/exec",
-				app.authorizedMiddleware.HasPermission(permissions.PodsView),
-				app.Handlers.Terminal.Exec,
-			)
-			pods.GET("/:namespace/:name/network",
-				app.authorizedMiddleware.HasPermission(permissions.PodsView),
-				app.Handlers.NetworkInspector.GetConnections,
-			)
+		pods := v1.Group("/pods")
+		pods.Use(app.rateLimitMiddleware.Limit("pods"))
+		{
+			app.handle(pods, http.MethodGet, "", requires(permissions.PodsView), app.Handlers.Pod.List)
+			app.handle(pods, http.MethodGet, "/:namespace/:name", requires(permissions.PodsView), app.Handlers.Pod.Get)
+			app.handle(pods, http.MethodGet, "/:namespace/:name/network", requires(permissions.PodsNetwork), app.Handlers.NetworkInspector.GetConnections)
 		}
 
 		deployments := v1.Group("/deployments")
+		deployments.Use(app.rateLimitMiddleware.Limit("deployments"))
 		{
-			deployments.GET("",
-				app.authorizedMiddleware.HasPermission(permissions.DeploymentsView),
-				app.Handlers.Deployment.List,
-			)
-
-			deployments.GET("/:namespace/:name",
-				app.authorizedMiddleware.HasPermission(permissions.DeploymentsView),
-				app.Handlers.Deployment.Get,
-			)
-
-			deployments.POST("",
-				app.authorizedMiddleware.HasPermission(permissions.DeploymentsCreate),
+			app.handle(deployments, http.MethodGet, "", requires(permissions.DeploymentsView), app.Handlers.Deployment.List)
+			app.handle(deployments, http.MethodGet, "/:namespace/:name", requires(permissions.DeploymentsView), app.Handlers.Deployment.Get)
+			app.handle(deployments, http.MethodPost, "",
+				requires(permissions.DeploymentsCreate).audited("deployments.create"),
 				app.Handlers.Deployment.Create,
 			)
-
-			deployments.DELETE("/:namespace/:name",
-				app.authorizedMiddleware.HasPermission(permissions.DeploymentsDelete),
+			app.handle(deployments, http.MethodDelete, "/:namespace/:name",
+				requires(permissions.DeploymentsDelete).audited("deployments.delete"),
 				app.Handlers.Deployment.Delete,
 			)
-
-			deployments.PATCH("/scale",
-				app.authorizedMiddleware.HasPermission(permissions.DeploymentsScale),
+			app.handle(deployments, http.MethodPatch, "/scale",
+				requires(permissions.DeploymentsScale).audited("deployments.scale"),
 				app.Handlers.Deployment.ScaleDeployment,
 			)
 		}
 
 		services := v1.Group("/services")
-		services.Use(app.authorizedMiddleware.HasPermission(permissions.ServicesView))
+		services.Use(app.rateLimitMiddleware.Limit("services"))
 		{
-			services.GET("", app.Handlers.Service.List)
-			services.GET("/:namespace/:name", app.Handlers.Service.Get)
+			app.handle(services, http.MethodGet, "", requires(permissions.ServicesView), app.Handlers.Service.List)
+			app.handle(services, http.MethodGet, "/:namespace/:name", requires(permissions.ServicesView), app.Handlers.Service.Get)
 		}
 
 		configmaps := v1.Group("/configmaps")
-		configmaps.Use(app.authorizedMiddleware.HasPermission(permissions.ConfigMapsView))
+		configmaps.Use(app.rateLimitMiddleware.Limit("configmaps"))
 		{
-			configmaps.GET("", app.Handlers.ConfigMaps.List)
-			configmaps.GET("/:namespace/:name", app.Handlers.ConfigMaps.Get)
+			app.handle(configmaps, http.MethodGet, "", requires(permissions.ConfigMapsView), app.Handlers.ConfigMaps.List)
+			app.handle(configmaps, http.MethodGet, "/:namespace/:name", requires(permissions.ConfigMapsView), app.Handlers.ConfigMaps.Get)
 		}
 
 		secrets := v1.Group("/secrets")
-		secrets.Use(app.authorizedMiddleware.HasPermission(permissions.SecretsView))
+		secrets.Use(app.rateLimitMiddleware.Limit("secrets"))
 		{
-			secrets.GET("", app.Handlers.Secrets.List)
-			secrets.GET("/:namespace/:name", app.Handlers.Secrets.Get)
+			app.handle(secrets, http.MethodGet, "", requires(permissions.SecretsView), app.Handlers.Secrets.List)
+			app.handle(secrets, http.MethodGet, "/:namespace/:name",
+				requires(permissions.SecretsView).audited("secrets.get"),
+				app.Handlers.Secrets.Get,
+			)
+			app.handle(secrets, http.MethodGet, "/:namespace/:name/keys/:key",
+				requires(permissions.SecretsReveal).audited("secrets.reveal"),
+				app.Handlers.Secrets.Reveal,
+			)
 		}
 
 		ingresses := v1.Group("/ingresses")
-		ingresses.Use(app.authorizedMiddleware.HasPermission(permissions.IngressesView))
+		ingresses.Use(app.rateLimitMiddleware.Limit("ingresses"))
 		{
-			ingresses.GET("", app.Handlers.Ingresses.List)
-			ingresses.GET("/:namespace/:name", app.Handlers.Ingresses.Get)
+			app.handle(ingresses, http.MethodGet, "", requires(permissions.IngressesView), app.Handlers.Ingresses.List)
+			app.handle(ingresses, http.MethodGet, "/:namespace/:name", requires(permissions.IngressesView), app.Handlers.Ingresses.Get)
 		}
 
 		pvcs := v1.Group("/persistentvolumeclaims")
-		pvcs.Use(app.authorizedMiddleware.HasPermission(permissions.PVCsView))
+		pvcs.Use(app.rateLimitMiddleware.Limit("pvcs"))
 		{
-			pvcs.GET("", app.Handlers.Pvcs.List)
-			pvcs.GET("/:namespace/:name", app.Handlers.Pvcs.Get)
+			app.handle(pvcs, http.MethodGet, "", requires(permissions.PVCsView), app.Handlers.Pvcs.List)
+			app.handle(pvcs, http.MethodGet, "/:namespace/:name", requires(permissions.PVCsView), app.Handlers.Pvcs.Get)
 		}
 
 		namespace := v1.Group("/namespaces")
+		namespace.Use(app.rateLimitMiddleware.Limit("namespaces"))
 		{
-			namespace.GET("", app.Handlers.Namespace.List)
+			app.handle(namespace, http.MethodGet, "", public(), app.Handlers.Namespace.List)
 		}
 
 		node := v1.Group("/nodes")
-		node.Use(app.authorizedMiddleware.HasPermission(permissions.NodesView))
+		node.Use(app.rateLimitMiddleware.Limit("nodes"))
+		{
+			app.handle(node, http.MethodGet, "", requiresCluster(permissions.NodesView), app.Handlers.Node.List)
+		}
+
+		tickets := v1.Group("/tickets")
+		tickets.Use(app.rateLimitMiddleware.Limit("tickets"))
+		{
+			app.handle(tickets, http.MethodPost, "", public(), app.Handlers.Ticket.Create)
+		}
+
+		tokens := v1.Group("/tokens")
+		tokens.Use(app.rateLimitMiddleware.Limit("tokens"))
+		{
+			app.handle(tokens, http.MethodPost, "/revoke",
+				requiresCluster(permissions.TokensRevoke).audited("tokens.revoke"),
+				app.Handlers.Revocation.Revoke,
+			)
+		}
+
+		apiKeys := v1.Group("/apikeys")
+		apiKeys.Use(app.rateLimitMiddleware.Limit("apikeys"))
+		{
+			app.handle(apiKeys, http.MethodGet, "", requiresCluster(permissions.APIKeysManage), app.Handlers.APIKey.List)
+			app.handle(apiKeys, http.MethodPost, "",
+				requiresCluster(permissions.APIKeysManage).audited("apikeys.create"),
+				app.Handlers.APIKey.Create,
+			)
+			app.handle(apiKeys, http.MethodDelete, "/:id",
+				requiresCluster(permissions.APIKeysManage).audited("apikeys.revoke"),
+				app.Handlers.APIKey.Revoke,
+			)
+		}
+
+		events := v1.Group("/events")
+		events.Use(app.rateLimitMiddleware.Limit("events"))
+		{
+			app.handle(events, http.MethodGet, "", requires(permissions.EventsView), app.Handlers.Events.List)
+			app.handle(events, http.MethodGet, "/pipeline", requiresCluster(permissions.EventsView), app.Handlers.EventPipeline.Stats)
+		}
+
+		configuration := v1.Group("/config")
+		configuration.Use(app.rateLimitMiddleware.Limit("config"))
 		{
-			node.GET("", app.Handlers.Node.List)
+			app.handle(configuration, http.MethodGet, "/status", requiresCluster(permissions.ConfigView), app.Handlers.Config.Status)
+		}
+
+		audit := v1.Group("/audit")
+		audit.Use(app.rateLimitMiddleware.Limit("audit"))
+		{
+			app.handle(audit, http.MethodGet, "", requires(permissions.AuditView), app.Handlers.Audit.Query)
 		}
 
 		topology := v1.Group("/topology")
-		topology.Use(app.authorizedMiddleware.HasPermission(permissions.TopologyView))
+		topology.Use(app.rateLimitMiddleware.Limit("topology"))
 		{
-			topology.GET("", app.Handlers.Topology.Get)
+			app.handle(topology, http.MethodGet, "", requires(permissions.TopologyView), app.Handlers.Topology.Get)
 		}
 	}
 }
 
+// setWebSocketRoutes registers the streaming routes, which authenticate with a
+// ticket from POST /api/v1/tickets instead of the Authorization header.
+func (app *App) setWebSocketRoutes() {
+	pods := app.Router.Group("/api/v1/pods")
+	{
+		app.handle(pods, http.MethodGet, "/:namespace/:name/logs",
+			requires(permissions.PodsView).viaTicket(models.TicketRoutePodLogs).limited("pods"),
+			app.Handlers.PodLogs.StreamLogs,
+		)
+		app.handle(pods, http.MethodGet, "/:namespace/:name/exec",
+			requires(permissions.PodsExec).viaTicket(models.TicketRoutePodExec).audited("pods.exec").limited("pods"),
+			app.Handlers.Terminal.Exec,
+		)
+	}
+
+	events := app.Router.Group("/api/v1/events")
+	{
+		app.handle(events, http.MethodGet, "/stream",
+			requires(permissions.EventsView).viaTicket(models.TicketRouteEvents).limited("events"),
+			app.Handlers.Events.Stream,
+		)
+	}
+}
+
 func (app *App) Start() {
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
@@ -176,6 +281,30 @@ func (app *App) Start() {
 		return nil
 	})
 
+	// The aggregator forwards what it holds when gCtx is canceled, so the
+	// batcher is only closed after that final flush.
+	g.Go(func() error {
+		app.EventAggregator.Run(gCtx)
+		app.EventBatcher.Close()
+		return nil
+	})
+
+	g.Go(func() error {
+		app.KeySet.Run(gCtx)
+		return nil
+	})
+
+	g.Go(func() error {
+		app.ConfigManager.Run(gCtx)
+		return nil
+	})
+
+	g.Go(func() error {
+		log.Println("Starting Audit Log...")
+		app.AuditService.Run(gCtx)
+		return nil
+	})
+
 	log.Println("Starting Shared Informer Factory...")
 	app.InformerFactory.Start(ctx.Done())
 
@@ -189,14 +318,41 @@ func (app *App) Start() {
 	}
 	log.Println("All caches synced successfully!")
 
+	tlsConfig, certificates, err := newTLSConfig(app.cfg)
+	if err != nil {
+		log.Fatalf("failed to configure TLS: %v", err)
+	}
+
 	srv := &http.Server{
-		Addr:    ":8080",
-		Handler: app.Router,
+		Addr:      app.cfg.ListenAddr,
+		Handler:   app.Router,
+		TLSConfig: tlsConfig,
+	}
+
+	if tlsConfig != nil {
+		// Browsers negotiate HTTP/2 via ALPN and still open WebSockets over a
+		// separate HTTP/1.1 connection, so both protocols stay enabled.
+		srv.Protocols = new(http.Protocols)
+		srv.Protocols.SetHTTP1(true)
+		srv.Protocols.SetHTTP2(true)
+
+		g.Go(func() error {
+			certificates.Run(gCtx, app.cfg.TLSReloadInterval)
+			return nil
+		})
 	}
 
 	g.Go(func() error {
-		log.Println("Starting HTTP Server on :8080")
-		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		var err error
+		if tlsConfig != nil {
+			log.Printf("Starting HTTPS Server on %s", app.cfg.ListenAddr)
+			err = srv.ListenAndServeTLS("", "")
+		} else {
+			log.Printf("Starting HTTP Server on %s", app.cfg.ListenAddr)
+			err = srv.ListenAndServe()
+		}
+
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
 			return fmt.Errorf("http server error: %w", err)
 		}
 		return nil
@@ -0,0 +1,102 @@
+package audit
+
+import (
+	"bufio"
+	"cluster-agent/internal/models"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// FileSink appends entries to a JSON Lines file.
+type FileSink struct {
+	path string
+	mu   sync.Mutex
+	file *os.File
+}
+
+func NewFileSink(path string) (*FileSink, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log %s: %w", path, err)
+	}
+
+	return &FileSink{
+		path: path,
+		file: file,
+	}, nil
+}
+
+func (s *FileSink) Name() string {
+	return SinkFile
+}
+
+func (s *FileSink) Write(_ context.Context, entries []models.AuditEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	writer := bufio.NewWriter(s.file)
+	encoder := json.NewEncoder(writer)
+
+	for _, entry := range entries {
+		if err := encoder.Encode(entry); err != nil {
+			return fmt.Errorf("failed to encode audit entry: %w", err)
+		}
+	}
+
+	return writer.Flush()
+}
+
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.file.Close()
+}
+
+// Query scans the whole file and keeps the newest matching entries.
+func (s *FileSink) Query(ctx context.Context, query models.AuditQuery) ([]models.AuditEntry, error) {
+	file, err := os.Open(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log %s: %w", s.path, err)
+	}
+
+	defer file.Close()
+
+	matches := make([]models.AuditEntry, 0, query.Limit)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		var entry models.AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+
+		if !query.Matches(entry) {
+			continue
+		}
+
+		if len(matches) == query.Limit {
+			matches = matches[1:]
+		}
+		matches = append(matches, entry)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+
+	// Newest first, like the Redis stream.
+	for i, j := 0, len(matches)-1; i < j; i, j = i+1, j-1 {
+		matches[i], matches[j] = matches[j], matches[i]
+	}
+
+	return matches, nil
+}
@@ -0,0 +1,94 @@
+package audit
+
+import (
+	"cluster-agent/internal/auth"
+	"cluster-agent/internal/models"
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var queryEpoch = time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// entryAt returns an entry recorded the given number of minutes after
+// queryEpoch against the namespace.
+func entryAt(minute int, namespace string) models.AuditEntry {
+	return models.AuditEntry{
+		Time:   queryEpoch.Add(time.Duration(minute) * time.Minute),
+		UserId: "42",
+		Action: "pods.exec",
+		Target: models.AuditTarget{Namespace: namespace, Name: "web"},
+	}
+}
+
+// minutes returns when each entry was recorded, relative to queryEpoch.
+func minutes(entries []models.AuditEntry) []int {
+	result := make([]int, 0, len(entries))
+	for _, entry := range entries {
+		result = append(result, int(entry.Time.Sub(queryEpoch)/time.Minute))
+	}
+
+	return result
+}
+
+func newTestFileSink(t *testing.T, entries ...models.AuditEntry) *FileSink {
+	sink, err := NewFileSink(filepath.Join(t.TempDir(), "audit.log"))
+	require.NoError(t, err)
+	t.Cleanup(func() { sink.Close() })
+
+	require.NoError(t, sink.Write(context.Background(), entries))
+
+	return sink
+}
+
+func TestFileSink_Query(t *testing.T) {
+	type testCase struct {
+		name     string
+		entries  []models.AuditEntry
+		query    models.AuditQuery
+		expected []int
+	}
+
+	tests := []testCase{
+		{
+			name:     "Newest first",
+			entries:  []models.AuditEntry{entryAt(0, "payments"), entryAt(1, "payments"), entryAt(2, "payments")},
+			query:    models.AuditQuery{Limit: 10},
+			expected: []int{2, 1, 0},
+		},
+		{
+			name:     "Keeps the newest entries under the limit",
+			entries:  []models.AuditEntry{entryAt(0, "payments"), entryAt(1, "payments"), entryAt(2, "payments"), entryAt(3, "payments")},
+			query:    models.AuditQuery{Limit: 2},
+			expected: []int{3, 2},
+		},
+		{
+			name:     "Skips entries before since",
+			entries:  []models.AuditEntry{entryAt(0, "payments"), entryAt(1, "payments"), entryAt(2, "payments")},
+			query:    models.AuditQuery{Since: queryEpoch.Add(time.Minute), Limit: 10},
+			expected: []int{2, 1},
+		},
+		{
+			name: "Scopes namespaces before the limit",
+			entries: []models.AuditEntry{
+				entryAt(0, "payments"), entryAt(1, "payments"), entryAt(2, "kube-system"), entryAt(3, "kube-system"),
+			},
+			query:    models.AuditQuery{Scope: auth.NewNamespaceScope("payments"), Limit: 2},
+			expected: []int{1, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink := newTestFileSink(t, tt.entries...)
+
+			result, err := sink.Query(context.Background(), tt.query)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, minutes(result))
+		})
+	}
+}
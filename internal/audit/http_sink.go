@@ -0,0 +1,105 @@
+package audit
+
+import (
+	"bytes"
+	"cluster-agent/internal/config"
+	"cluster-agent/internal/events"
+	"cluster-agent/internal/models"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+)
+
+const (
+	httpMaxAttempts = 3
+
+	// httpRetryBackoff doubles after each failed attempt.
+	httpRetryBackoff = time.Second
+)
+
+// errRejected marks responses that resending the same entries cannot change.
+var errRejected = errors.New("backend rejected audit entries")
+
+// HTTPSink pushes entries as a JSON array to the backend. Requests carry the
+// cluster id, auth token and signature configured for event deliveries, read
+// per write so rotated secrets apply without a restart.
+type HTTPSink struct {
+	url        string
+	configs    *config.Manager
+	httpClient *http.Client
+	backoff    time.Duration
+}
+
+func NewHTTPSink(url string, configs *config.Manager) *HTTPSink {
+	return &HTTPSink{
+		url:     url,
+		configs: configs,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+		backoff: httpRetryBackoff,
+	}
+}
+
+func (s *HTTPSink) Name() string {
+	return SinkHTTP
+}
+
+// Write retries failed requests with backoff, so a collector that is briefly
+// down does not lose entries. Every attempt sends the same delivery id.
+func (s *HTTPSink) Write(ctx context.Context, entries []models.AuditEntry) error {
+	payload, err := json.Marshal(entries)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit entries: %w", err)
+	}
+
+	id := events.DeliveryID(payload)
+
+	for attempt := 0; ; attempt++ {
+		err = s.post(ctx, payload, id)
+		if err == nil || errors.Is(err, errRejected) || attempt+1 == httpMaxAttempts {
+			return err
+		}
+
+		log.Printf("Attempt %d/%d to send audit entries failed: %v", attempt+1, httpMaxAttempts, err)
+
+		select {
+		case <-time.After(s.backoff << attempt):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (s *HTTPSink) post(ctx context.Context, payload []byte, deliveryID string) error {
+	req, err := http.NewRequestWithContext(ctx, "POST", s.url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(events.HeaderDeliveryID, deliveryID)
+	events.SetCredentials(req, s.configs.Current(), deliveryID, payload)
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 500:
+		return fmt.Errorf("server error: %d", resp.StatusCode)
+	case resp.StatusCode == http.StatusRequestTimeout, resp.StatusCode == http.StatusTooManyRequests:
+		return fmt.Errorf("backend unavailable: %d", resp.StatusCode)
+	case resp.StatusCode >= 400:
+		return fmt.Errorf("%w: %d", errRejected, resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,124 @@
+package audit
+
+import (
+	"cluster-agent/internal/config"
+	"cluster-agent/internal/events"
+	"cluster-agent/internal/models"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// collector answers requests with the next of statuses, then with 200.
+type collector struct {
+	mu       sync.Mutex
+	statuses []int
+	headers  []http.Header
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.headers = append(c.headers, r.Header.Clone())
+
+	status := http.StatusOK
+	if len(c.statuses) > 0 {
+		status, c.statuses = c.statuses[0], c.statuses[1:]
+	}
+
+	w.WriteHeader(status)
+}
+
+func newTestHTTPSink(t *testing.T, backend *collector) *HTTPSink {
+	server := httptest.NewServer(backend)
+	t.Cleanup(server.Close)
+
+	sink := NewHTTPSink(server.URL, config.NewManager(&config.Config{
+		ClusterID:          "prod",
+		EventAuthHeader:    "Authorization",
+		EventAuthToken:     "token",
+		EventSigningSecret: "0123456789abcdef0123456789abcdef",
+	}))
+	sink.backoff = time.Millisecond
+
+	return sink
+}
+
+func TestHTTPSink_Write(t *testing.T) {
+	type testCase struct {
+		name             string
+		statuses         []int
+		expectedAttempts int
+		expectError      bool
+	}
+
+	tests := []testCase{
+		{
+			name:             "Delivered",
+			expectedAttempts: 1,
+		},
+		{
+			name:             "Retried until the collector is back",
+			statuses:         []int{http.StatusServiceUnavailable, http.StatusTooManyRequests},
+			expectedAttempts: 3,
+		},
+		{
+			name:             "Gives up after the last attempt",
+			statuses:         []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway},
+			expectedAttempts: 3,
+			expectError:      true,
+		},
+		{
+			name:             "Rejected entries are not retried",
+			statuses:         []int{http.StatusBadRequest},
+			expectedAttempts: 1,
+			expectError:      true,
+		},
+	}
+
+	entries := []models.AuditEntry{{UserId: "42", Action: "pods.exec", Status: http.StatusOK}}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			backend := &collector{statuses: tc.statuses}
+			sink := newTestHTTPSink(t, backend)
+
+			err := sink.Write(context.Background(), entries)
+
+			if tc.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			require.Len(t, backend.headers, tc.expectedAttempts)
+			for _, header := range backend.headers {
+				assert.Equal(t, "Bearer token", header.Get("Authorization"))
+				assert.Equal(t, "prod", header.Get(events.HeaderClusterID))
+				assert.NotEmpty(t, header.Get(events.HeaderSignature))
+				assert.Equal(t, backend.headers[0].Get(events.HeaderDeliveryID), header.Get(events.HeaderDeliveryID), "retries keep the delivery id")
+			}
+		})
+	}
+}
+
+func TestHTTPSink_WriteStopsWhenCanceled(t *testing.T) {
+	backend := &collector{statuses: []int{http.StatusServiceUnavailable}}
+	sink := newTestHTTPSink(t, backend)
+	sink.backoff = time.Minute
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err := sink.Write(ctx, []models.AuditEntry{{UserId: "42"}})
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Len(t, backend.headers, 1)
+}
@@ -0,0 +1,103 @@
+package audit
+
+import (
+	"cluster-agent/internal/models"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	streamKey      = "audit:log"
+	streamPageSize = 500
+	// maxScannedEntries bounds how far back a filtered query walks the stream.
+	maxScannedEntries = 10000
+)
+
+// RedisStreamSink stores entries in a capped Redis stream.
+type RedisStreamSink struct {
+	redisClient *redis.Client
+	maxLen      int64
+}
+
+func NewRedisStreamSink(redisClient *redis.Client, maxLen int64) *RedisStreamSink {
+	return &RedisStreamSink{
+		redisClient: redisClient,
+		maxLen:      maxLen,
+	}
+}
+
+func (s *RedisStreamSink) Name() string {
+	return SinkRedis
+}
+
+func (s *RedisStreamSink) Write(ctx context.Context, entries []models.AuditEntry) error {
+	pipe := s.redisClient.Pipeline()
+
+	for _, entry := range entries {
+		payload, err := json.Marshal(entry)
+		if err != nil {
+			return fmt.Errorf("failed to marshal audit entry: %w", err)
+		}
+
+		pipe.XAdd(ctx, &redis.XAddArgs{
+			Stream: streamKey,
+			MaxLen: s.maxLen,
+			Approx: true,
+			Values: map[string]interface{}{"entry": payload},
+		})
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to append audit entries: %w", err)
+	}
+
+	return nil
+}
+
+// Query walks the stream from the newest entry backwards.
+func (s *RedisStreamSink) Query(ctx context.Context, query models.AuditQuery) ([]models.AuditEntry, error) {
+	start := "-"
+	if !query.Since.IsZero() {
+		start = strconv.FormatInt(query.Since.UnixMilli(), 10)
+	}
+
+	matches := make([]models.AuditEntry, 0, query.Limit)
+	end := "+"
+
+	for scanned := 0; scanned < maxScannedEntries; {
+		messages, err := s.redisClient.XRevRangeN(ctx, streamKey, end, start, streamPageSize).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read audit stream: %w", err)
+		}
+
+		for _, message := range messages {
+			payload, _ := message.Values["entry"].(string)
+
+			var entry models.AuditEntry
+			if err := json.Unmarshal([]byte(payload), &entry); err != nil {
+				continue
+			}
+			entry.Id = message.ID
+
+			if query.Matches(entry) {
+				matches = append(matches, entry)
+				if len(matches) == query.Limit {
+					return matches, nil
+				}
+			}
+		}
+
+		if len(messages) < streamPageSize {
+			break
+		}
+
+		scanned += len(messages)
+		end = "(" + messages[len(messages)-1].ID
+	}
+
+	return matches, nil
+}
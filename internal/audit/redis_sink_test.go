@@ -0,0 +1,185 @@
+package audit
+
+import (
+	"cluster-agent/internal/auth"
+	"cluster-agent/internal/models"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// streamHook answers XREVRANGE from an in-memory stream, oldest message
+// first, so the client never connects.
+type streamHook struct {
+	messages []redis.XMessage
+	reads    int
+}
+
+func (h *streamHook) DialHook(redis.DialHook) redis.DialHook {
+	return func(context.Context, string, string) (net.Conn, error) {
+		return nil, errors.New("unexpected dial")
+	}
+}
+
+func (h *streamHook) ProcessHook(redis.ProcessHook) redis.ProcessHook {
+	return func(_ context.Context, cmd redis.Cmder) error {
+		c, ok := cmd.(*redis.XMessageSliceCmd)
+		if !ok || cmd.Name() != "xrevrange" {
+			err := fmt.Errorf("unexpected command %s", cmd.Name())
+			cmd.SetErr(err)
+			return err
+		}
+
+		args := cmd.Args()
+		end, start := fmt.Sprint(args[2]), fmt.Sprint(args[3])
+		count, _ := strconv.Atoi(fmt.Sprint(args[5]))
+		h.reads++
+
+		var page []redis.XMessage
+		for i := len(h.messages) - 1; i >= 0 && len(page) < count; i-- {
+			id := h.messages[i].ID
+			if beforeEnd(id, end) && !beforeStart(id, start) {
+				page = append(page, h.messages[i])
+			}
+		}
+
+		c.SetVal(page)
+		return nil
+	}
+}
+
+func (h *streamHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return next
+}
+
+// streamID orders IDs of the form "<ms>-<seq>"; a bare "<ms>" is "<ms>-0".
+func streamID(id string) [2]int64 {
+	ms, seq, _ := strings.Cut(id, "-")
+	var parsed [2]int64
+	parsed[0], _ = strconv.ParseInt(ms, 10, 64)
+	parsed[1], _ = strconv.ParseInt(seq, 10, 64)
+	return parsed
+}
+
+func less(a, b [2]int64) bool {
+	return a[0] < b[0] || a[0] == b[0] && a[1] < b[1]
+}
+
+func beforeEnd(id, end string) bool {
+	switch {
+	case end == "+":
+		return true
+	case strings.HasPrefix(end, "("):
+		return less(streamID(id), streamID(end[1:]))
+	default:
+		return !less(streamID(end), streamID(id))
+	}
+}
+
+func beforeStart(id, start string) bool {
+	return start != "-" && less(streamID(id), streamID(start))
+}
+
+func newTestRedisStreamSink(t *testing.T, entries ...models.AuditEntry) (*RedisStreamSink, *streamHook) {
+	hook := &streamHook{}
+	for i, entry := range entries {
+		payload, err := json.Marshal(entry)
+		require.NoError(t, err)
+
+		hook.messages = append(hook.messages, redis.XMessage{
+			ID:     fmt.Sprintf("%d-%d", entry.Time.UnixMilli(), i),
+			Values: map[string]interface{}{"entry": string(payload)},
+		})
+	}
+
+	client := redis.NewClient(&redis.Options{Addr: "redis.invalid:6379"})
+	client.AddHook(hook)
+	t.Cleanup(func() { client.Close() })
+
+	return NewRedisStreamSink(client, 1000), hook
+}
+
+func TestRedisStreamSink_Query(t *testing.T) {
+	type testCase struct {
+		name     string
+		entries  []models.AuditEntry
+		query    models.AuditQuery
+		expected []int
+	}
+
+	tests := []testCase{
+		{
+			name:     "Newest first",
+			entries:  []models.AuditEntry{entryAt(0, "payments"), entryAt(1, "payments"), entryAt(2, "payments")},
+			query:    models.AuditQuery{Limit: 10},
+			expected: []int{2, 1, 0},
+		},
+		{
+			name:     "Keeps the newest entries under the limit",
+			entries:  []models.AuditEntry{entryAt(0, "payments"), entryAt(1, "payments"), entryAt(2, "payments"), entryAt(3, "payments")},
+			query:    models.AuditQuery{Limit: 2},
+			expected: []int{3, 2},
+		},
+		{
+			name:     "Skips entries before since",
+			entries:  []models.AuditEntry{entryAt(0, "payments"), entryAt(1, "payments"), entryAt(2, "payments")},
+			query:    models.AuditQuery{Since: queryEpoch.Add(time.Minute), Limit: 10},
+			expected: []int{2, 1},
+		},
+		{
+			name: "Scopes namespaces before the limit",
+			entries: []models.AuditEntry{
+				entryAt(0, "payments"), entryAt(1, "payments"), entryAt(2, "kube-system"), entryAt(3, "kube-system"),
+			},
+			query:    models.AuditQuery{Scope: auth.NewNamespaceScope("payments"), Limit: 2},
+			expected: []int{1, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink, _ := newTestRedisStreamSink(t, tt.entries...)
+
+			result, err := sink.Query(context.Background(), tt.query)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, minutes(result))
+		})
+	}
+
+	t.Run("Pages past the first read", func(t *testing.T) {
+		// Every other entry is in scope, so filling the limit takes three pages.
+		entries := make([]models.AuditEntry, 0, 2*streamPageSize+200)
+		for i := 0; i < cap(entries); i++ {
+			namespace := "payments"
+			if i%2 == 1 {
+				namespace = "kube-system"
+			}
+			entries = append(entries, entryAt(i, namespace))
+		}
+		sink, hook := newTestRedisStreamSink(t, entries...)
+
+		result, err := sink.Query(context.Background(), models.AuditQuery{
+			Scope: auth.NewNamespaceScope("payments"),
+			Limit: streamPageSize + 50,
+		})
+		require.NoError(t, err)
+
+		require.Len(t, result, streamPageSize+50)
+		assert.Equal(t, 3, hook.reads)
+
+		got := minutes(result)
+		for i := range got {
+			assert.Equal(t, len(entries)-2-2*i, got[i], "entry %d", i)
+		}
+	})
+}
@@ -0,0 +1,55 @@
+package audit
+
+import (
+	"cluster-agent/internal/config"
+	"cluster-agent/internal/services"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	SinkFile  = "file"
+	SinkRedis = "redis"
+	SinkHTTP  = "http"
+)
+
+// NewSinks builds the audit sinks listed in the config, in order. The first
+// sink that supports queries backs the audit query endpoint.
+func NewSinks(configs *config.Manager, redisClient *redis.Client) ([]services.AuditSink, func(), error) {
+	cfg := configs.Current()
+	sinks := make([]services.AuditSink, 0, len(cfg.AuditSinks))
+	var files []*FileSink
+
+	cleanup := func() {
+		for _, file := range files {
+			file.Close()
+		}
+	}
+
+	for _, name := range cfg.AuditSinks {
+		switch name {
+		case SinkFile:
+			sink, err := NewFileSink(cfg.AuditFilePath)
+			if err != nil {
+				cleanup()
+				return nil, nil, err
+			}
+			files = append(files, sink)
+			sinks = append(sinks, sink)
+		case SinkRedis:
+			sinks = append(sinks, NewRedisStreamSink(redisClient, cfg.AuditStreamMaxLen))
+		case SinkHTTP:
+			if cfg.AuditHTTPURL == "" {
+				cleanup()
+				return nil, nil, fmt.Errorf("audit sink %q requires AUDIT_HTTP_URL", name)
+			}
+			sinks = append(sinks, NewHTTPSink(cfg.AuditHTTPURL, configs))
+		default:
+			cleanup()
+			return nil, nil, fmt.Errorf("unknown audit sink %q", name)
+		}
+	}
+
+	return sinks, cleanup, nil
+}
@@ -2,7 +2,6 @@ package auth
 
 import (
 	"cluster-agent/internal/auth/permissions"
-	"crypto/rsa"
 	"fmt"
 	"github.com/golang-jwt/jwt/v5"
 )
@@ -11,18 +10,32 @@ type UserClaims struct {
 	jwt.RegisteredClaims
 	UserId      string                   `json:"sub"`
 	Permissions []permissions.Permission `json:"permissions"`
+	Namespaces  []string                 `json:"namespaces,omitempty"`
+	Roles       []string                 `json:"roles,omitempty"`
+	Groups      []string                 `json:"groups,omitempty"`
 }
 
-func ParseToken(token string, claims *UserClaims, pubKey *rsa.PublicKey) (*jwt.Token, error) {
+// ApplyRoles adds the permissions bundled in the claimed roles. Roles unknown
+// to the agent grant nothing.
+func (c *UserClaims) ApplyRoles(roles map[string][]permissions.Permission) {
+	for _, role := range c.Roles {
+		c.Permissions = append(c.Permissions, roles[role]...)
+	}
+}
+
+func ParseToken(token string, claims *UserClaims, keys KeySet, validation ValidationOptions) (*jwt.Token, error) {
 	if token == "" {
 		return nil, fmt.Errorf("token is empty")
 	}
 
-	return jwt.ParseWithClaims(token, claims, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
+	parsed, err := jwt.ParseWithClaims(token, claims, keyFunc(keys), validation.parserOptions()...)
+	if err != nil {
+		return parsed, err
+	}
+
+	if validation.RequireNotBefore && claims.NotBefore == nil {
+		return parsed, fmt.Errorf("%w: nbf", jwt.ErrTokenRequiredClaimMissing)
+	}
 
-		return pubKey, nil
-	})
+	return parsed, nil
 }
@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"cluster-agent/internal/config"
+	"crypto/x509"
+	"slices"
+)
+
+// ClaimsFromCertificate builds claims for a verified client certificate from
+// the first configured identity it matches.
+func ClaimsFromCertificate(cert *x509.Certificate, identities []config.ClientIdentity) (*UserClaims, bool) {
+	for _, identity := range identities {
+		if !certificateMatches(cert, identity) {
+			continue
+		}
+
+		return &UserClaims{
+			UserId:      identity.UserId,
+			Permissions: slices.Clone(identity.Permissions),
+			Namespaces:  identity.Namespaces,
+			Roles:       identity.Roles,
+			Groups:      identity.Groups,
+		}, true
+	}
+
+	return nil, false
+}
+
+func certificateMatches(cert *x509.Certificate, identity config.ClientIdentity) bool {
+	if identity.CommonName != "" && identity.CommonName == cert.Subject.CommonName {
+		return true
+	}
+
+	if identity.DNSName != "" && slices.Contains(cert.DNSNames, identity.DNSName) {
+		return true
+	}
+
+	if identity.URI != "" {
+		for _, uri := range cert.URIs {
+			if uri.String() == identity.URI {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,120 @@
+package auth
+
+import (
+	"cluster-agent/internal/auth/permissions"
+	"cluster-agent/internal/config"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClaimsFromCertificate(t *testing.T) {
+	type testCase struct {
+		name       string
+		cert       *x509.Certificate
+		identities []config.ClientIdentity
+		expectedId string
+	}
+
+	spiffe, _ := url.Parse("spiffe://cluster.local/ns/ci/sa/runner")
+	cert := &x509.Certificate{
+		Subject:  pkix.Name{CommonName: "ci-runner"},
+		DNSNames: []string{"runner.ci.svc", "runner.ci.svc.cluster.local"},
+		URIs:     []*url.URL{spiffe},
+	}
+
+	tests := []testCase{
+		{
+			name:       "Common name",
+			cert:       cert,
+			identities: []config.ClientIdentity{{CommonName: "ci-runner", UserId: "svc:ci"}},
+			expectedId: "svc:ci",
+		},
+		{
+			name:       "Any DNS name",
+			cert:       cert,
+			identities: []config.ClientIdentity{{DNSName: "runner.ci.svc.cluster.local", UserId: "svc:ci"}},
+			expectedId: "svc:ci",
+		},
+		{
+			name:       "URI",
+			cert:       cert,
+			identities: []config.ClientIdentity{{URI: "spiffe://cluster.local/ns/ci/sa/runner", UserId: "svc:ci"}},
+			expectedId: "svc:ci",
+		},
+		{
+			name: "First matching identity wins",
+			cert: cert,
+			identities: []config.ClientIdentity{
+				{CommonName: "deployer", UserId: "svc:deployer"},
+				{DNSName: "runner.ci.svc", UserId: "svc:ci"},
+				{CommonName: "ci-runner", UserId: "svc:ci-fallback"},
+			},
+			expectedId: "svc:ci",
+		},
+		{
+			name:       "Common name is matched exactly",
+			cert:       cert,
+			identities: []config.ClientIdentity{{CommonName: "CI-Runner", UserId: "svc:ci"}, {CommonName: "ci", UserId: "svc:ci"}},
+		},
+		{
+			name:       "DNS name is matched exactly",
+			cert:       cert,
+			identities: []config.ClientIdentity{{DNSName: "ci.svc", UserId: "svc:ci"}},
+		},
+		{
+			name:       "URI is matched exactly",
+			cert:       cert,
+			identities: []config.ClientIdentity{{URI: "spiffe://cluster.local/ns/ci", UserId: "svc:ci"}},
+		},
+		{
+			name:       "Identity without selectors matches nothing",
+			cert:       &x509.Certificate{},
+			identities: []config.ClientIdentity{{UserId: "svc:anyone"}},
+		},
+		{
+			name: "No identities",
+			cert: cert,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, ok := ClaimsFromCertificate(tt.cert, tt.identities)
+
+			if tt.expectedId == "" {
+				assert.False(t, ok)
+				assert.Nil(t, claims)
+				return
+			}
+
+			assert.True(t, ok)
+			assert.Equal(t, tt.expectedId, claims.UserId)
+		})
+	}
+}
+
+func TestClaimsFromCertificate_CopiesGrants(t *testing.T) {
+	identities := []config.ClientIdentity{{
+		CommonName:  "ci-runner",
+		UserId:      "svc:ci",
+		Permissions: []permissions.Permission{permissions.PodsView},
+		Namespaces:  []string{"ci"},
+		Roles:       []string{"viewer"},
+		Groups:      []string{"ci-runners"},
+	}}
+
+	claims, ok := ClaimsFromCertificate(&x509.Certificate{Subject: pkix.Name{CommonName: "ci-runner"}}, identities)
+	assert.True(t, ok)
+	assert.Equal(t, []permissions.Permission{permissions.PodsView}, claims.Permissions)
+	assert.Equal(t, []string{"ci"}, claims.Namespaces)
+	assert.Equal(t, []string{"viewer"}, claims.Roles)
+	assert.Equal(t, []string{"ci-runners"}, claims.Groups)
+
+	// Roles are applied to the claims later, which must not grow the config.
+	claims.ApplyRoles(map[string][]permissions.Permission{"viewer": {permissions.DeploymentsView}})
+	assert.Equal(t, []permissions.Permission{permissions.PodsView}, identities[0].Permissions)
+}
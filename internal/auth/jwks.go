@@ -0,0 +1,349 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	// minRefreshInterval bounds how often an unknown kid may force a refetch,
+	// so tokens with garbage kids cannot hammer the JWKS endpoint.
+	minRefreshInterval = 30 * time.Second
+	fetchTimeout       = 10 * time.Second
+)
+
+type jwksFetcher func(ctx context.Context) ([]byte, error)
+
+// JWKSKeySet holds the keys of a JSON Web Key Set and refreshes them in the
+// background, so signing keys can be rotated without restarting the agent.
+type JWKSKeySet struct {
+	fetch    jwksFetcher
+	interval time.Duration
+
+	mu          sync.RWMutex
+	keys        *webKeys
+	lastRefresh time.Time
+	refreshMu   sync.Mutex
+}
+
+func NewJWKSKeySet(fetch jwksFetcher, interval time.Duration) (*JWKSKeySet, error) {
+	s := &JWKSKeySet{
+		fetch:    fetch,
+		interval: interval,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+	defer cancel()
+
+	if err := s.refresh(ctx); err != nil {
+		return nil, fmt.Errorf("failed to load JWKS: %w", err)
+	}
+
+	return s, nil
+}
+
+func (s *JWKSKeySet) Keys(kid string) ([]*VerificationKey, error) {
+	if keys := s.lookup(kid); len(keys) > 0 {
+		return keys, nil
+	}
+
+	// The issuer may have rotated to a key we have not seen yet.
+	s.refreshMu.Lock()
+	if time.Since(s.lastRefreshTime()) >= minRefreshInterval {
+		ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+		if err := s.refresh(ctx); err != nil {
+			log.Printf("Failed to refresh JWKS: %v", err)
+		}
+		cancel()
+	}
+	s.refreshMu.Unlock()
+
+	if keys := s.lookup(kid); len(keys) > 0 {
+		return keys, nil
+	}
+
+	return nil, fmt.Errorf("%w: %q", ErrUnknownKey, kid)
+}
+
+func (s *JWKSKeySet) Run(ctx context.Context) {
+	if s.interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.refreshMu.Lock()
+			fetchCtx, cancel := context.WithTimeout(ctx, fetchTimeout)
+			if err := s.refresh(fetchCtx); err != nil {
+				log.Printf("Failed to refresh JWKS: %v", err)
+			}
+			cancel()
+			s.refreshMu.Unlock()
+
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// lookup matches tokens without a kid against the keys without one, or the
+// only key in the set when every key has a kid.
+func (s *JWKSKeySet) lookup(kid string) []*VerificationKey {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if kid != "" {
+		if key, ok := s.keys.byKid[kid]; ok {
+			return []*VerificationKey{key}
+		}
+		return nil
+	}
+
+	if len(s.keys.unnamed) > 0 {
+		return s.keys.unnamed
+	}
+
+	if len(s.keys.byKid) == 1 {
+		for _, key := range s.keys.byKid {
+			return []*VerificationKey{key}
+		}
+	}
+
+	return nil
+}
+
+func (s *JWKSKeySet) lastRefreshTime() time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.lastRefresh
+}
+
+func (s *JWKSKeySet) refresh(ctx context.Context) error {
+	s.mu.Lock()
+	s.lastRefresh = time.Now()
+	s.mu.Unlock()
+
+	data, err := s.fetch(ctx)
+	if err != nil {
+		return err
+	}
+
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.keys = keys
+	s.mu.Unlock()
+
+	return nil
+}
+
+// webKeys are the usable keys of a JWKS document. Keys without a kid cannot
+// share a map entry, so they are kept apart.
+type webKeys struct {
+	byKid   map[string]*VerificationKey
+	unnamed []*VerificationKey
+}
+
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+
+	// RSA
+	N string `json:"n"`
+	E string `json:"e"`
+
+	// EC and OKP
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func parseJWKS(data []byte) (*webKeys, error) {
+	var set jsonWebKeySet
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("invalid JWKS document: %w", err)
+	}
+
+	keys := &webKeys{
+		byKid: make(map[string]*VerificationKey, len(set.Keys)),
+	}
+
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+
+		key, err := jwk.verificationKey()
+		if err != nil {
+			log.Printf("Skipping JWKS key %q: %v", jwk.Kid, err)
+			continue
+		}
+
+		if jwk.Kid == "" {
+			keys.unnamed = append(keys.unnamed, key)
+		} else {
+			keys.byKid[jwk.Kid] = key
+		}
+	}
+
+	if len(keys.byKid) == 0 && len(keys.unnamed) == 0 {
+		return nil, fmt.Errorf("JWKS document contains no usable signing keys")
+	}
+
+	return keys, nil
+}
+
+func (k jsonWebKey) verificationKey() (*VerificationKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid modulus: %w", err)
+		}
+
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exponent: %w", err)
+		}
+
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("exponent out of range")
+		}
+
+		return &VerificationKey{
+			Key:       &rsa.PublicKey{N: n, E: int(e.Int64())},
+			Algorithm: k.Alg,
+		}, nil
+
+	case "EC":
+		curve, err := ellipticCurve(k.Crv)
+		if err != nil {
+			return nil, err
+		}
+
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("invalid x coordinate: %w", err)
+		}
+
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("invalid y coordinate: %w", err)
+		}
+
+		size := (curve.Params().BitSize + 7) / 8
+		if len(x) > size || len(y) > size {
+			return nil, fmt.Errorf("coordinates too long for curve %s", k.Crv)
+		}
+
+		point := make([]byte, 1+2*size)
+		point[0] = 4
+		copy(point[1+size-len(x):1+size], x)
+		copy(point[1+2*size-len(y):], y)
+
+		pubKey, err := ecdsa.ParseUncompressedPublicKey(curve, point)
+		if err != nil {
+			return nil, err
+		}
+
+		return &VerificationKey{Key: pubKey, Algorithm: k.Alg}, nil
+
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported OKP curve %q", k.Crv)
+		}
+
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("invalid public key: %w", err)
+		}
+
+		if len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid Ed25519 key size %d", len(x))
+		}
+
+		return &VerificationKey{Key: ed25519.PublicKey(x), Algorithm: k.Alg}, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func ellipticCurve(crv string) (elliptic.Curve, error) {
+	switch crv {
+	case "P-256":
+		return elliptic.P256(), nil
+	case "P-384":
+		return elliptic.P384(), nil
+	case "P-521":
+		return elliptic.P521(), nil
+	default:
+		return nil, fmt.Errorf("unsupported EC curve %q", crv)
+	}
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).SetBytes(data), nil
+}
+
+func httpJWKSFetcher(url string) jwksFetcher {
+	client := &http.Client{Timeout: fetchTimeout}
+
+	return func(ctx context.Context) ([]byte, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("unexpected JWKS response status: %d", resp.StatusCode)
+		}
+
+		return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	}
+}
+
+func fileJWKSFetcher(path string) jwksFetcher {
+	return func(context.Context) ([]byte, error) {
+		return os.ReadFile(path)
+	}
+}
@@ -0,0 +1,303 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testKeys struct {
+	rsa     *rsa.PrivateKey
+	ec      *ecdsa.PrivateKey
+	ed25519 ed25519.PrivateKey
+}
+
+func generateTestKeys(t *testing.T) testKeys {
+	t.Helper()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	return testKeys{rsa: rsaKey, ec: ecKey, ed25519: edKey}
+}
+
+func encode(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func rsaJWK(kid string, key *rsa.PublicKey) jsonWebKey {
+	return jsonWebKey{
+		Kty: "RSA",
+		Kid: kid,
+		N:   encode(key.N.Bytes()),
+		E:   encode(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func ecJWK(t *testing.T, kid string, key *ecdsa.PublicKey) jsonWebKey {
+	t.Helper()
+
+	point, err := key.Bytes()
+	require.NoError(t, err)
+
+	size := (len(point) - 1) / 2
+	return jsonWebKey{
+		Kty: "EC",
+		Kid: kid,
+		Crv: key.Curve.Params().Name,
+		X:   encode(point[1 : 1+size]),
+		Y:   encode(point[1+size:]),
+	}
+}
+
+func okpJWK(kid string, key ed25519.PublicKey) jsonWebKey {
+	return jsonWebKey{
+		Kty: "OKP",
+		Kid: kid,
+		Crv: "Ed25519",
+		X:   encode(key),
+	}
+}
+
+func jwksDocument(t *testing.T, keys ...jsonWebKey) []byte {
+	t.Helper()
+
+	data, err := json.Marshal(jsonWebKeySet{Keys: keys})
+	require.NoError(t, err)
+	return data
+}
+
+func TestParseJWKS(t *testing.T) {
+	type testCase struct {
+		name          string
+		document      func(t *testing.T, keys testKeys) []byte
+		verify        func(t *testing.T, keys testKeys, parsed map[string]*VerificationKey)
+		expectedError string
+	}
+
+	keys := generateTestKeys(t)
+
+	tests := []testCase{
+		{
+			name: "RSA key",
+			document: func(t *testing.T, keys testKeys) []byte {
+				jwk := rsaJWK("rsa-1", &keys.rsa.PublicKey)
+				jwk.Alg = "RS256"
+				return jwksDocument(t, jwk)
+			},
+			verify: func(t *testing.T, keys testKeys, parsed map[string]*VerificationKey) {
+				require.Contains(t, parsed, "rsa-1")
+				assert.True(t, keys.rsa.PublicKey.Equal(parsed["rsa-1"].Key))
+				assert.Equal(t, "RS256", parsed["rsa-1"].Algorithm)
+			},
+		},
+		{
+			name: "EC key",
+			document: func(t *testing.T, keys testKeys) []byte {
+				return jwksDocument(t, ecJWK(t, "ec-1", &keys.ec.PublicKey))
+			},
+			verify: func(t *testing.T, keys testKeys, parsed map[string]*VerificationKey) {
+				require.Contains(t, parsed, "ec-1")
+				assert.True(t, keys.ec.PublicKey.Equal(parsed["ec-1"].Key))
+				assert.Empty(t, parsed["ec-1"].Algorithm)
+			},
+		},
+		{
+			name: "OKP key",
+			document: func(t *testing.T, keys testKeys) []byte {
+				return jwksDocument(t, okpJWK("ed-1", keys.ed25519.Public().(ed25519.PublicKey)))
+			},
+			verify: func(t *testing.T, keys testKeys, parsed map[string]*VerificationKey) {
+				require.Contains(t, parsed, "ed-1")
+				assert.True(t, keys.ed25519.Public().(ed25519.PublicKey).Equal(parsed["ed-1"].Key))
+			},
+		},
+		{
+			name: "Mixed set skips encryption and unsupported keys",
+			document: func(t *testing.T, keys testKeys) []byte {
+				encryption := rsaJWK("enc-1", &keys.rsa.PublicKey)
+				encryption.Use = "enc"
+				signing := okpJWK("ed-1", keys.ed25519.Public().(ed25519.PublicKey))
+				signing.Use = "sig"
+				return jwksDocument(t,
+					encryption,
+					jsonWebKey{Kty: "oct", Kid: "hmac-1"},
+					jsonWebKey{Kty: "OKP", Kid: "x-1", Crv: "X25519", X: encode(make([]byte, 32))},
+					jsonWebKey{Kty: "EC", Kid: "ec-bad", Crv: "P-256", X: encode(make([]byte, 33)), Y: encode(make([]byte, 32))},
+					signing,
+				)
+			},
+			verify: func(t *testing.T, keys testKeys, parsed map[string]*VerificationKey) {
+				assert.Len(t, parsed, 1)
+				assert.Contains(t, parsed, "ed-1")
+			},
+		},
+		{
+			name: "EC point not on the curve",
+			document: func(t *testing.T, keys testKeys) []byte {
+				return jwksDocument(t, jsonWebKey{Kty: "EC", Kid: "ec-1", Crv: "P-256", X: encode([]byte{1}), Y: encode([]byte{1})})
+			},
+			expectedError: "no usable signing keys",
+		},
+		{
+			name: "OKP key of the wrong size",
+			document: func(t *testing.T, keys testKeys) []byte {
+				return jwksDocument(t, jsonWebKey{Kty: "OKP", Kid: "ed-1", Crv: "Ed25519", X: encode(make([]byte, 31))})
+			},
+			expectedError: "no usable signing keys",
+		},
+		{
+			name: "RSA exponent out of range",
+			document: func(t *testing.T, keys testKeys) []byte {
+				jwk := rsaJWK("rsa-1", &keys.rsa.PublicKey)
+				jwk.E = encode(append([]byte{1}, make([]byte, 8)...))
+				return jwksDocument(t, jwk)
+			},
+			expectedError: "no usable signing keys",
+		},
+		{
+			name: "Empty set",
+			document: func(t *testing.T, keys testKeys) []byte {
+				return []byte(`{"keys": []}`)
+			},
+			expectedError: "no usable signing keys",
+		},
+		{
+			name: "Invalid JSON",
+			document: func(t *testing.T, keys testKeys) []byte {
+				return []byte(`{"keys": `)
+			},
+			expectedError: "invalid JWKS document",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed, err := parseJWKS(tt.document(t, keys))
+
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			tt.verify(t, keys, parsed.byKid)
+		})
+	}
+}
+
+func TestJWKSKeySet_Keys(t *testing.T) {
+	keys := generateTestKeys(t)
+	first := jwksDocument(t, rsaJWK("rsa-1", &keys.rsa.PublicKey))
+	rotated := jwksDocument(t, rsaJWK("rsa-1", &keys.rsa.PublicKey), ecJWK(t, "ec-1", &keys.ec.PublicKey))
+
+	var document atomic.Pointer[[]byte]
+	document.Store(&first)
+	var fetches atomic.Int32
+	fetch := func(context.Context) ([]byte, error) {
+		fetches.Add(1)
+		return *document.Load(), nil
+	}
+
+	keySet, err := NewJWKSKeySet(fetch, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), fetches.Load())
+
+	t.Run("Known kid", func(t *testing.T) {
+		found, err := keySet.Keys("rsa-1")
+		require.NoError(t, err)
+		require.Len(t, found, 1)
+		assert.True(t, keys.rsa.PublicKey.Equal(found[0].Key))
+	})
+
+	t.Run("Token without kid uses the only key", func(t *testing.T) {
+		found, err := keySet.Keys("")
+		require.NoError(t, err)
+		require.Len(t, found, 1)
+		assert.True(t, keys.rsa.PublicKey.Equal(found[0].Key))
+	})
+
+	t.Run("Unknown kid within the refresh interval does not refetch", func(t *testing.T) {
+		document.Store(&rotated)
+
+		_, err := keySet.Keys("ec-1")
+		assert.ErrorIs(t, err, ErrUnknownKey)
+		assert.Equal(t, int32(1), fetches.Load())
+	})
+
+	t.Run("Unknown kid refetches a rotated set", func(t *testing.T) {
+		keySet.mu.Lock()
+		keySet.lastRefresh = time.Now().Add(-minRefreshInterval)
+		keySet.mu.Unlock()
+
+		found, err := keySet.Keys("ec-1")
+		require.NoError(t, err)
+		require.Len(t, found, 1)
+		assert.True(t, keys.ec.PublicKey.Equal(found[0].Key))
+		assert.Equal(t, int32(2), fetches.Load())
+	})
+
+	t.Run("Token without kid is ambiguous with several keys", func(t *testing.T) {
+		_, err := keySet.Keys("")
+		assert.ErrorIs(t, err, ErrUnknownKey)
+	})
+}
+
+func TestJWKSKeySet_KeysWithoutKid(t *testing.T) {
+	keys := generateTestKeys(t)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	document := jwksDocument(t,
+		rsaJWK("", &keys.rsa.PublicKey),
+		rsaJWK("", &otherKey.PublicKey),
+		ecJWK(t, "ec-1", &keys.ec.PublicKey),
+	)
+	keySet, err := NewJWKSKeySet(func(context.Context) ([]byte, error) { return document, nil }, 0)
+	require.NoError(t, err)
+
+	found, err := keySet.Keys("")
+	require.NoError(t, err)
+	require.Len(t, found, 2, "keys without a kid do not replace each other")
+
+	options := ValidationOptions{RequireExpiration: true}
+	claims := &UserClaims{
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))},
+		UserId:           "42",
+	}
+
+	for name, key := range map[string]*rsa.PrivateKey{"first": keys.rsa, "second": otherKey} {
+		t.Run("Token signed by the "+name+" key", func(t *testing.T) {
+			token, err := ParseToken(signToken(t, jwt.SigningMethodRS256, "", key, claims), &UserClaims{}, keySet, options)
+			require.NoError(t, err)
+			assert.True(t, token.Valid)
+		})
+	}
+
+	t.Run("Token signed by another key", func(t *testing.T) {
+		unknown, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+
+		_, err = ParseToken(signToken(t, jwt.SigningMethodRS256, "", unknown, claims), &UserClaims{}, keySet, options)
+		assert.Error(t, err)
+	})
+}
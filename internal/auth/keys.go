@@ -0,0 +1,137 @@
+package auth
+
+import (
+	"cluster-agent/internal/config"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrUnknownKey = errors.New("unknown signing key")
+)
+
+// KeySet resolves the public keys a token may have been signed with from its
+// kid header. Tokens without a kid can match several keys, which are tried in
+// turn.
+type KeySet interface {
+	Keys(kid string) ([]*VerificationKey, error)
+	// Run keeps the key set up to date until ctx is canceled.
+	Run(ctx context.Context)
+}
+
+type VerificationKey struct {
+	Key crypto.PublicKey
+	// Algorithm pins the key to a single signing algorithm when the key
+	// source declares one.
+	Algorithm string
+}
+
+// NewKeySet prefers a JWKS source when one is configured and falls back to the
+// single PEM key otherwise. The PEM key follows configuration reloads.
+func NewKeySet(configs *config.Manager) (KeySet, error) {
+	var fetch jwksFetcher
+
+	cfg := configs.Current()
+
+	switch {
+	case cfg.JWKSURL != "":
+		fetch = httpJWKSFetcher(cfg.JWKSURL)
+	case cfg.JWKSPath != "":
+		fetch = fileJWKSFetcher(cfg.JWKSPath)
+	case cfg.JWTPublicKey != nil:
+		return NewConfigKeySet(configs), nil
+	default:
+		return nil, fmt.Errorf("no token verification keys configured")
+	}
+
+	keySet, err := NewJWKSKeySet(fetch, cfg.JWKSRefreshInterval)
+	if err != nil {
+		return nil, err
+	}
+
+	return keySet, nil
+}
+
+// ConfigKeySet verifies every token with the PEM key of the active
+// configuration, so a reloaded key applies to the next request.
+type ConfigKeySet struct {
+	configs *config.Manager
+}
+
+func NewConfigKeySet(configs *config.Manager) *ConfigKeySet {
+	return &ConfigKeySet{
+		configs: configs,
+	}
+}
+
+func (s *ConfigKeySet) Keys(string) ([]*VerificationKey, error) {
+	key := s.configs.Current().JWTPublicKey
+	if key == nil {
+		return nil, ErrUnknownKey
+	}
+
+	return []*VerificationKey{{Key: key}}, nil
+}
+
+func (s *ConfigKeySet) Run(context.Context) {}
+
+var supportedMethods = []string{
+	"RS256", "RS384", "RS512",
+	"PS256", "PS384", "PS512",
+	"ES256", "ES384", "ES512",
+	"EdDSA",
+}
+
+func keyFunc(keys KeySet) jwt.Keyfunc {
+	return func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+
+		candidates, err := keys.Keys(kid)
+		if err != nil {
+			return nil, err
+		}
+
+		var matching jwt.VerificationKeySet
+		for _, key := range candidates {
+			if key.Algorithm != "" && key.Algorithm != token.Method.Alg() {
+				continue
+			}
+
+			if methodMatchesKey(token.Method, key.Key) {
+				matching.Keys = append(matching.Keys, key.Key)
+			}
+		}
+
+		switch len(matching.Keys) {
+		case 0:
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		case 1:
+			return matching.Keys[0], nil
+		default:
+			return matching, nil
+		}
+	}
+}
+
+func methodMatchesKey(method jwt.SigningMethod, key crypto.PublicKey) bool {
+	switch method.(type) {
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS:
+		_, ok := key.(*rsa.PublicKey)
+		return ok
+	case *jwt.SigningMethodECDSA:
+		_, ok := key.(*ecdsa.PublicKey)
+		return ok
+	case *jwt.SigningMethodEd25519:
+		_, ok := key.(ed25519.PublicKey)
+		return ok
+	default:
+		return false
+	}
+}
@@ -1,5 +1,7 @@
 package permissions
 
+import "strings"
+
 type Permission string
 
 func (p Permission) String() string {
@@ -11,7 +13,9 @@ const (
 	NodesView Permission = "nodes:view"
 
 	// Pods
-	PodsView Permission = "pods:view"
+	PodsView    Permission = "pods:view"
+	PodsExec    Permission = "pods:exec"
+	PodsNetwork Permission = "pods:network"
 
 	// Deployments
 	DeploymentsView   Permission = "deployments:view"
@@ -35,8 +39,103 @@ const (
 	ConfigMapsView Permission = "configmaps:view"
 
 	// Secrets
-	SecretsView Permission = "secrets:view"
+	SecretsView   Permission = "secrets:view"
+	SecretsReveal Permission = "secrets:reveal"
 
 	// PersistentVolumeClaims
 	PVCsView Permission = "pvcs:view"
+
+	// Tokens
+	TokensRevoke Permission = "tokens:revoke"
+
+	// Audit
+	AuditView Permission = "audit:view"
+
+	// API keys
+	APIKeysManage Permission = "apikeys:manage"
+
+	// Agent configuration
+	ConfigView Permission = "config:view"
+)
+
+// All lists every permission the agent checks. Keep it in sync with the
+// constants above, the matcher tests iterate over it.
+func All() []Permission {
+	return []Permission{
+		NodesView,
+		PodsView,
+		PodsExec,
+		PodsNetwork,
+		DeploymentsView,
+		DeploymentsCreate,
+		DeploymentsDelete,
+		DeploymentsScale,
+		EventsView,
+		TopologyView,
+		ServicesView,
+		IngressesView,
+		ConfigMapsView,
+		SecretsView,
+		SecretsReveal,
+		PVCsView,
+		TokensRevoke,
+		AuditView,
+		APIKeysManage,
+		ConfigView,
+	}
+}
+
+const (
+	// Wildcard grants any resource, any action, or everything when used alone,
+	// e.g. "pods:*", "*:view" or "*".
+	Wildcard = "*"
+
+	actionSeparator = ":"
 )
+
+// Match reports whether a granted permission covers the required one. Both
+// must be unscoped, namespaces are resolved by the caller via Split.
+func Match(granted, required Permission) bool {
+	if granted == required || granted == Wildcard {
+		return true
+	}
+
+	grantedResource, grantedAction, ok := strings.Cut(string(granted), actionSeparator)
+	if !ok {
+		return false
+	}
+
+	resource, action, ok := strings.Cut(string(required), actionSeparator)
+	if !ok {
+		return false
+	}
+
+	return (grantedResource == Wildcard || grantedResource == resource) &&
+		(grantedAction == Wildcard || grantedAction == action)
+}
+
+// namespaceSeparator splits a granted permission from the namespace it is
+// limited to, e.g. "pods:view@payments".
+const namespaceSeparator = "@"
+
+// Scoped returns the permission limited to a single namespace.
+func (p Permission) Scoped(namespace string) Permission {
+	return Permission(string(p) + namespaceSeparator + namespace)
+}
+
+// Split separates a granted permission into its base permission and the
+// namespace it is limited to. The namespace is empty for cluster-wide grants.
+// A separator without a namespace, as in "pods:view@", is not valid, since
+// reading it as cluster-wide would widen the grant.
+func (p Permission) Split() (Permission, string, bool) {
+	base, namespace, found := strings.Cut(string(p), namespaceSeparator)
+	if !found {
+		return p, "", true
+	}
+
+	if namespace == "" {
+		return "", "", false
+	}
+
+	return Permission(base), namespace, true
+}
@@ -0,0 +1,108 @@
+package permissions
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatch_AllPermissions(t *testing.T) {
+	for _, required := range All() {
+		resource, action, _ := strings.Cut(string(required), ":")
+
+		t.Run(required.String(), func(t *testing.T) {
+			assert.True(t, Match(required, required), "exact grant")
+			assert.True(t, Match(Wildcard, required), "global wildcard")
+			assert.True(t, Match(Permission(resource+":*"), required), "resource wildcard")
+			assert.True(t, Match(Permission("*:"+action), required), "action wildcard")
+			assert.True(t, Match("*:*", required), "resource and action wildcard")
+
+			assert.False(t, Match(Permission("other:"+action), required), "other resource")
+			assert.False(t, Match(Permission(resource+":other"), required), "other action")
+			assert.False(t, Match("other:*", required), "other resource wildcard")
+			assert.False(t, Match("*:other", required), "other action wildcard")
+			assert.False(t, Match(Permission(resource), required), "resource without action")
+			assert.False(t, Match("", required), "empty grant")
+		})
+	}
+}
+
+func TestMatch_DoesNotCrossPermissions(t *testing.T) {
+	all := All()
+
+	for _, granted := range all {
+		for _, required := range all {
+			assert.Equal(t, granted == required, Match(granted, required), "%s grants %s", granted, required)
+		}
+	}
+}
+
+func TestAll_CoversConstants(t *testing.T) {
+	file, err := parser.ParseFile(token.NewFileSet(), "permissions.go", nil, 0)
+	assert.NoError(t, err)
+
+	declared := make(map[Permission]bool)
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.CONST {
+			continue
+		}
+
+		for _, spec := range gen.Specs {
+			value := spec.(*ast.ValueSpec)
+			if ident, ok := value.Type.(*ast.Ident); !ok || ident.Name != "Permission" {
+				continue
+			}
+
+			literal := value.Values[0].(*ast.BasicLit)
+			declared[Permission(strings.Trim(literal.Value, `"`))] = true
+		}
+	}
+
+	for _, permission := range All() {
+		delete(declared, permission)
+	}
+
+	assert.Empty(t, declared, "permissions missing from All()")
+}
+
+func TestAll_IsUnique(t *testing.T) {
+	seen := make(map[Permission]bool)
+
+	for _, permission := range All() {
+		assert.False(t, seen[permission], "duplicate permission %s", permission)
+		seen[permission] = true
+	}
+}
+
+func TestPermission_Split(t *testing.T) {
+	type testCase struct {
+		name              string
+		permission        Permission
+		expectedBase      Permission
+		expectedNamespace string
+		invalid           bool
+	}
+
+	tests := []testCase{
+		{name: "Cluster-wide", permission: PodsView, expectedBase: PodsView},
+		{name: "Namespaced", permission: PodsView.Scoped("payments"), expectedBase: PodsView, expectedNamespace: "payments"},
+		{name: "Namespaced wildcard", permission: "pods:*@payments", expectedBase: "pods:*", expectedNamespace: "payments"},
+		{name: "Empty namespace", permission: "pods:view@", invalid: true},
+		{name: "Empty namespace on a wildcard", permission: "*@", invalid: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			base, namespace, ok := tc.permission.Split()
+
+			assert.Equal(t, !tc.invalid, ok)
+			assert.Equal(t, tc.expectedBase, base)
+			assert.Equal(t, tc.expectedNamespace, namespace)
+		})
+	}
+}
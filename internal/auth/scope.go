@@ -0,0 +1,148 @@
+package auth
+
+import (
+	"cluster-agent/internal/auth/permissions"
+	"slices"
+)
+
+// NamespaceScope is the set of namespaces a permission has been granted in.
+// A nil scope is unrestricted.
+type NamespaceScope struct {
+	all        bool
+	namespaces map[string]struct{}
+}
+
+func NewNamespaceScope(namespaces ...string) *NamespaceScope {
+	scope := &NamespaceScope{
+		namespaces: make(map[string]struct{}, len(namespaces)),
+	}
+
+	for _, namespace := range namespaces {
+		scope.namespaces[namespace] = struct{}{}
+	}
+
+	return scope
+}
+
+func AllNamespaces() *NamespaceScope {
+	return &NamespaceScope{all: true}
+}
+
+func (s *NamespaceScope) Allows(namespace string) bool {
+	if s == nil || s.all {
+		return true
+	}
+
+	_, ok := s.namespaces[namespace]
+	return ok
+}
+
+func (s *NamespaceScope) IsAll() bool {
+	return s == nil || s.all
+}
+
+func (s *NamespaceScope) IsEmpty() bool {
+	return s != nil && !s.all && len(s.namespaces) == 0
+}
+
+// Namespaces returns the sorted namespaces of a restricted scope, or nil for
+// an unrestricted one.
+func (s *NamespaceScope) Namespaces() []string {
+	if s.IsAll() {
+		return nil
+	}
+
+	result := make([]string, 0, len(s.namespaces))
+	for namespace := range s.namespaces {
+		result = append(result, namespace)
+	}
+	slices.Sort(result)
+
+	return result
+}
+
+// Scope resolves the namespaces the claims grant the permission in.
+// Cluster-wide grants are limited by the namespaces claim when it is present,
+// while grants like "pods:view@payments" always apply to their own namespace.
+// Wildcards such as "pods:*@payments" are expanded by permissions.Match.
+func (c *UserClaims) Scope(permission permissions.Permission) *NamespaceScope {
+	scope := NewNamespaceScope()
+
+	for _, granted := range c.Permissions {
+		base, namespace, ok := granted.Split()
+		if !ok || !permissions.Match(base, permission) {
+			continue
+		}
+
+		if namespace != "" {
+			scope.namespaces[namespace] = struct{}{}
+			continue
+		}
+
+		if len(c.Namespaces) == 0 {
+			return AllNamespaces()
+		}
+
+		for _, allowed := range c.Namespaces {
+			scope.namespaces[allowed] = struct{}{}
+		}
+	}
+
+	return scope
+}
+
+// GrantedNamespaces resolves the namespaces the claims grant any permission
+// in, following the same rules as Scope.
+func (c *UserClaims) GrantedNamespaces() *NamespaceScope {
+	scope := NewNamespaceScope()
+
+	for _, granted := range c.Permissions {
+		_, namespace, ok := granted.Split()
+		if !ok {
+			continue
+		}
+
+		if namespace != "" {
+			scope.namespaces[namespace] = struct{}{}
+			continue
+		}
+
+		if len(c.Namespaces) == 0 {
+			return AllNamespaces()
+		}
+
+		for _, allowed := range c.Namespaces {
+			scope.namespaces[allowed] = struct{}{}
+		}
+	}
+
+	return scope
+}
+
+// CanGrant reports whether the claims may delegate a permission, e.g. to an
+// API key limited to the given namespaces. Wildcards in the delegated
+// permission are only covered by equal or broader grants.
+func (c *UserClaims) CanGrant(permission permissions.Permission, namespaces []string) bool {
+	base, namespace, ok := permission.Split()
+	if !ok {
+		return false
+	}
+
+	scope := c.Scope(base)
+
+	if namespace != "" {
+		return scope.Allows(namespace)
+	}
+
+	if len(namespaces) == 0 {
+		return scope.IsAll()
+	}
+
+	for _, allowed := range namespaces {
+		if !scope.Allows(allowed) {
+			return false
+		}
+	}
+
+	return true
+}
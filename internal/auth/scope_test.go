@@ -0,0 +1,120 @@
+package auth
+
+import (
+	"cluster-agent/internal/auth/permissions"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserClaims_Scope(t *testing.T) {
+	type testCase struct {
+		name               string
+		claims             UserClaims
+		expectedAll        bool
+		expectedNamespaces []string
+	}
+
+	tests := []testCase{
+		{
+			name:        "Cluster-wide grant",
+			claims:      UserClaims{Permissions: []permissions.Permission{permissions.PodsView}},
+			expectedAll: true,
+		},
+		{
+			name: "Cluster-wide grant limited by the namespaces claim",
+			claims: UserClaims{
+				Permissions: []permissions.Permission{permissions.PodsView},
+				Namespaces:  []string{"payments", "batch"},
+			},
+			expectedNamespaces: []string{"batch", "payments"},
+		},
+		{
+			name: "Namespaced grants",
+			claims: UserClaims{Permissions: []permissions.Permission{
+				permissions.PodsView.Scoped("payments"),
+				"pods:*@batch",
+				permissions.DeploymentsView.Scoped("web"),
+			}},
+			expectedNamespaces: []string{"batch", "payments"},
+		},
+		{
+			name:               "Empty namespace grants nothing",
+			claims:             UserClaims{Permissions: []permissions.Permission{"pods:view@"}},
+			expectedNamespaces: []string{},
+		},
+		{
+			name:               "Empty namespace on a wildcard grants nothing",
+			claims:             UserClaims{Permissions: []permissions.Permission{"*@"}},
+			expectedNamespaces: []string{},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			scope := tc.claims.Scope(permissions.PodsView)
+
+			assert.Equal(t, tc.expectedAll, scope.IsAll())
+			if !tc.expectedAll {
+				assert.Equal(t, tc.expectedNamespaces, scope.Namespaces())
+			}
+		})
+	}
+}
+
+func TestUserClaims_GrantedNamespaces(t *testing.T) {
+	type testCase struct {
+		name               string
+		claims             UserClaims
+		expectedAll        bool
+		expectedNamespaces []string
+	}
+
+	tests := []testCase{
+		{
+			name:        "Cluster-wide grant",
+			claims:      UserClaims{Permissions: []permissions.Permission{permissions.NodesView}},
+			expectedAll: true,
+		},
+		{
+			name: "Cluster-wide grant limited by the namespaces claim",
+			claims: UserClaims{
+				Permissions: []permissions.Permission{permissions.PodsView},
+				Namespaces:  []string{"payments"},
+			},
+			expectedNamespaces: []string{"payments"},
+		},
+		{
+			name: "Namespaced grants of any permission",
+			claims: UserClaims{Permissions: []permissions.Permission{
+				permissions.PodsView.Scoped("payments"),
+				permissions.DeploymentsView.Scoped("web"),
+				"pods:view@",
+			}},
+			expectedNamespaces: []string{"payments", "web"},
+		},
+		{
+			name:               "No grants",
+			claims:             UserClaims{},
+			expectedNamespaces: []string{},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			scope := tc.claims.GrantedNamespaces()
+
+			assert.Equal(t, tc.expectedAll, scope.IsAll())
+			if !tc.expectedAll {
+				assert.Equal(t, tc.expectedNamespaces, scope.Namespaces())
+			}
+		})
+	}
+}
+
+func TestUserClaims_CanGrant(t *testing.T) {
+	claims := UserClaims{Permissions: []permissions.Permission{permissions.PodsView}}
+
+	assert.True(t, claims.CanGrant(permissions.PodsView.Scoped("payments"), nil))
+	assert.False(t, claims.CanGrant("pods:view@", nil))
+}
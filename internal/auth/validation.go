@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"cluster-agent/internal/config"
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ValidationOptions are the claim checks applied on top of the signature.
+type ValidationOptions struct {
+	Issuer            string
+	Audience          string
+	Leeway            time.Duration
+	RequireExpiration bool
+	RequireNotBefore  bool
+}
+
+func NewValidationOptions(cfg *config.Config) ValidationOptions {
+	return ValidationOptions{
+		Issuer:            cfg.JWTIssuer,
+		Audience:          cfg.JWTAudience,
+		Leeway:            cfg.JWTLeeway,
+		RequireExpiration: cfg.JWTRequireExpiration,
+		RequireNotBefore:  cfg.JWTRequireNotBefore,
+	}
+}
+
+func (o ValidationOptions) parserOptions() []jwt.ParserOption {
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods(supportedMethods),
+		jwt.WithLeeway(o.Leeway),
+		jwt.WithIssuedAt(),
+	}
+
+	if o.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(o.Issuer))
+	}
+
+	if o.Audience != "" {
+		opts = append(opts, jwt.WithAudience(o.Audience))
+	}
+
+	if o.RequireExpiration {
+		opts = append(opts, jwt.WithExpirationRequired())
+	}
+
+	return opts
+}
+
+// ErrorReason maps a ParseToken error to a short reason that is safe to
+// return to the client.
+func ErrorReason(err error) string {
+	switch {
+	case errors.Is(err, jwt.ErrTokenMalformed):
+		return "malformed token"
+	case errors.Is(err, ErrUnknownKey):
+		return "unknown signing key"
+	case errors.Is(err, jwt.ErrTokenSignatureInvalid):
+		return "invalid token signature"
+	case errors.Is(err, jwt.ErrTokenExpired):
+		return "token expired"
+	case errors.Is(err, jwt.ErrTokenNotValidYet):
+		return "token not valid yet"
+	case errors.Is(err, jwt.ErrTokenUsedBeforeIssued):
+		return "token used before issued"
+	case errors.Is(err, jwt.ErrTokenInvalidIssuer):
+		return "invalid token issuer"
+	case errors.Is(err, jwt.ErrTokenInvalidAudience):
+		return "invalid token audience"
+	case errors.Is(err, jwt.ErrTokenRequiredClaimMissing):
+		return "token is missing a required claim"
+	default:
+		return "invalid token"
+	}
+}
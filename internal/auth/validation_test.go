@@ -0,0 +1,266 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func signToken(t *testing.T, method jwt.SigningMethod, kid string, key crypto.PrivateKey, claims jwt.Claims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func TestParseToken(t *testing.T) {
+	type testCase struct {
+		name           string
+		options        ValidationOptions
+		token          func(t *testing.T) string
+		expectedReason string
+	}
+
+	keys := generateTestKeys(t)
+	pinned := rsaJWK("rsa-1", &keys.rsa.PublicKey)
+	pinned.Alg = "RS256"
+	document := jwksDocument(t, pinned, ecJWK(t, "ec-1", &keys.ec.PublicKey), okpJWK("ed-1", keys.ed25519.Public().(ed25519.PublicKey)))
+	keySet, err := NewJWKSKeySet(func(context.Context) ([]byte, error) { return document, nil }, 0)
+	require.NoError(t, err)
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	now := time.Now()
+	at := func(offset time.Duration) *jwt.NumericDate {
+		return jwt.NewNumericDate(now.Add(offset))
+	}
+	claims := func(modify func(c *UserClaims)) *UserClaims {
+		c := &UserClaims{
+			RegisteredClaims: jwt.RegisteredClaims{
+				Issuer:    "https://auth.example.com",
+				Audience:  jwt.ClaimStrings{"cluster-agent"},
+				ExpiresAt: at(time.Hour),
+				NotBefore: at(-time.Minute),
+				IssuedAt:  at(-time.Minute),
+			},
+			UserId: "42",
+		}
+		if modify != nil {
+			modify(c)
+		}
+		return c
+	}
+	rsaToken := func(modify func(c *UserClaims)) func(t *testing.T) string {
+		return func(t *testing.T) string {
+			return signToken(t, jwt.SigningMethodRS256, "rsa-1", keys.rsa, claims(modify))
+		}
+	}
+
+	defaults := ValidationOptions{
+		Issuer:            "https://auth.example.com",
+		Audience:          "cluster-agent",
+		Leeway:            30 * time.Second,
+		RequireExpiration: true,
+	}
+	withOptions := func(modify func(o *ValidationOptions)) ValidationOptions {
+		o := defaults
+		modify(&o)
+		return o
+	}
+
+	tests := []testCase{
+		{name: "Valid RSA token", options: defaults, token: rsaToken(nil)},
+		{
+			name:    "Valid EC token",
+			options: defaults,
+			token: func(t *testing.T) string {
+				return signToken(t, jwt.SigningMethodES256, "ec-1", keys.ec, claims(nil))
+			},
+		},
+		{
+			name:    "Valid EdDSA token",
+			options: defaults,
+			token: func(t *testing.T) string {
+				return signToken(t, jwt.SigningMethodEdDSA, "ed-1", keys.ed25519, claims(nil))
+			},
+		},
+		{
+			name:    "Expired within leeway",
+			options: defaults,
+			token:   rsaToken(func(c *UserClaims) { c.ExpiresAt = at(-10 * time.Second) }),
+		},
+		{
+			name:           "Expired beyond leeway",
+			options:        defaults,
+			token:          rsaToken(func(c *UserClaims) { c.ExpiresAt = at(-time.Minute) }),
+			expectedReason: "token expired",
+		},
+		{
+			name:    "Not yet valid within leeway",
+			options: defaults,
+			token:   rsaToken(func(c *UserClaims) { c.NotBefore = at(10 * time.Second) }),
+		},
+		{
+			name:           "Not yet valid beyond leeway",
+			options:        defaults,
+			token:          rsaToken(func(c *UserClaims) { c.NotBefore = at(time.Minute) }),
+			expectedReason: "token not valid yet",
+		},
+		{
+			name:           "Leeway of zero",
+			options:        withOptions(func(o *ValidationOptions) { o.Leeway = 0 }),
+			token:          rsaToken(func(c *UserClaims) { c.ExpiresAt = at(-10 * time.Second) }),
+			expectedReason: "token expired",
+		},
+		{
+			name:           "Issued in the future",
+			options:        defaults,
+			token:          rsaToken(func(c *UserClaims) { c.IssuedAt = at(time.Hour) }),
+			expectedReason: "token used before issued",
+		},
+		{
+			name:           "Missing exp when required",
+			options:        defaults,
+			token:          rsaToken(func(c *UserClaims) { c.ExpiresAt = nil }),
+			expectedReason: "token is missing a required claim",
+		},
+		{
+			name:    "Missing exp when optional",
+			options: withOptions(func(o *ValidationOptions) { o.RequireExpiration = false }),
+			token:   rsaToken(func(c *UserClaims) { c.ExpiresAt = nil }),
+		},
+		{
+			name:           "Missing nbf when required",
+			options:        withOptions(func(o *ValidationOptions) { o.RequireNotBefore = true }),
+			token:          rsaToken(func(c *UserClaims) { c.NotBefore = nil }),
+			expectedReason: "token is missing a required claim",
+		},
+		{
+			name:    "Missing nbf when optional",
+			options: defaults,
+			token:   rsaToken(func(c *UserClaims) { c.NotBefore = nil }),
+		},
+		{
+			name:           "Wrong issuer",
+			options:        defaults,
+			token:          rsaToken(func(c *UserClaims) { c.Issuer = "https://evil.example.com" }),
+			expectedReason: "invalid token issuer",
+		},
+		{
+			name:           "Wrong audience",
+			options:        defaults,
+			token:          rsaToken(func(c *UserClaims) { c.Audience = jwt.ClaimStrings{"other"} }),
+			expectedReason: "invalid token audience",
+		},
+		{
+			name:    "Issuer and audience not checked when unset",
+			options: ValidationOptions{Leeway: 30 * time.Second},
+			token:   rsaToken(func(c *UserClaims) { c.Issuer, c.Audience = "", nil }),
+		},
+		{
+			name:    "Signed by another key",
+			options: defaults,
+			token: func(t *testing.T) string {
+				return signToken(t, jwt.SigningMethodRS256, "rsa-1", otherKey, claims(nil))
+			},
+			expectedReason: "invalid token signature",
+		},
+		{
+			name:    "Unknown kid",
+			options: defaults,
+			token: func(t *testing.T) string {
+				return signToken(t, jwt.SigningMethodRS256, "rsa-2", keys.rsa, claims(nil))
+			},
+			expectedReason: "unknown signing key",
+		},
+		{
+			name:    "Algorithm differs from the pinned one",
+			options: defaults,
+			token: func(t *testing.T) string {
+				return signToken(t, jwt.SigningMethodPS256, "rsa-1", keys.rsa, claims(nil))
+			},
+			expectedReason: "invalid token",
+		},
+		{
+			name:    "Algorithm does not match the key type",
+			options: defaults,
+			token: func(t *testing.T) string {
+				return signToken(t, jwt.SigningMethodRS256, "ec-1", keys.rsa, claims(nil))
+			},
+			expectedReason: "invalid token",
+		},
+		{
+			name:    "HMAC is not accepted",
+			options: defaults,
+			token: func(t *testing.T) string {
+				return signToken(t, jwt.SigningMethodHS256, "rsa-1", []byte("secret"), claims(nil))
+			},
+			expectedReason: "invalid token signature",
+		},
+		{
+			name:           "Malformed",
+			options:        defaults,
+			token:          func(t *testing.T) string { return "not-a-token" },
+			expectedReason: "malformed token",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var parsed UserClaims
+			_, err := ParseToken(tt.token(t), &parsed, keySet, tt.options)
+
+			if tt.expectedReason == "" {
+				require.NoError(t, err)
+				assert.Equal(t, "42", parsed.UserId)
+				return
+			}
+
+			require.Error(t, err)
+			assert.Equal(t, tt.expectedReason, ErrorReason(err))
+		})
+	}
+}
+
+func TestErrorReason(t *testing.T) {
+	type testCase struct {
+		err      error
+		expected string
+	}
+
+	tests := []testCase{
+		{err: jwt.ErrTokenMalformed, expected: "malformed token"},
+		{err: fmt.Errorf("%w: %q", ErrUnknownKey, "kid"), expected: "unknown signing key"},
+		{err: jwt.ErrTokenSignatureInvalid, expected: "invalid token signature"},
+		{err: jwt.ErrTokenExpired, expected: "token expired"},
+		{err: jwt.ErrTokenNotValidYet, expected: "token not valid yet"},
+		{err: jwt.ErrTokenUsedBeforeIssued, expected: "token used before issued"},
+		{err: jwt.ErrTokenInvalidIssuer, expected: "invalid token issuer"},
+		{err: jwt.ErrTokenInvalidAudience, expected: "invalid token audience"},
+		{err: fmt.Errorf("%w: nbf", jwt.ErrTokenRequiredClaimMissing), expected: "token is missing a required claim"},
+		{err: errors.New("token is empty"), expected: "invalid token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ErrorReason(tt.err))
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package cache
+
+import (
+	"cluster-agent/internal/models"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"strconv"
+	"time"
+)
+
+const (
+	apiKeyKeyPrefix = "apikey:"
+	apiKeyIndexKey  = "apikeys"
+
+	apiKeyDataField     = "data"
+	apiKeyLastUsedField = "last_used"
+)
+
+// touchAPIKeyScript updates last use only while the key exists, so a use
+// racing a revocation cannot recreate the key without its expiry.
+var touchAPIKeyScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	redis.call('HSET', KEYS[1], ARGV[1], ARGV[2])
+end
+return 0
+`)
+
+// APIKeyCache keeps each key in a hash with its record and last use, plus an
+// index set for listing. Expired keys vanish with their TTL and are pruned
+// from the index on the next listing.
+type APIKeyCache struct {
+	redisClient *redis.Client
+}
+
+func NewAPIKeyCache(redisClient *redis.Client) *APIKeyCache {
+	return &APIKeyCache{
+		redisClient: redisClient,
+	}
+}
+
+func (c *APIKeyCache) SaveAPIKey(ctx context.Context, key models.StoredAPIKey, ttl time.Duration) error {
+	data, err := json.Marshal(key)
+	if err != nil {
+		return fmt.Errorf("failed to encode api key: %w", err)
+	}
+
+	redisKey := apiKeyKeyPrefix + key.Id
+
+	_, err = c.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, redisKey, apiKeyDataField, data)
+		if ttl > 0 {
+			pipe.Expire(ctx, redisKey, ttl)
+		}
+		pipe.SAdd(ctx, apiKeyIndexKey, key.Id)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save api key: %w", err)
+	}
+
+	return nil
+}
+
+func (c *APIKeyCache) GetAPIKey(ctx context.Context, id string) (*models.StoredAPIKey, error) {
+	fields, err := c.redisClient.HGetAll(ctx, apiKeyKeyPrefix+id).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get api key: %w", err)
+	}
+
+	return decodeAPIKey(fields)
+}
+
+func (c *APIKeyCache) ListAPIKeys(ctx context.Context) ([]models.StoredAPIKey, error) {
+	ids, err := c.redisClient.SMembers(ctx, apiKeyIndexKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+
+	pipe := c.redisClient.Pipeline()
+	results := make([]*redis.MapStringStringCmd, len(ids))
+	for i, id := range ids {
+		results[i] = pipe.HGetAll(ctx, apiKeyKeyPrefix+id)
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+
+	keys := make([]models.StoredAPIKey, 0, len(ids))
+	var expired []interface{}
+
+	for i, result := range results {
+		key, err := decodeAPIKey(result.Val())
+		if err != nil {
+			return nil, err
+		}
+
+		if key == nil {
+			expired = append(expired, ids[i])
+			continue
+		}
+
+		keys = append(keys, *key)
+	}
+
+	if len(expired) > 0 {
+		c.redisClient.SRem(ctx, apiKeyIndexKey, expired...)
+	}
+
+	return keys, nil
+}
+
+func (c *APIKeyCache) DeleteAPIKey(ctx context.Context, id string) (bool, error) {
+	var deleted *redis.IntCmd
+
+	_, err := c.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		deleted = pipe.Del(ctx, apiKeyKeyPrefix+id)
+		pipe.SRem(ctx, apiKeyIndexKey, id)
+		return nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to delete api key: %w", err)
+	}
+
+	return deleted.Val() > 0, nil
+}
+
+func (c *APIKeyCache) TouchAPIKey(ctx context.Context, id string, usedAt time.Time) error {
+	err := touchAPIKeyScript.Run(ctx, c.redisClient,
+		[]string{apiKeyKeyPrefix + id},
+		apiKeyLastUsedField, usedAt.Unix(),
+	).Err()
+	if err != nil {
+		return fmt.Errorf("failed to touch api key: %w", err)
+	}
+
+	return nil
+}
+
+func decodeAPIKey(fields map[string]string) (*models.StoredAPIKey, error) {
+	data, ok := fields[apiKeyDataField]
+	if !ok {
+		return nil, nil
+	}
+
+	var key models.StoredAPIKey
+	if err := json.Unmarshal([]byte(data), &key); err != nil {
+		return nil, fmt.Errorf("failed to decode api key: %w", err)
+	}
+
+	if lastUsed, err := strconv.ParseInt(fields[apiKeyLastUsedField], 10, 64); err == nil {
+		usedAt := time.Unix(lastUsed, 0).UTC()
+		key.LastUsedAt = &usedAt
+	}
+
+	return &key, nil
+}
@@ -0,0 +1,117 @@
+package cache
+
+import (
+	"cluster-agent/internal/config"
+	"cluster-agent/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"strconv"
+)
+
+const (
+	eventSpoolKeyPrefix = "events:spool:"
+
+	eventSpoolEventsField  = "events"
+	eventSpoolPayloadField = "payload"
+)
+
+// appendEventBatchScript makes room by deleting the oldest batches before
+// adding the new one, and returns how many batches and events it deleted.
+var appendEventBatchScript = redis.NewScript(`
+local excess = redis.call('XLEN', KEYS[1]) + 1 - tonumber(ARGV[1])
+local batches, events = 0, 0
+
+if excess > 0 then
+	for _, entry in ipairs(redis.call('XRANGE', KEYS[1], '-', '+', 'COUNT', excess)) do
+		local fields = entry[2]
+		for i = 1, #fields, 2 do
+			if fields[i] == 'events' then
+				events = events + tonumber(fields[i + 1])
+			end
+		end
+		redis.call('XDEL', KEYS[1], entry[1])
+		batches = batches + 1
+	end
+end
+
+redis.call('XADD', KEYS[1], '*', 'events', ARGV[2], 'payload', ARGV[3])
+return {batches, events}
+`)
+
+// EventSpoolCache keeps undelivered event batches in a Redis stream per sink,
+// which preserves their order and survives agent restarts.
+type EventSpoolCache struct {
+	redisClient *redis.Client
+	maxBatches  int64
+}
+
+func NewEventSpoolCache(redisClient *redis.Client, cfg *config.Config) *EventSpoolCache {
+	return &EventSpoolCache{
+		redisClient: redisClient,
+		maxBatches:  cfg.EventSpoolMaxBatches,
+	}
+}
+
+func (c *EventSpoolCache) Enabled() bool {
+	return c.maxBatches > 0
+}
+
+func (c *EventSpoolCache) AppendBatch(ctx context.Context, sink string, events int, payload []byte) (int64, error) {
+	result, err := appendEventBatchScript.Run(ctx, c.redisClient, []string{eventSpoolKeyPrefix + sink}, c.maxBatches, events, payload).Int64Slice()
+	if err != nil {
+		return 0, fmt.Errorf("failed to spool event batch: %w", err)
+	}
+
+	return result[1], nil
+}
+
+func (c *EventSpoolCache) OldestBatch(ctx context.Context, sink string) (*models.SpooledEventBatch, error) {
+	entries, err := c.redisClient.XRangeN(ctx, eventSpoolKeyPrefix+sink, "-", "+", 1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read event spool: %w", err)
+	}
+
+	if len(entries) == 0 {
+		return nil, nil
+	}
+
+	return decodeEventBatch(entries[0])
+}
+
+func (c *EventSpoolCache) RemoveBatch(ctx context.Context, sink string, id string) error {
+	if err := c.redisClient.XDel(ctx, eventSpoolKeyPrefix+sink, id).Err(); err != nil {
+		return fmt.Errorf("failed to remove spooled event batch: %w", err)
+	}
+
+	return nil
+}
+
+func (c *EventSpoolCache) PendingBatches(ctx context.Context, sink string) (int64, error) {
+	count, err := c.redisClient.XLen(ctx, eventSpoolKeyPrefix+sink).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read event spool length: %w", err)
+	}
+
+	return count, nil
+}
+
+func decodeEventBatch(entry redis.XMessage) (*models.SpooledEventBatch, error) {
+	payload, ok := entry.Values[eventSpoolPayloadField].(string)
+	if !ok {
+		return nil, errors.New("spooled event batch without payload")
+	}
+
+	events, _ := entry.Values[eventSpoolEventsField].(string)
+	count, err := strconv.Atoi(events)
+	if err != nil {
+		return nil, fmt.Errorf("invalid spooled event count %q: %w", events, err)
+	}
+
+	return &models.SpooledEventBatch{
+		Id:      entry.ID,
+		Events:  count,
+		Payload: []byte(payload),
+	}, nil
+}
@@ -0,0 +1,73 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"time"
+)
+
+const rateLimitKeyPrefix = "ratelimit:"
+
+// slidingWindowScript keeps one sorted-set member per accepted request, scored
+// by its time in milliseconds. It uses the Redis clock so replicas with skewed
+// clocks still share one window. It returns {allowed, retry after ms, remaining}.
+var slidingWindowScript = redis.NewScript(`
+local key = KEYS[1]
+local window = tonumber(ARGV[1])
+local limit = tonumber(ARGV[2])
+local member = ARGV[3]
+
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+redis.call('ZREMRANGEBYSCORE', key, '-inf', now - window)
+
+local count = redis.call('ZCARD', key)
+if count < limit then
+	redis.call('ZADD', key, now, member)
+	redis.call('PEXPIRE', key, window)
+	return {1, 0, limit - count - 1}
+end
+
+local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+local retry = window
+if oldest[2] then
+	retry = tonumber(oldest[2]) + window - now
+end
+
+return {0, retry, 0}
+`)
+
+type RateLimitCache struct {
+	redisClient *redis.Client
+}
+
+func NewRateLimitCache(redisClient *redis.Client) *RateLimitCache {
+	return &RateLimitCache{
+		redisClient: redisClient,
+	}
+}
+
+func (c *RateLimitCache) Allow(ctx context.Context, key string, limit int, window time.Duration) (bool, int, time.Duration, error) {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return false, 0, 0, fmt.Errorf("failed to generate request id: %w", err)
+	}
+
+	result, err := slidingWindowScript.Run(ctx, c.redisClient,
+		[]string{rateLimitKeyPrefix + key},
+		window.Milliseconds(), limit, hex.EncodeToString(id),
+	).Int64Slice()
+	if err != nil {
+		return false, 0, 0, fmt.Errorf("failed to check rate limit: %w", err)
+	}
+
+	if len(result) != 3 {
+		return false, 0, 0, fmt.Errorf("unexpected rate limit result: %v", result)
+	}
+
+	return result[0] == 1, int(result[2]), time.Duration(result[1]) * time.Millisecond, nil
+}
@@ -0,0 +1,78 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"strconv"
+	"time"
+)
+
+const (
+	revokedTokenKeyPrefix = "revoked:token:"
+	revokedUserKeyPrefix  = "revoked:user:"
+)
+
+type RevocationCache struct {
+	redisClient *redis.Client
+}
+
+func NewRevocationCache(redisClient *redis.Client) *RevocationCache {
+	return &RevocationCache{
+		redisClient: redisClient,
+	}
+}
+
+func (c *RevocationCache) RevokeToken(ctx context.Context, tokenId string, ttl time.Duration) error {
+	err := c.redisClient.Set(ctx, revokedTokenKeyPrefix+tokenId, 1, ttl).Err()
+	if err != nil {
+		return fmt.Errorf("failed to save revoked token: %w", err)
+	}
+
+	return nil
+}
+
+func (c *RevocationCache) IsTokenRevoked(ctx context.Context, tokenId string) (bool, error) {
+	count, err := c.redisClient.Exists(ctx, revokedTokenKeyPrefix+tokenId).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to check revoked token: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+// SetUserRevokedBefore keeps the time to the nanosecond, so tokens with a
+// fractional iat issued later in the same second stay valid.
+func (c *RevocationCache) SetUserRevokedBefore(ctx context.Context, userId string, before time.Time) error {
+	err := c.redisClient.Set(ctx, revokedUserKeyPrefix+userId, before.UTC().Format(time.RFC3339Nano), 0).Err()
+	if err != nil {
+		return fmt.Errorf("failed to save revoked user: %w", err)
+	}
+
+	return nil
+}
+
+// GetUserRevokedBefore returns the zero time when the user has no revocation.
+// Revocations saved as Unix seconds by earlier versions are still read.
+func (c *RevocationCache) GetUserRevokedBefore(ctx context.Context, userId string) (time.Time, error) {
+	value, err := c.redisClient.Get(ctx, revokedUserKeyPrefix+userId).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return time.Time{}, nil
+		}
+
+		return time.Time{}, fmt.Errorf("failed to read revoked user: %w", err)
+	}
+
+	if before, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return before, nil
+	}
+
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid revoked user time %q: %w", value, err)
+	}
+
+	return time.Unix(seconds, 0), nil
+}
@@ -0,0 +1,90 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryHook answers SET and GET from a map, so the client never connects.
+type memoryHook struct {
+	values map[string]string
+}
+
+func (h *memoryHook) DialHook(redis.DialHook) redis.DialHook {
+	return func(context.Context, string, string) (net.Conn, error) {
+		return nil, errors.New("unexpected dial")
+	}
+}
+
+func (h *memoryHook) ProcessHook(redis.ProcessHook) redis.ProcessHook {
+	return func(_ context.Context, cmd redis.Cmder) error {
+		args := cmd.Args()
+		key := fmt.Sprint(args[1])
+
+		switch c := cmd.(type) {
+		case *redis.StatusCmd:
+			h.values[key] = fmt.Sprint(args[2])
+			c.SetVal("OK")
+		case *redis.StringCmd:
+			value, ok := h.values[key]
+			if !ok {
+				c.SetErr(redis.Nil)
+				return redis.Nil
+			}
+			c.SetVal(value)
+		}
+
+		return nil
+	}
+}
+
+func (h *memoryHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return next
+}
+
+func newTestRevocationCache() (*RevocationCache, *memoryHook) {
+	hook := &memoryHook{values: make(map[string]string)}
+	client := redis.NewClient(&redis.Options{Addr: "redis.invalid:6379"})
+	client.AddHook(hook)
+	return NewRevocationCache(client), hook
+}
+
+func TestRevocationCache_UserRevokedBefore(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Keeps sub-second precision", func(t *testing.T) {
+		cache, _ := newTestRevocationCache()
+		before := time.Date(2026, 1, 1, 12, 0, 0, 300_000_000, time.UTC)
+
+		require.NoError(t, cache.SetUserRevokedBefore(ctx, "42", before))
+
+		stored, err := cache.GetUserRevokedBefore(ctx, "42")
+		require.NoError(t, err)
+		assert.True(t, before.Equal(stored), "got %s", stored)
+	})
+
+	t.Run("Reads revocations saved in Unix seconds", func(t *testing.T) {
+		cache, hook := newTestRevocationCache()
+		hook.values[revokedUserKeyPrefix+"42"] = "1767268800"
+
+		stored, err := cache.GetUserRevokedBefore(ctx, "42")
+		require.NoError(t, err)
+		assert.True(t, time.Unix(1767268800, 0).Equal(stored))
+	})
+
+	t.Run("User without revocation", func(t *testing.T) {
+		cache, _ := newTestRevocationCache()
+
+		stored, err := cache.GetUserRevokedBefore(ctx, "42")
+		require.NoError(t, err)
+		assert.True(t, stored.IsZero())
+	})
+}
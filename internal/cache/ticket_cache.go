@@ -0,0 +1,45 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"time"
+)
+
+const ticketKeyPrefix = "ws:ticket:"
+
+type TicketCache struct {
+	redisClient *redis.Client
+}
+
+func NewTicketCache(redisClient *redis.Client) *TicketCache {
+	return &TicketCache{
+		redisClient: redisClient,
+	}
+}
+
+func (c *TicketCache) SaveTicket(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	err := c.redisClient.Set(ctx, ticketKeyPrefix+key, value, ttl).Err()
+	if err != nil {
+		return fmt.Errorf("failed to save ticket: %w", err)
+	}
+
+	return nil
+}
+
+// TakeTicket uses GETDEL so concurrent redemptions of one ticket cannot both
+// succeed, even across agent replicas.
+func (c *TicketCache) TakeTicket(ctx context.Context, key string) ([]byte, error) {
+	value, err := c.redisClient.GetDel(ctx, ticketKeyPrefix+key).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("failed to take ticket: %w", err)
+	}
+
+	return value, nil
+}
@@ -1,6 +1,7 @@
 package cache
 
 import (
+	"cluster-agent/internal/config"
 	"cluster-agent/internal/services/graph"
 	"context"
 	"encoding/json"
@@ -10,10 +11,7 @@ import (
 	"time"
 )
 
-const (
-	cacheKeyPrefix = "topology:"
-	ttl            = time.Hour
-)
+const cacheKeyPrefix = "topology:"
 
 var (
 	ErrNotFound = errors.New("topology not found in cache")
@@ -21,11 +19,13 @@ var (
 
 type TopologyCache struct {
 	redisClient *redis.Client
+	ttl         time.Duration
 }
 
-func NewTopologyCache(redisClient *redis.Client) *TopologyCache {
+func NewTopologyCache(redisClient *redis.Client, cfg *config.Config) *TopologyCache {
 	return &TopologyCache{
 		redisClient: redisClient,
+		ttl:         cfg.TopologyCacheTTL,
 	}
 }
 
@@ -57,7 +57,7 @@ func (c *TopologyCache) Set(ctx context.Context, namespace string, topology *gra
 	}
 
 	cacheKey := cacheKeyPrefix + namespace
-	err = c.redisClient.Set(ctx, cacheKey, bytes, ttl).Err()
+	err = c.redisClient.Set(ctx, cacheKey, bytes, c.ttl).Err()
 	if err != nil {
 		return fmt.Errorf("failed to save topology to cache: %w", err)
 	}
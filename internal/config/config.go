@@ -1,53 +1,380 @@
 package config
 
 import (
-	"crypto/rsa"
+	"cluster-agent/internal/auth/permissions"
+	"crypto"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
 	"log"
 	"os"
+	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	ApiURL       string
-	JWTPublicKey *rsa.PublicKey
-	RedisAddr    string
-	RedisPass    string
-	RedisDB      int
+	ApiURL              string
+	ListenAddr          string
+	JWTPublicKey        crypto.PublicKey
+	JWKSURL             string
+	JWKSPath            string
+	JWKSRefreshInterval time.Duration
+	JWTIssuer           string
+	JWTAudience         string
+	JWTLeeway           time.Duration
+	RedisAddr           string
+	RedisPass           string
+	RedisDB             int
+	TopologyCacheTTL    time.Duration
+	AuditSinks          []string
+	AuditFilePath       string
+	AuditHTTPURL        string
+	AuditStreamMaxLen   int64
+	Roles               map[string][]permissions.Permission
+
+	// TLSClientCAPath enables client certificate authentication; certificates
+	// signed by it are mapped to claims through ClientIdentities.
+	TLSCertPath      string
+	TLSKeyPath       string
+	TLSClientCAPath  string
+	ClientIdentities []ClientIdentity
+
+	// TLSMinVersion is a crypto/tls version constant. The certificate and key
+	// are checked for rotation every TLSReloadInterval, zero disables it.
+	TLSMinVersion     uint16
+	TLSReloadInterval time.Duration
+
+	// RateLimits are keyed by route group (pods, deployments, topology...),
+	// with DefaultRateLimit applying to groups without their own entry.
+	RateLimits map[string]RateLimit
+
+	// ExecPolicies are keyed by namespace, with DefaultExecPolicy applying to
+	// namespaces without their own entry. Without either, exec opens a shell.
+	ExecPolicies map[string]ExecPolicy
+
+	// SecretDigestKey keys the HMAC-SHA256 digests shown for masked secret
+	// values, so a digest cannot be used to guess a short value. Without it
+	// the agent picks a random key and digests change when it restarts.
+	SecretDigestKey string
+
+	// EventBatchSize events, EventBatchMaxBytes of encoded events or
+	// EventFlushInterval, whichever comes first, make a batch. EventQueueSize
+	// bounds the events waiting to be batched.
+	EventBatchSize     int
+	EventBatchMaxBytes int64
+	EventFlushInterval time.Duration
+	EventQueueSize     int
+
+	// EventSpoolMaxBatches bounds the Redis stream holding batches a sink
+	// could not take, per sink; the oldest are discarded beyond it. Zero
+	// disables the spool and failed batches are dropped.
+	EventSpoolMaxBatches int64
+
+	// EventSinks receive every batch: "http" posts to ApiURL, "redis"
+	// appends to EventStreamKey, "file" writes JSON Lines to EventFilePath,
+	// rotated at EventFileMaxSize bytes, and "stdout" logs them.
+	EventSinks          []string
+	EventStreamKey      string
+	EventStreamMaxLen   int64
+	EventFilePath       string
+	EventFileMaxSize    int64
+	EventFileMaxBackups int
+
+	// ClusterID identifies this cluster to the backend. The http event and
+	// audit sinks send EventAuthToken in EventAuthHeader (as a bearer token
+	// when that is Authorization) and, with EventSigningSecret, sign every
+	// request.
+	ClusterID          string
+	EventAuthHeader    string
+	EventAuthToken     string
+	EventSigningSecret string
+
+	// EventCompression is the Content-Encoding of http sink requests: "none",
+	// "gzip" or "zstd".
+	EventCompression string
+
+	// EventAggregationWindow collapses repeats of an event (same involved
+	// object, reason and message) into one record per window. It is off by
+	// default: zero forwards every update as it arrives.
+	EventAggregationWindow time.Duration
+
+	// EventFilters decide which events are forwarded at all. They are
+	// checked in order, the first matching rule wins and events matching no
+	// rule are kept.
+	EventFilters []EventFilterRule
+
+	// K8sQPS and K8sBurst are the client-side rate limits for the Kubernetes
+	// API, shared by every request the agent makes.
+	K8sQPS         float32
+	K8sBurst       int
+	InformerResync time.Duration
+
+	// JWTRequireExpiration rejects tokens without exp, JWTRequireNotBefore
+	// rejects tokens without nbf. Claims that are present are always enforced.
+	JWTRequireExpiration bool
+	JWTRequireNotBefore  bool
+
+	// K8sImpersonation runs API calls as the caller instead of the agent's
+	// service account. User and group names get K8sImpersonationUserPrefix
+	// prepended, which is required so callers cannot be impersonated as
+	// Kubernetes' own users such as "system:admin".
+	K8sImpersonation           bool
+	K8sImpersonationUserPrefix string
+
+	// ConfigReloadInterval is how often the config file and the files it
+	// references are checked for changes. Zero leaves reloads to SIGHUP.
+	ConfigReloadInterval time.Duration
+
+	// TrustedProxies are the addresses or CIDR ranges whose X-Forwarded-For
+	// and X-Real-IP headers name the client. Without any, the client is the
+	// peer address, so callers cannot pick the address they are rate limited
+	// and audited by.
+	TrustedProxies []string
+
+	// path and files are where this configuration was read from, so a
+	// Manager can tell when it changes.
+	path  string
+	files []string
 }
 
-func NewConfig() *Config {
+const DefaultRateLimit = "default"
+
+// RateLimit allows Requests per caller within a sliding Window.
+type RateLimit struct {
+	Requests int
+	Window   time.Duration
+}
+
+func (l *RateLimit) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Requests int    `json:"requests"`
+		Window   string `json:"window"`
+	}
+
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	window, err := time.ParseDuration(raw.Window)
+	if err != nil {
+		return fmt.Errorf("invalid window %q: %w", raw.Window, err)
+	}
+
+	if raw.Requests < 0 || window <= 0 {
+		return fmt.Errorf("requests must not be negative and window must be positive")
+	}
+
+	l.Requests = raw.Requests
+	l.Window = window
+	return nil
+}
+
+// DefaultExecPolicy keys the policy for namespaces without their own. It is
+// not a valid namespace name, so it cannot collide with one.
+const DefaultExecPolicy = "*"
+
+type ExecMode string
+
+const (
+	// ExecModeShell allows an interactive shell and any command.
+	ExecModeShell ExecMode = "shell"
+	// ExecModeReadOnly allows only the built-in read-only commands.
+	ExecModeReadOnly ExecMode = "readonly"
+	// ExecModeAllowlist allows only the commands listed in the policy.
+	ExecModeAllowlist ExecMode = "allowlist"
+	// ExecModeDeny disables exec.
+	ExecModeDeny ExecMode = "deny"
+)
+
+type ExecPolicy struct {
+	Mode     ExecMode `json:"mode"`
+	Commands []string `json:"commands,omitempty"`
+}
+
+type EventFilterAction string
+
+const (
+	EventFilterKeep EventFilterAction = "keep"
+	EventFilterDrop EventFilterAction = "drop"
+)
+
+// EventFilterRule matches events by every condition that is set; an empty
+// list matches anything. Message is a regular expression. Excluding a
+// namespace is a drop rule for it, including only some namespaces is a keep
+// rule for them followed by a drop rule without conditions.
+type EventFilterRule struct {
+	Name       string            `json:"name,omitempty"`
+	Action     EventFilterAction `json:"action"`
+	Namespaces []string          `json:"namespaces,omitempty"`
+	Types      []string          `json:"types,omitempty"`
+	Reasons    []string          `json:"reasons,omitempty"`
+	Kinds      []string          `json:"kinds,omitempty"`
+	Message    string            `json:"message,omitempty"`
+}
+
+// RuleName names the rule in counters and logs, falling back to its
+// position for unnamed rules.
+func (r EventFilterRule) RuleName(index int) string {
+	if r.Name != "" {
+		return r.Name
+	}
+
+	return fmt.Sprintf("rule %d", index+1)
+}
+
+// ClientIdentity maps a client certificate to the claims it is granted. A
+// certificate matches when any of the selectors that are set matches.
+type ClientIdentity struct {
+	CommonName string `json:"common_name,omitempty"`
+	DNSName    string `json:"dns_name,omitempty"`
+	URI        string `json:"uri,omitempty"`
+
+	UserId      string                   `json:"user_id"`
+	Permissions []permissions.Permission `json:"permissions"`
+	Namespaces  []string                 `json:"namespaces,omitempty"`
+	Roles       []string                 `json:"roles,omitempty"`
+	Groups      []string                 `json:"groups,omitempty"`
+}
+
+// NewConfig loads the startup configuration, which a Manager later reloads.
+// Settings come from the YAML file at CONFIG_PATH, if any, overridden by
+// environment variables.
+func NewConfig() (*Config, error) {
 	if err := godotenv.Load(); err != nil {
 		log.Println("No .env file found, relying on system envs")
 	}
 
-	pubKey := readPublicKey()
+	return Load(os.Getenv("CONFIG_PATH"))
+}
 
-	return &Config{
-		ApiURL:       os.Getenv("API_URL"),
-		JWTPublicKey: pubKey,
-		RedisAddr:    os.Getenv("REDIS_ADDR"),
-		RedisPass:    os.Getenv("REDIS_PASS"),
-		RedisDB:      0,
+// Load reads the configuration file, applies environment overrides and
+// validates the result. File keys are the environment variable names in lower
+// case, e.g. "listen_addr: :9090" and LISTEN_ADDR=:9090 are equivalent.
+// Structured settings (roles, rate_limits, exec_policies, client_identities)
+// may be inlined in the file or read from their *_PATH files.
+func Load(path string) (*Config, error) {
+	src, err := newSource(path)
+	if err != nil {
+		return nil, err
 	}
-}
 
-func readPublicKey() *rsa.PublicKey {
-	keyPath := os.Getenv("JWT_PUBLIC_KEY_PATH")
-	if keyPath == "" {
-		log.Fatal("JWT_PUBLIC_KEY_PATH is not set")
+	cfg := &Config{
+		ApiURL:              src.string("API_URL", ""),
+		ListenAddr:          src.string("LISTEN_ADDR", ":8080"),
+		JWKSURL:             src.string("JWKS_URL", ""),
+		JWKSPath:            src.string("JWKS_PATH", ""),
+		JWKSRefreshInterval: src.duration("JWKS_REFRESH_INTERVAL", 5*time.Minute),
+		JWTIssuer:           src.string("JWT_ISSUER", ""),
+		JWTAudience:         src.string("JWT_AUDIENCE", ""),
+		JWTLeeway:           src.duration("JWT_LEEWAY", 30*time.Second),
+
+		JWTRequireExpiration: src.bool("JWT_REQUIRE_EXP", true),
+		JWTRequireNotBefore:  src.bool("JWT_REQUIRE_NBF", false),
+
+		RedisAddr:         src.string("REDIS_ADDR", ""),
+		RedisPass:         src.string("REDIS_PASS", ""),
+		RedisDB:           int(src.int("REDIS_DB", 0)),
+		TopologyCacheTTL:  src.duration("TOPOLOGY_CACHE_TTL", time.Hour),
+		AuditSinks:        src.list("AUDIT_SINKS", []string{"redis"}),
+		AuditFilePath:     src.string("AUDIT_FILE_PATH", "audit.jsonl"),
+		AuditHTTPURL:      src.string("AUDIT_HTTP_URL", ""),
+		AuditStreamMaxLen: src.int("AUDIT_STREAM_MAX_LEN", 100000),
+
+		EventBatchSize:     int(src.int("EVENT_BATCH_SIZE", 100)),
+		EventBatchMaxBytes: src.int("EVENT_BATCH_MAX_BYTES", 1<<20),
+		EventFlushInterval: src.duration("EVENT_FLUSH_INTERVAL", 5*time.Second),
+		EventQueueSize:     int(src.int("EVENT_QUEUE_SIZE", 1000)),
+
+		EventSpoolMaxBatches:   src.int("EVENT_SPOOL_MAX_BATCHES", 10000),
+		EventAggregationWindow: src.duration("EVENT_AGGREGATION_WINDOW", 0),
+
+		EventSinks:          src.list("EVENT_SINKS", []string{"http"}),
+		EventStreamKey:      src.string("EVENT_STREAM_KEY", "events:stream"),
+		EventStreamMaxLen:   src.int("EVENT_STREAM_MAX_LEN", 100000),
+		EventFilePath:       src.string("EVENT_FILE_PATH", "events.jsonl"),
+		EventFileMaxSize:    src.int("EVENT_FILE_MAX_SIZE", 100<<20),
+		EventFileMaxBackups: int(src.int("EVENT_FILE_MAX_BACKUPS", 5)),
+
+		ClusterID:          src.string("CLUSTER_ID", ""),
+		EventAuthHeader:    src.string("EVENT_AUTH_HEADER", "Authorization"),
+		EventAuthToken:     src.string("EVENT_AUTH_TOKEN", ""),
+		EventSigningSecret: src.string("EVENT_SIGNING_SECRET", ""),
+		EventCompression:   src.string("EVENT_COMPRESSION", "none"),
+
+		SecretDigestKey: src.string("SECRET_DIGEST_KEY", ""),
+
+		K8sQPS:         src.float("K8S_QPS", 100),
+		K8sBurst:       int(src.int("K8S_BURST", 200)),
+		InformerResync: src.duration("INFORMER_RESYNC", 12*time.Hour),
+
+		K8sImpersonation:           src.bool("K8S_IMPERSONATION", false),
+		K8sImpersonationUserPrefix: src.string("K8S_IMPERSONATION_USER_PREFIX", ""),
+
+		TLSCertPath:     src.string("TLS_CERT_PATH", ""),
+		TLSKeyPath:      src.string("TLS_KEY_PATH", ""),
+		TLSClientCAPath: src.string("TLS_CLIENT_CA_PATH", ""),
+
+		TLSMinVersion:     src.tlsVersion("TLS_MIN_VERSION", tls.VersionTLS12),
+		TLSReloadInterval: src.duration("TLS_RELOAD_INTERVAL", 30*time.Second),
+
+		ConfigReloadInterval: src.duration("CONFIG_RELOAD_INTERVAL", 10*time.Second),
+
+		TrustedProxies: src.list("TRUSTED_PROXIES", nil),
+	}
+
+	if keyPath := src.string("JWT_PUBLIC_KEY_PATH", ""); keyPath != "" {
+		src.watch(keyPath)
+		key, err := readPublicKey(keyPath)
+		src.fail("JWT_PUBLIC_KEY_PATH", err)
+		cfg.JWTPublicKey = key
 	}
 
+	// Role bundles map names to permissions, e.g. {"viewer": ["*:view"], "admin": ["*"]}.
+	src.object("ROLES", &cfg.Roles)
+	// Limits per route group, e.g. {"default": {"requests": 600, "window": "1m"}}.
+	src.object("RATE_LIMITS", &cfg.RateLimits)
+	// Policies per namespace, e.g. {"payments": {"mode": "allowlist", "commands": ["ls"]}}.
+	src.object("EXEC_POLICIES", &cfg.ExecPolicies)
+	// Certificate mappings, e.g. [{"common_name": "ci-runner", "user_id": "svc:ci"}].
+	src.object("CLIENT_IDENTITIES", &cfg.ClientIdentities)
+	// Event rules, e.g. [{"action": "drop", "namespaces": ["kube-system"], "types": ["Normal"]}].
+	src.object("EVENT_FILTERS", &cfg.EventFilters)
+
+	// Parse errors leave defaults behind, so validation may repeat a problem,
+	// but reporting everything at once beats fixing one setting per restart.
+	if err := joinErrors(append(src.errors(), cfg.validate()...)); err != nil {
+		return nil, err
+	}
+
+	cfg.path = path
+	cfg.files = src.files
+	return cfg, nil
+}
+
+// readPublicKey loads an RSA, ECDSA or Ed25519 public key from a PEM file.
+func readPublicKey(keyPath string) (crypto.PublicKey, error) {
 	keyBytes, err := os.ReadFile(keyPath)
 	if err != nil {
-		log.Fatalf("Could not read public key file at %s: %v", keyPath, err)
+		return nil, fmt.Errorf("could not read public key file: %w", err)
+	}
+
+	block, _ := pem.Decode(keyBytes)
+	if block == nil {
+		return nil, fmt.Errorf("invalid public key at %s: no PEM data found", keyPath)
+	}
+
+	if pubKey, err := x509.ParsePKIXPublicKey(block.Bytes); err == nil {
+		return pubKey, nil
 	}
 
 	pubKey, err := jwt.ParseRSAPublicKeyFromPEM(keyBytes)
 	if err != nil {
-		log.Fatalf("Invalid RSA public key: %v", err)
+		return nil, fmt.Errorf("invalid public key at %s: %w", keyPath, err)
 	}
 
-	return pubKey
+	return pubKey, nil
 }
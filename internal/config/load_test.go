@@ -0,0 +1,210 @@
+package config
+
+import (
+	"maps"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad_Precedence(t *testing.T) {
+	type testCase struct {
+		name   string
+		file   string
+		env    map[string]string
+		verify func(t *testing.T, cfg *Config)
+	}
+
+	tests := []testCase{
+		{
+			name: "Defaults apply when neither file nor env set a value",
+			file: baseConfig,
+			verify: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, ":8080", cfg.ListenAddr)
+				assert.Equal(t, 100, cfg.EventBatchSize)
+				assert.Equal(t, []string{"http"}, cfg.EventSinks)
+				assert.Zero(t, cfg.EventAggregationWindow)
+			},
+		},
+		{
+			name: "File overrides defaults",
+			file: baseConfig + "listen_addr: :9090\nevent_batch_size: 50\njwt_leeway: 1m\n",
+			verify: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, ":9090", cfg.ListenAddr)
+				assert.Equal(t, 50, cfg.EventBatchSize)
+				assert.Equal(t, time.Minute, cfg.JWTLeeway)
+			},
+		},
+		{
+			name: "Env overrides the file",
+			file: baseConfig + "listen_addr: :9090\nevent_batch_size: 50\n",
+			env:  map[string]string{"LISTEN_ADDR": ":7070", "EVENT_BATCH_SIZE": "25"},
+			verify: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, ":7070", cfg.ListenAddr)
+				assert.Equal(t, 25, cfg.EventBatchSize)
+			},
+		},
+		{
+			name: "Empty env value falls back to the file",
+			file: baseConfig + "listen_addr: :9090\n",
+			env:  map[string]string{"LISTEN_ADDR": ""},
+			verify: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, ":9090", cfg.ListenAddr)
+			},
+		},
+		{
+			name: "File lists accept YAML sequences",
+			file: baseConfig + "event_sinks: [http, stdout]\n",
+			verify: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, []string{"http", "stdout"}, cfg.EventSinks)
+			},
+		},
+		{
+			name: "Env list overrides the file list",
+			file: baseConfig + "event_sinks: [http, stdout]\n",
+			env:  map[string]string{"EVENT_SINKS": "stdout"},
+			verify: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, []string{"stdout"}, cfg.EventSinks)
+			},
+		},
+		{
+			name: "Object file takes precedence over the inline object",
+			file: baseConfig + "roles:\n  viewer: [\"*:view\"]\n",
+			env:  map[string]string{"ROLES_PATH": "roles.yaml"},
+			verify: func(t *testing.T, cfg *Config) {
+				assert.Equal(t, []string{"admin"}, slices.Collect(maps.Keys(cfg.Roles)))
+				assert.Contains(t, cfg.files, "roles.yaml", "referenced files are watched for reloads")
+			},
+		},
+		{
+			name: "Inline object in the file",
+			file: baseConfig + "roles:\n  viewer: [\"*:view\"]\n",
+			verify: func(t *testing.T, cfg *Config) {
+				require.Len(t, cfg.Roles["viewer"], 1)
+				assert.Equal(t, "*:view", string(cfg.Roles["viewer"][0]))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Chdir(t.TempDir())
+			writeConfig(t, "roles.yaml", "admin: [\"*\"]\n")
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+
+			cfg, err := Load(configPath(t, tt.file))
+			require.NoError(t, err)
+			tt.verify(t, cfg)
+		})
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	type testCase struct {
+		name     string
+		file     string
+		env      map[string]string
+		expected []string
+	}
+
+	tests := []testCase{
+		{
+			name:     "Bad duration",
+			file:     baseConfig + "jwt_leeway: soon\n",
+			expected: []string{`jwt_leeway (JWT_LEEWAY): invalid value "soon", expected a duration`},
+		},
+		{
+			name:     "Bad duration from env",
+			file:     baseConfig,
+			env:      map[string]string{"TOPOLOGY_CACHE_TTL": "1 hour"},
+			expected: []string{`topology_cache_ttl (TOPOLOGY_CACHE_TTL): invalid value "1 hour"`},
+		},
+		{
+			name:     "Unknown file key",
+			file:     baseConfig + "listen_adr: :9090\n",
+			expected: []string{`unknown setting "listen_adr"`},
+		},
+		{
+			name:     "Negative batch size",
+			file:     baseConfig + "event_batch_size: -1\n",
+			expected: []string{"event_batch_size (EVENT_BATCH_SIZE)"},
+		},
+		{
+			name:     "Short secret digest key",
+			file:     baseConfig,
+			env:      map[string]string{"SECRET_DIGEST_KEY": "short"},
+			expected: []string{"secret_digest_key (SECRET_DIGEST_KEY) must be at least 32 characters"},
+		},
+		{
+			name:     "Missing key source",
+			file:     "api_url: https://api.example.com\n",
+			expected: []string{"one of jwt_public_key_path (JWT_PUBLIC_KEY_PATH), jwks_url (JWKS_URL) or jwks_path (JWKS_PATH) must be set"},
+		},
+		{
+			name:     "Unknown field in an object",
+			file:     baseConfig + "exec_policies:\n  payments:\n    mod: deny\n",
+			expected: []string{"exec_policies (EXEC_POLICIES)", `unknown field "mod"`},
+		},
+		{
+			name:     "Invalid event filter pattern",
+			file:     baseConfig + "event_filters:\n  - name: probes\n    action: drop\n    message: \"(Liveness\"\n",
+			expected: []string{"event_filters (EVENT_FILTERS): probes has an invalid message pattern"},
+		},
+		{
+			name:     "Invalid trusted proxy",
+			file:     baseConfig,
+			env:      map[string]string{"TRUSTED_PROXIES": "10.0.0.0/8,proxy.internal"},
+			expected: []string{`trusted_proxies (TRUSTED_PROXIES): "proxy.internal" is not an IP address or CIDR range`},
+		},
+		{
+			name:     "Impersonation without a user prefix",
+			file:     baseConfig + "k8s_impersonation: true\n",
+			expected: []string{"k8s_impersonation_user_prefix (K8S_IMPERSONATION_USER_PREFIX) is required by k8s_impersonation (K8S_IMPERSONATION)"},
+		},
+		{
+			name:     "Impersonation prefix in the system namespace",
+			file:     baseConfig + "k8s_impersonation: true\nk8s_impersonation_user_prefix: \"system:agent:\"\n",
+			expected: []string{`k8s_impersonation_user_prefix (K8S_IMPERSONATION_USER_PREFIX) must not start with "system:"`},
+		},
+		{
+			name:     "Unknown event filter action",
+			file:     baseConfig + "event_filters:\n  - action: skip\n",
+			expected: []string{`event_filters (EVENT_FILTERS): rule 1 has unknown action "skip"`},
+		},
+		{
+			name: "Every problem is reported at once",
+			file: baseConfig + "jwt_leeway: soon\nredis_db: -1\nlisten_adr: :9090\n",
+			expected: []string{
+				"jwt_leeway (JWT_LEEWAY)",
+				"redis_db (REDIS_DB) must not be negative",
+				`unknown setting "listen_adr"`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+
+			cfg, err := Load(configPath(t, tt.file))
+			require.Error(t, err)
+			assert.Nil(t, cfg)
+			for _, expected := range tt.expected {
+				assert.Contains(t, err.Error(), expected)
+			}
+		})
+	}
+}
+
+func TestLoad_MissingFile(t *testing.T) {
+	_, err := Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.ErrorContains(t, err, "could not read config file")
+}
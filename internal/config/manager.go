@@ -0,0 +1,254 @@
+package config
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// restartSettings are read once at startup by the listener, the clients and
+// the background workers. A reload keeps their original values and reports
+// them as requiring a restart.
+var restartSettings = []struct {
+	field string
+	env   string
+}{
+	{"ListenAddr", "LISTEN_ADDR"},
+	{"TrustedProxies", "TRUSTED_PROXIES"},
+	{"JWKSURL", "JWKS_URL"},
+	{"JWKSPath", "JWKS_PATH"},
+	{"JWKSRefreshInterval", "JWKS_REFRESH_INTERVAL"},
+	{"RedisAddr", "REDIS_ADDR"},
+	{"RedisPass", "REDIS_PASS"},
+	{"RedisDB", "REDIS_DB"},
+	{"TopologyCacheTTL", "TOPOLOGY_CACHE_TTL"},
+	{"AuditSinks", "AUDIT_SINKS"},
+	{"AuditFilePath", "AUDIT_FILE_PATH"},
+	{"AuditHTTPURL", "AUDIT_HTTP_URL"},
+	{"AuditStreamMaxLen", "AUDIT_STREAM_MAX_LEN"},
+	{"TLSCertPath", "TLS_CERT_PATH"},
+	{"TLSKeyPath", "TLS_KEY_PATH"},
+	{"TLSClientCAPath", "TLS_CLIENT_CA_PATH"},
+	{"TLSMinVersion", "TLS_MIN_VERSION"},
+	{"TLSReloadInterval", "TLS_RELOAD_INTERVAL"},
+	{"EventBatchSize", "EVENT_BATCH_SIZE"},
+	{"EventBatchMaxBytes", "EVENT_BATCH_MAX_BYTES"},
+	{"EventFlushInterval", "EVENT_FLUSH_INTERVAL"},
+	{"EventQueueSize", "EVENT_QUEUE_SIZE"},
+	{"EventSpoolMaxBatches", "EVENT_SPOOL_MAX_BATCHES"},
+	{"EventAggregationWindow", "EVENT_AGGREGATION_WINDOW"},
+	{"EventSinks", "EVENT_SINKS"},
+	{"EventStreamKey", "EVENT_STREAM_KEY"},
+	{"EventStreamMaxLen", "EVENT_STREAM_MAX_LEN"},
+	{"EventFilePath", "EVENT_FILE_PATH"},
+	{"EventFileMaxSize", "EVENT_FILE_MAX_SIZE"},
+	{"EventFileMaxBackups", "EVENT_FILE_MAX_BACKUPS"},
+	{"K8sQPS", "K8S_QPS"},
+	{"K8sBurst", "K8S_BURST"},
+	{"InformerResync", "INFORMER_RESYNC"},
+	{"K8sImpersonation", "K8S_IMPERSONATION"},
+	{"ConfigReloadInterval", "CONFIG_RELOAD_INTERVAL"},
+}
+
+// ReloadStatus describes the active configuration and the last reload attempt.
+type ReloadStatus struct {
+	// Version starts at 1 and grows with every applied reload.
+	Version int64 `json:"version"`
+	// Checksum covers the contents of Files, so agents reading the same
+	// files report the same checksum.
+	Checksum string    `json:"checksum"`
+	Files    []string  `json:"files"`
+	LoadedAt time.Time `json:"loaded_at"`
+	Trigger  string    `json:"trigger"`
+
+	LastAttempt time.Time `json:"last_attempt"`
+	LastError   string    `json:"last_error,omitempty"`
+	// RestartRequired names changed settings that keep their old value
+	// until the agent restarts.
+	RestartRequired []string `json:"restart_required,omitempty"`
+}
+
+// Manager holds the active configuration. It reloads it when the config file
+// or a file it references changes, or when the process receives SIGHUP.
+// Components that read settings per request take the Manager and call
+// Current, so a reload applies to the next request.
+type Manager struct {
+	initial *Config
+	current atomic.Pointer[Config]
+
+	mu       sync.Mutex
+	status   ReloadStatus
+	checksum string
+}
+
+func NewManager(cfg *Config) *Manager {
+	m := &Manager{
+		initial: cfg,
+	}
+	m.current.Store(cfg)
+
+	now := time.Now()
+	m.checksum = checksum(cfg.files)
+	m.status = ReloadStatus{
+		Version:     1,
+		Checksum:    m.checksum,
+		Files:       cfg.files,
+		LoadedAt:    now,
+		Trigger:     "startup",
+		LastAttempt: now,
+	}
+
+	return m
+}
+
+// Current returns the active configuration, which must not be modified.
+func (m *Manager) Current() *Config {
+	return m.current.Load()
+}
+
+func (m *Manager) Status() ReloadStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.status
+}
+
+// Reload loads the configuration again and swaps it in if it is valid. On
+// error the active configuration stays in place.
+func (m *Manager) Reload(trigger string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.status.LastAttempt = time.Now()
+
+	next, err := Load(m.initial.path)
+	if err != nil {
+		return m.reloadFailed(trigger, err)
+	}
+
+	// The kept settings are what actually runs, so the configuration is
+	// validated again with them, e.g. the http event sink still needs API_URL
+	// after EVENT_SINKS dropped it.
+	restart := keepRestartSettings(next, m.initial)
+	if err := next.Validate(); err != nil {
+		return m.reloadFailed(trigger, fmt.Errorf("with the settings that require a restart kept: %w", err))
+	}
+
+	m.current.Store(next)
+
+	m.checksum = checksum(next.files)
+	m.status = ReloadStatus{
+		Version:         m.status.Version + 1,
+		Checksum:        m.checksum,
+		Files:           next.files,
+		LoadedAt:        m.status.LastAttempt,
+		Trigger:         trigger,
+		LastAttempt:     m.status.LastAttempt,
+		RestartRequired: restart,
+	}
+
+	log.Printf("Config reloaded (%s): version %d, checksum %s", trigger, m.status.Version, m.checksum)
+	if len(restart) > 0 {
+		log.Printf("Config changes to %v take effect after a restart", restart)
+	}
+
+	return nil
+}
+
+func (m *Manager) reloadFailed(trigger string, err error) error {
+	m.status.LastError = err.Error()
+	log.Printf("Config reload (%s) failed, keeping version %d: %v", trigger, m.status.Version, err)
+	return err
+}
+
+// Run reloads on SIGHUP and, every ConfigReloadInterval, when the watched
+// files changed, until ctx is canceled.
+func (m *Manager) Run(ctx context.Context) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+
+	var poll <-chan time.Time
+	if interval := m.initial.ConfigReloadInterval; interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		poll = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hangup:
+			_ = m.Reload("SIGHUP")
+		case <-poll:
+			if m.changed() {
+				_ = m.Reload("file change")
+			}
+		}
+	}
+}
+
+// changed reports whether the watched files differ from the last time they
+// were seen. A failed reload also counts as seen, so a broken file is retried
+// once it changes again rather than on every tick.
+func (m *Manager) changed() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	sum := checksum(m.current.Load().files)
+	if sum == m.checksum {
+		return false
+	}
+
+	m.checksum = sum
+	return true
+}
+
+// keepRestartSettings copies the startup values of restartSettings into next
+// and returns the environment names of those that differ.
+func keepRestartSettings(next, initial *Config) []string {
+	var changed []string
+
+	nextValue := reflect.ValueOf(next).Elem()
+	initialValue := reflect.ValueOf(initial).Elem()
+	for _, setting := range restartSettings {
+		field := nextValue.FieldByName(setting.field)
+		original := initialValue.FieldByName(setting.field)
+
+		if !reflect.DeepEqual(field.Interface(), original.Interface()) {
+			changed = append(changed, setting.env)
+			field.Set(original)
+		}
+	}
+
+	return changed
+}
+
+// checksum hashes the names and contents of files. Unreadable files hash as
+// missing, so a file that reappears counts as a change.
+func checksum(files []string) string {
+	hash := sha256.New()
+	for _, file := range files {
+		hash.Write([]byte(file))
+		hash.Write([]byte{0})
+
+		if data, err := os.ReadFile(file); err == nil {
+			hash.Write(data)
+		} else {
+			hash.Write([]byte("\x00missing"))
+		}
+		hash.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,101 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRestartSettings_NameConfigFields(t *testing.T) {
+	configType := reflect.TypeOf(Config{})
+	for _, setting := range restartSettings {
+		_, ok := configType.FieldByName(setting.field)
+		assert.True(t, ok, "Config has no field %s", setting.field)
+	}
+}
+
+func TestManager_Reload(t *testing.T) {
+	path := configPath(t, baseConfig+"listen_addr: :9090\nevent_batch_size: 50\ncluster_id: prod\n")
+
+	cfg, err := Load(path)
+	require.NoError(t, err)
+	manager := NewManager(cfg)
+
+	status := manager.Status()
+	assert.Equal(t, int64(1), status.Version)
+	assert.Equal(t, "startup", status.Trigger)
+	assert.Equal(t, []string{path}, status.Files)
+
+	t.Run("Reloadable settings apply and restart-only settings are kept", func(t *testing.T) {
+		writeConfig(t, path, baseConfig+"listen_addr: :7070\nevent_batch_size: 10\ncluster_id: staging\n")
+		require.True(t, manager.changed())
+
+		require.NoError(t, manager.Reload("test"))
+
+		current := manager.Current()
+		assert.Equal(t, "staging", current.ClusterID)
+		assert.Equal(t, ":9090", current.ListenAddr)
+		assert.Equal(t, 50, current.EventBatchSize)
+
+		status := manager.Status()
+		assert.Equal(t, int64(2), status.Version)
+		assert.Equal(t, "test", status.Trigger)
+		assert.ElementsMatch(t, []string{"LISTEN_ADDR", "EVENT_BATCH_SIZE"}, status.RestartRequired)
+		assert.Empty(t, status.LastError)
+		assert.False(t, manager.changed())
+	})
+
+	t.Run("Invalid file keeps the active configuration", func(t *testing.T) {
+		previous := manager.Current()
+		writeConfig(t, path, baseConfig+"jwt_leeway: soon\n")
+
+		assert.Error(t, manager.Reload("test"))
+
+		assert.Same(t, previous, manager.Current())
+		status := manager.Status()
+		assert.Equal(t, int64(2), status.Version)
+		assert.Contains(t, status.LastError, "jwt_leeway (JWT_LEEWAY)")
+	})
+
+	t.Run("Reload is validated with the restart-only settings kept", func(t *testing.T) {
+		previous := manager.Current()
+		writeConfig(t, path, "jwks_url: https://auth.example.com/jwks.json\nevent_sinks: [stdout]\n")
+
+		err := manager.Reload("test")
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "with the settings that require a restart kept")
+		assert.Contains(t, err.Error(), "api_url (API_URL) is required by the http event sink")
+		assert.Same(t, previous, manager.Current())
+		assert.Equal(t, int64(2), manager.Status().Version)
+		assert.Equal(t, "https://api.example.com", manager.Current().ApiURL)
+	})
+
+	t.Run("Reverting restart-only settings clears the restart notice", func(t *testing.T) {
+		writeConfig(t, path, baseConfig+"listen_addr: :9090\nevent_batch_size: 50\ncluster_id: prod\n")
+
+		require.NoError(t, manager.Reload("test"))
+
+		status := manager.Status()
+		assert.Equal(t, int64(3), status.Version)
+		assert.Empty(t, status.RestartRequired)
+		assert.Empty(t, status.LastError)
+		assert.Equal(t, "prod", manager.Current().ClusterID)
+	})
+}
+
+func TestChecksum(t *testing.T) {
+	path := configPath(t, baseConfig)
+
+	before := checksum([]string{path})
+	assert.Equal(t, before, checksum([]string{path}))
+
+	writeConfig(t, path, baseConfig+"cluster_id: prod\n")
+	assert.NotEqual(t, before, checksum([]string{path}))
+
+	missing := checksum([]string{path + ".missing"})
+	writeConfig(t, path+".missing", "")
+	assert.NotEqual(t, missing, checksum([]string{path + ".missing"}))
+}
@@ -0,0 +1,273 @@
+package config
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+// source resolves settings from the environment first and the config file
+// second, collecting every problem so startup reports them all at once.
+type source struct {
+	path string
+	file map[string]json.RawMessage
+	used map[string]bool
+	errs []error
+
+	// files lists every file read, the config file included.
+	files []string
+}
+
+func newSource(path string) (*source, error) {
+	src := &source{
+		path: path,
+		used: make(map[string]bool),
+	}
+
+	if path == "" {
+		return src, nil
+	}
+
+	src.watch(path)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read config file: %w", err)
+	}
+
+	// YAML is converted to JSON, so values keep the types written in the file.
+	jsonData, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+
+	if len(bytes.TrimSpace(jsonData)) > 0 && string(jsonData) != "null" {
+		if err := json.Unmarshal(jsonData, &src.file); err != nil {
+			return nil, fmt.Errorf("invalid config file %s: top level must be a mapping: %w", path, err)
+		}
+	}
+
+	return src, nil
+}
+
+// lookup returns the raw setting. Environment values win over the file; an
+// empty environment value counts as unset.
+func (s *source) lookup(name string) (string, bool) {
+	key := fileKey(name)
+	raw, inFile := s.file[key]
+	s.used[key] = true
+
+	if value := os.Getenv(name); value != "" {
+		return value, true
+	}
+
+	if !inFile || string(raw) == "null" {
+		return "", false
+	}
+
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return text, true
+	}
+
+	var items []string
+	if err := json.Unmarshal(raw, &items); err == nil {
+		return strings.Join(items, ","), true
+	}
+
+	return string(raw), true
+}
+
+func (s *source) string(name string, fallback string) string {
+	if value, ok := s.lookup(name); ok {
+		return value
+	}
+
+	return fallback
+}
+
+func (s *source) duration(name string, fallback time.Duration) time.Duration {
+	value, ok := s.lookup(name)
+	if !ok {
+		return fallback
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		s.invalid(name, value, "a duration such as 30s or 5m")
+		return fallback
+	}
+
+	return duration
+}
+
+func (s *source) int(name string, fallback int64) int64 {
+	value, ok := s.lookup(name)
+	if !ok {
+		return fallback
+	}
+
+	number, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		s.invalid(name, value, "an integer")
+		return fallback
+	}
+
+	return number
+}
+
+func (s *source) float(name string, fallback float32) float32 {
+	value, ok := s.lookup(name)
+	if !ok {
+		return fallback
+	}
+
+	number, err := strconv.ParseFloat(value, 32)
+	if err != nil {
+		s.invalid(name, value, "a number")
+		return fallback
+	}
+
+	return float32(number)
+}
+
+func (s *source) bool(name string, fallback bool) bool {
+	value, ok := s.lookup(name)
+	if !ok {
+		return fallback
+	}
+
+	flag, err := strconv.ParseBool(value)
+	if err != nil {
+		s.invalid(name, value, "true or false")
+		return fallback
+	}
+
+	return flag
+}
+
+var tlsVersions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+func (s *source) tlsVersion(name string, fallback uint16) uint16 {
+	value, ok := s.lookup(name)
+	if !ok {
+		return fallback
+	}
+
+	version, ok := tlsVersions[value]
+	if !ok {
+		s.invalid(name, value, "1.2 or 1.3")
+		return fallback
+	}
+
+	return version
+}
+
+// list parses a comma separated list, ignoring blank items. Unlike the other
+// settings, an empty environment value clears the list.
+func (s *source) list(name string, fallback []string) []string {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		value, ok = s.lookup(name)
+	} else {
+		s.used[fileKey(name)] = true
+	}
+
+	if !ok {
+		return fallback
+	}
+
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+// object decodes a structured setting inlined in the config file, or from
+// the JSON or YAML file named by <name>_PATH, which takes precedence.
+func (s *source) object(name string, target any) {
+	pathName := name + "_PATH"
+	key := fileKey(name)
+	s.used[key] = true
+
+	if path, ok := s.lookup(pathName); ok {
+		s.watch(path)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			s.fail(pathName, err)
+			return
+		}
+
+		if err := yaml.UnmarshalStrict(data, target); err != nil {
+			s.fail(pathName, fmt.Errorf("invalid file %s: %w", path, err))
+		}
+		return
+	}
+
+	raw, ok := s.file[key]
+	if !ok {
+		return
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(target); err != nil {
+		s.fail(name, err)
+	}
+}
+
+func (s *source) watch(path string) {
+	s.files = append(s.files, path)
+}
+
+func (s *source) invalid(name, value, expected string) {
+	s.errs = append(s.errs, fmt.Errorf("%s: invalid value %q, expected %s", describe(name), value, expected))
+}
+
+func (s *source) fail(name string, err error) {
+	if err != nil {
+		s.errs = append(s.errs, fmt.Errorf("%s: %w", describe(name), err))
+	}
+}
+
+// errors returns parse errors and keys in the file that no setting reads,
+// which are usually typos.
+func (s *source) errors() []error {
+	var unknown []string
+	for key := range s.file {
+		if !s.used[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	slices.Sort(unknown)
+
+	errs := slices.Clone(s.errs)
+	for _, key := range unknown {
+		errs = append(errs, fmt.Errorf("unknown setting %q in %s", key, s.path))
+	}
+
+	return errs
+}
+
+func fileKey(name string) string {
+	return strings.ToLower(name)
+}
+
+// describe names a setting by both its file key and environment variable.
+func describe(name string) string {
+	return fileKey(name) + " (" + name + ")"
+}
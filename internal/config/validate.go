@@ -0,0 +1,281 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// minSigningSecretLength matches the 256-bit output of HMAC-SHA256.
+const minSigningSecretLength = 32
+
+var (
+	auditSinkNames    = []string{"file", "redis", "http"}
+	eventSinkNames    = []string{"http", "redis", "file", "stdout"}
+	eventCompressions = []string{"none", "gzip", "zstd"}
+)
+
+// Validate checks settings that parsed but cannot work, naming each setting
+// by its file key and environment variable.
+func (c *Config) Validate() error {
+	return joinErrors(c.validate())
+}
+
+func (c *Config) validate() []error {
+	var errs []error
+	fail := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if c.ApiURL == "" {
+		if slices.Contains(c.EventSinks, "http") {
+			fail("%s is required by the http event sink", describe("API_URL"))
+		}
+	} else if u, err := url.Parse(c.ApiURL); err != nil || u.Scheme == "" || u.Host == "" {
+		fail("%s: %q is not an absolute URL", describe("API_URL"), c.ApiURL)
+	}
+
+	if c.ListenAddr == "" {
+		fail("%s must not be empty", describe("LISTEN_ADDR"))
+	}
+
+	for _, proxy := range c.TrustedProxies {
+		if net.ParseIP(proxy) == nil {
+			if _, _, err := net.ParseCIDR(proxy); err != nil {
+				fail("%s: %q is not an IP address or CIDR range", describe("TRUSTED_PROXIES"), proxy)
+			}
+		}
+	}
+
+	if c.JWTPublicKey == nil && c.JWKSURL == "" && c.JWKSPath == "" {
+		fail("one of %s, %s or %s must be set", describe("JWT_PUBLIC_KEY_PATH"), describe("JWKS_URL"), describe("JWKS_PATH"))
+	}
+
+	if c.JWKSRefreshInterval < 0 {
+		fail("%s must not be negative", describe("JWKS_REFRESH_INTERVAL"))
+	}
+
+	if c.ConfigReloadInterval < 0 {
+		fail("%s must not be negative", describe("CONFIG_RELOAD_INTERVAL"))
+	}
+
+	if c.JWTLeeway < 0 {
+		fail("%s must not be negative", describe("JWT_LEEWAY"))
+	}
+
+	if c.RedisDB < 0 {
+		fail("%s must not be negative", describe("REDIS_DB"))
+	}
+
+	if c.TopologyCacheTTL <= 0 {
+		fail("%s must be positive", describe("TOPOLOGY_CACHE_TTL"))
+	}
+
+	for _, sink := range c.AuditSinks {
+		if !slices.Contains(auditSinkNames, sink) {
+			fail("%s: unknown sink %q, expected one of %v", describe("AUDIT_SINKS"), sink, auditSinkNames)
+		}
+	}
+
+	if slices.Contains(c.AuditSinks, "http") && c.AuditHTTPURL == "" {
+		fail("%s is required by the http audit sink", describe("AUDIT_HTTP_URL"))
+	}
+
+	if c.AuditStreamMaxLen <= 0 {
+		fail("%s must be positive", describe("AUDIT_STREAM_MAX_LEN"))
+	}
+
+	if c.EventBatchSize <= 0 {
+		fail("%s must be positive", describe("EVENT_BATCH_SIZE"))
+	}
+
+	if c.EventBatchMaxBytes <= 0 {
+		fail("%s must be positive", describe("EVENT_BATCH_MAX_BYTES"))
+	}
+
+	if c.EventFlushInterval <= 0 {
+		fail("%s must be positive", describe("EVENT_FLUSH_INTERVAL"))
+	}
+
+	if c.EventQueueSize < c.EventBatchSize {
+		fail("%s must be at least %s (%d)", describe("EVENT_QUEUE_SIZE"), describe("EVENT_BATCH_SIZE"), c.EventBatchSize)
+	}
+
+	if c.EventSpoolMaxBatches < 0 {
+		fail("%s must not be negative, use 0 to disable the spool", describe("EVENT_SPOOL_MAX_BATCHES"))
+	}
+
+	if len(c.EventSinks) == 0 {
+		fail("%s must name at least one of %v", describe("EVENT_SINKS"), eventSinkNames)
+	}
+
+	for i, sink := range c.EventSinks {
+		if !slices.Contains(eventSinkNames, sink) {
+			fail("%s: unknown sink %q, expected one of %v", describe("EVENT_SINKS"), sink, eventSinkNames)
+		} else if slices.Index(c.EventSinks, sink) != i {
+			fail("%s: sink %q is listed twice", describe("EVENT_SINKS"), sink)
+		}
+	}
+
+	if slices.Contains(c.EventSinks, "redis") && (c.EventStreamKey == "" || c.EventStreamMaxLen <= 0) {
+		fail("%s and a positive %s are required by the redis event sink", describe("EVENT_STREAM_KEY"), describe("EVENT_STREAM_MAX_LEN"))
+	}
+
+	if slices.Contains(c.EventSinks, "file") && (c.EventFilePath == "" || c.EventFileMaxSize <= 0 || c.EventFileMaxBackups < 0) {
+		fail("the file event sink requires %s, a positive %s and a non-negative %s",
+			describe("EVENT_FILE_PATH"), describe("EVENT_FILE_MAX_SIZE"), describe("EVENT_FILE_MAX_BACKUPS"))
+	}
+
+	if c.EventAuthToken != "" && !validHeaderName(c.EventAuthHeader) {
+		fail("%s: %q is not a valid header name", describe("EVENT_AUTH_HEADER"), c.EventAuthHeader)
+	}
+
+	if c.EventSigningSecret != "" && len(c.EventSigningSecret) < minSigningSecretLength {
+		fail("%s must be at least %d characters", describe("EVENT_SIGNING_SECRET"), minSigningSecretLength)
+	}
+
+	if c.SecretDigestKey != "" && len(c.SecretDigestKey) < minSigningSecretLength {
+		fail("%s must be at least %d characters", describe("SECRET_DIGEST_KEY"), minSigningSecretLength)
+	}
+
+	if !slices.Contains(eventCompressions, c.EventCompression) {
+		fail("%s: unknown compression %q, expected one of %v", describe("EVENT_COMPRESSION"), c.EventCompression, eventCompressions)
+	}
+
+	if c.EventAggregationWindow < 0 {
+		fail("%s must not be negative, use 0 to disable aggregation", describe("EVENT_AGGREGATION_WINDOW"))
+	}
+
+	if c.K8sQPS <= 0 {
+		fail("%s must be positive", describe("K8S_QPS"))
+	}
+
+	if float32(c.K8sBurst) < c.K8sQPS {
+		fail("%s must be at least %s (%g)", describe("K8S_BURST"), describe("K8S_QPS"), c.K8sQPS)
+	}
+
+	// Without a prefix a caller named "system:admin" or
+	// "system:serviceaccount:..." would be impersonated as that user.
+	if c.K8sImpersonation {
+		if c.K8sImpersonationUserPrefix == "" {
+			fail("%s is required by %s", describe("K8S_IMPERSONATION_USER_PREFIX"), describe("K8S_IMPERSONATION"))
+		} else if strings.HasPrefix(c.K8sImpersonationUserPrefix, "system:") {
+			fail("%s must not start with \"system:\", which Kubernetes reserves", describe("K8S_IMPERSONATION_USER_PREFIX"))
+		}
+	}
+
+	if c.InformerResync < 0 {
+		fail("%s must not be negative, use 0 to disable resyncs", describe("INFORMER_RESYNC"))
+	}
+
+	if (c.TLSCertPath == "") != (c.TLSKeyPath == "") {
+		fail("%s and %s must be set together", describe("TLS_CERT_PATH"), describe("TLS_KEY_PATH"))
+	}
+
+	if c.TLSReloadInterval < 0 {
+		fail("%s must not be negative, use 0 to disable reloads", describe("TLS_RELOAD_INTERVAL"))
+	}
+
+	if c.TLSClientCAPath != "" && c.TLSCertPath == "" {
+		fail("%s requires %s and %s", describe("TLS_CLIENT_CA_PATH"), describe("TLS_CERT_PATH"), describe("TLS_KEY_PATH"))
+	}
+
+	errs = append(errs, c.validateExecPolicies()...)
+	errs = append(errs, c.validateClientIdentities()...)
+	errs = append(errs, c.validateEventFilters()...)
+
+	return errs
+}
+
+func joinErrors(errs []error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("invalid configuration:\n%w", errors.Join(errs...))
+}
+
+func (c *Config) validateExecPolicies() []error {
+	namespaces := make([]string, 0, len(c.ExecPolicies))
+	for namespace := range c.ExecPolicies {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+
+	var errs []error
+	for _, namespace := range namespaces {
+		policy := c.ExecPolicies[namespace]
+
+		switch policy.Mode {
+		case ExecModeShell, ExecModeReadOnly, ExecModeDeny:
+		case ExecModeAllowlist:
+			if len(policy.Commands) == 0 {
+				errs = append(errs, fmt.Errorf("%s: policy for %q uses allowlist mode without commands", describe("EXEC_POLICIES"), namespace))
+			}
+		default:
+			errs = append(errs, fmt.Errorf("%s: policy for %q has unknown mode %q", describe("EXEC_POLICIES"), namespace, policy.Mode))
+		}
+	}
+
+	return errs
+}
+
+func (c *Config) validateClientIdentities() []error {
+	var errs []error
+	for i, identity := range c.ClientIdentities {
+		if identity.UserId == "" {
+			errs = append(errs, fmt.Errorf("%s: identity #%d has no user_id", describe("CLIENT_IDENTITIES"), i))
+		}
+
+		if identity.CommonName == "" && identity.DNSName == "" && identity.URI == "" {
+			errs = append(errs, fmt.Errorf("%s: identity #%d needs one of common_name, dns_name or uri", describe("CLIENT_IDENTITIES"), i))
+		}
+	}
+
+	return errs
+}
+
+func (c *Config) validateEventFilters() []error {
+	var errs []error
+	names := make(map[string]bool)
+
+	for i, rule := range c.EventFilters {
+		name := rule.RuleName(i)
+		if names[name] {
+			errs = append(errs, fmt.Errorf("%s: duplicate rule name %q", describe("EVENT_FILTERS"), name))
+		}
+		names[name] = true
+
+		if rule.Action != EventFilterKeep && rule.Action != EventFilterDrop {
+			errs = append(errs, fmt.Errorf("%s: %s has unknown action %q, expected keep or drop", describe("EVENT_FILTERS"), name, rule.Action))
+		}
+
+		if _, err := regexp.Compile(rule.Message); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %s has an invalid message pattern: %w", describe("EVENT_FILTERS"), name, err))
+		}
+	}
+
+	return errs
+}
+
+// validHeaderName accepts HTTP token characters only, which rules out
+// whitespace and separators that would corrupt the request.
+func validHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	for _, r := range name {
+		if r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("!#$%&'*+-.^_`|~", r)) {
+			return false
+		}
+	}
+
+	return true
+}
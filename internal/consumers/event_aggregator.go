@@ -0,0 +1,213 @@
+package consumers
+
+import (
+	"cluster-agent/internal/config"
+	"cluster-agent/internal/models"
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// maxAggregationGroups bounds memory during event storms. Events that
+	// would open a group beyond it are forwarded as they are.
+	maxAggregationGroups = 10000
+
+	// eventCountRetention is how long the last count of a Kubernetes event is
+	// remembered, comfortably above the API server's default event TTL.
+	eventCountRetention = 2 * time.Hour
+)
+
+type aggregationKey struct {
+	kind      string
+	namespace string
+	name      string
+	uid       types.UID
+	reason    string
+	message   string
+}
+
+type aggregate struct {
+	latest *corev1.Event
+	count  int32
+	first  time.Time
+	last   time.Time
+	opened time.Time
+}
+
+type observedCount struct {
+	count int32
+	last  time.Time
+	seen  time.Time
+}
+
+// EventAggregator sits between the collector and the batcher. Events with the
+// same involved object, reason and message within a window become a single
+// record: the latest event, with Count set to the occurrences in the window
+// and First/LastTimestamp spanning those occurrences only, not the history of
+// the Kubernetes event.
+type EventAggregator struct {
+	window  time.Duration
+	batcher *EventBatcher
+	stats   *EventStats
+
+	mu     sync.Mutex
+	groups map[aggregationKey]*aggregate
+	// counts remembers the Count of each Kubernetes event, so an update
+	// contributes only the occurrences added since the previous one.
+	counts map[types.UID]observedCount
+}
+
+func NewEventAggregator(cfg *config.Config, batcher *EventBatcher, stats *EventStats) *EventAggregator {
+	return &EventAggregator{
+		window:  cfg.EventAggregationWindow,
+		batcher: batcher,
+		stats:   stats,
+		groups:  make(map[aggregationKey]*aggregate),
+		counts:  make(map[types.UID]observedCount),
+	}
+}
+
+func (a *EventAggregator) Push(event *corev1.Event) {
+	a.stats.received.Add(1)
+
+	if a.window <= 0 {
+		a.batcher.Push(event)
+		return
+	}
+
+	now := time.Now()
+	key := aggregationKey{
+		kind:      event.InvolvedObject.Kind,
+		namespace: event.InvolvedObject.Namespace,
+		name:      event.InvolvedObject.Name,
+		uid:       event.InvolvedObject.UID,
+		reason:    event.Reason,
+		message:   event.Message,
+	}
+
+	a.mu.Lock()
+	occurrences, first, last := a.occurrences(event, now)
+	if occurrences == 0 {
+		// A resync or an update that did not repeat the event.
+		a.stats.aggregated.Add(1)
+		a.mu.Unlock()
+		return
+	}
+
+	group, ok := a.groups[key]
+	if !ok && len(a.groups) >= maxAggregationGroups {
+		a.mu.Unlock()
+		a.batcher.Push(event)
+		return
+	}
+
+	if ok {
+		a.stats.aggregated.Add(1)
+	} else {
+		group = &aggregate{opened: now}
+		a.groups[key] = group
+	}
+
+	if group.first.IsZero() || first.Before(group.first) {
+		group.first = first
+	}
+	if last.After(group.last) {
+		group.last = last
+	}
+	group.latest = event
+	group.count += occurrences
+	a.mu.Unlock()
+}
+
+// Run forwards each record once its window has passed, and everything still
+// open when ctx is canceled. It returns only after that final flush, also
+// when aggregation is disabled, so callers can stop the batcher afterwards.
+func (a *EventAggregator) Run(ctx context.Context) {
+	if a.window <= 0 {
+		<-ctx.Done()
+		return
+	}
+
+	ticker := time.NewTicker(min(a.window, time.Second))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			a.flush(time.Now(), false)
+
+		case <-ctx.Done():
+			a.flush(time.Now(), true)
+			return
+		}
+	}
+}
+
+func (a *EventAggregator) flush(now time.Time, all bool) {
+	var records []*corev1.Event
+
+	a.mu.Lock()
+	for key, group := range a.groups {
+		if all || now.Sub(group.opened) >= a.window {
+			records = append(records, group.record())
+			delete(a.groups, key)
+		}
+	}
+
+	for uid, observed := range a.counts {
+		if now.Sub(observed.seen) > eventCountRetention {
+			delete(a.counts, uid)
+		}
+	}
+	a.mu.Unlock()
+
+	if all && len(records) > 0 {
+		log.Printf("Forwarding %d aggregated events before shutdown", len(records))
+	}
+
+	for _, record := range records {
+		a.batcher.Push(record)
+	}
+}
+
+// occurrences returns how many times the event happened since it was last
+// seen, and when. Kubernetes raises Count on repeats instead of creating new
+// events, and keeps FirstTimestamp at the very first one, so an update's new
+// occurrences start after the LastTimestamp seen before; that is the closest
+// known bound, and exact when a single one was added.
+func (a *EventAggregator) occurrences(event *corev1.Event, now time.Time) (int32, time.Time, time.Time) {
+	count := max(event.Count, 1)
+	first, last := models.EventSpan(event)
+
+	previous, ok := a.counts[event.UID]
+	a.counts[event.UID] = observedCount{count: count, last: last, seen: now}
+
+	if !ok {
+		return count, first, last
+	}
+
+	added := max(count-previous.count, 0)
+	switch {
+	case added == 1:
+		first = last
+	case previous.last.After(first):
+		first = previous.last
+	}
+
+	return added, first, last
+}
+
+func (g *aggregate) record() *corev1.Event {
+	event := g.latest.DeepCopy()
+	event.Count = g.count
+	event.FirstTimestamp = metav1.NewTime(g.first)
+	event.LastTimestamp = metav1.NewTime(g.last)
+
+	return event
+}
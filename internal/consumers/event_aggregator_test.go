@@ -0,0 +1,226 @@
+package consumers
+
+import (
+	"cluster-agent/internal/models"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var aggregationStart = time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func backOffEvent(uid types.UID, count int32, last time.Time) *corev1.Event {
+	return &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{UID: uid},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "default", Name: "api", UID: "pod-1"},
+		Reason:         "BackOff",
+		Message:        "Back-off restarting failed container",
+		Count:          count,
+		FirstTimestamp: metav1.NewTime(aggregationStart),
+		LastTimestamp:  metav1.NewTime(last),
+	}
+}
+
+func queued(b *EventBatcher) []*models.ClusterEvent {
+	var events []*models.ClusterEvent
+	for {
+		select {
+		case event := <-b.eventsChan:
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+}
+
+func TestEventAggregator_Push(t *testing.T) {
+	type testCase struct {
+		name               string
+		window             time.Duration
+		events             []*corev1.Event
+		expectedCounts     []int32
+		expectedAggregated int64
+	}
+
+	tests := []testCase{
+		{
+			name:   "Repeats within the window become one record",
+			window: time.Minute,
+			events: []*corev1.Event{
+				backOffEvent("e1", 1, aggregationStart),
+				backOffEvent("e2", 1, aggregationStart.Add(time.Second)),
+				backOffEvent("e3", 1, aggregationStart.Add(2*time.Second)),
+			},
+			expectedCounts:     []int32{3},
+			expectedAggregated: 2,
+		},
+		{
+			name:   "Count updates add only new occurrences",
+			window: time.Minute,
+			events: []*corev1.Event{
+				backOffEvent("e1", 2, aggregationStart),
+				backOffEvent("e1", 5, aggregationStart.Add(time.Second)),
+			},
+			expectedCounts:     []int32{5},
+			expectedAggregated: 1,
+		},
+		{
+			name:   "Resyncs are not counted again",
+			window: time.Minute,
+			events: []*corev1.Event{
+				backOffEvent("e1", 2, aggregationStart),
+				backOffEvent("e1", 2, aggregationStart),
+			},
+			expectedCounts:     []int32{2},
+			expectedAggregated: 1,
+		},
+		{
+			name:   "Disabled window forwards every event",
+			window: 0,
+			events: []*corev1.Event{
+				backOffEvent("e1", 1, aggregationStart),
+				backOffEvent("e2", 1, aggregationStart),
+			},
+			expectedCounts: []int32{1, 1},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := testConfig()
+			cfg.EventAggregationWindow = tc.window
+
+			batcher := newTestBatcher(cfg, newMemorySpool())
+			aggregator := NewEventAggregator(cfg, batcher, batcher.stats)
+
+			for _, event := range tc.events {
+				aggregator.Push(event)
+			}
+			aggregator.flush(time.Now().Add(tc.window), false)
+
+			records := queued(batcher)
+			counts := make([]int32, 0, len(records))
+			for _, record := range records {
+				counts = append(counts, record.Count)
+			}
+
+			assert.Equal(t, tc.expectedCounts, counts)
+			assert.Equal(t, tc.expectedAggregated, batcher.stats.aggregated.Load())
+			assert.Equal(t, int64(len(tc.events)), batcher.stats.received.Load())
+		})
+	}
+}
+
+func TestEventAggregator_RecordSpansOccurrences(t *testing.T) {
+	cfg := testConfig()
+	cfg.EventAggregationWindow = time.Minute
+
+	batcher := newTestBatcher(cfg, newMemorySpool())
+	aggregator := NewEventAggregator(cfg, batcher, batcher.stats)
+
+	aggregator.Push(backOffEvent("e1", 1, aggregationStart.Add(time.Second)))
+	aggregator.Push(backOffEvent("e2", 1, aggregationStart.Add(5*time.Second)))
+
+	aggregator.flush(time.Now(), false)
+	assert.Empty(t, queued(batcher), "window has not passed")
+
+	aggregator.flush(time.Now().Add(time.Minute), false)
+	records := queued(batcher)
+	require.Len(t, records, 1)
+	assert.Equal(t, "e2", records[0].UID)
+	assert.Equal(t, aggregationStart, records[0].FirstTimestamp)
+	assert.Equal(t, aggregationStart.Add(5*time.Second), records[0].LastTimestamp)
+}
+
+// A long-running event is reported once per window, each record spanning only
+// the occurrences it counts, so counts add up and spans do not overlap.
+func TestEventAggregator_RecordsOfRepeatedEventDoNotOverlap(t *testing.T) {
+	cfg := testConfig()
+	cfg.EventAggregationWindow = time.Minute
+
+	batcher := newTestBatcher(cfg, newMemorySpool())
+	aggregator := NewEventAggregator(cfg, batcher, batcher.stats)
+
+	window := func(event *corev1.Event) *models.ClusterEvent {
+		t.Helper()
+		aggregator.Push(event)
+		aggregator.flush(time.Now().Add(time.Minute), false)
+
+		records := queued(batcher)
+		require.Len(t, records, 1)
+		return records[0]
+	}
+
+	first := window(backOffEvent("e1", 3, aggregationStart.Add(10*time.Second)))
+	assert.Equal(t, int32(3), first.Count)
+	assert.Equal(t, aggregationStart, first.FirstTimestamp, "history before the agent saw it is counted")
+	assert.Equal(t, aggregationStart.Add(10*time.Second), first.LastTimestamp)
+
+	single := window(backOffEvent("e1", 4, aggregationStart.Add(70*time.Second)))
+	assert.Equal(t, int32(1), single.Count)
+	assert.Equal(t, aggregationStart.Add(70*time.Second), single.FirstTimestamp)
+	assert.Equal(t, aggregationStart.Add(70*time.Second), single.LastTimestamp)
+
+	several := window(backOffEvent("e1", 6, aggregationStart.Add(130*time.Second)))
+	assert.Equal(t, int32(2), several.Count)
+	assert.Equal(t, aggregationStart.Add(70*time.Second), several.FirstTimestamp, "bounded by the previous last occurrence")
+	assert.Equal(t, aggregationStart.Add(130*time.Second), several.LastTimestamp)
+
+	assert.Equal(t, int32(6), first.Count+single.Count+several.Count)
+}
+
+// Open records are forwarded on shutdown before the batcher stops, and the
+// batcher spools them since the sinks can no longer send.
+func TestEventAggregator_ShutdownForwardsOpenRecords(t *testing.T) {
+	cfg := testConfig()
+	cfg.EventAggregationWindow = time.Hour
+
+	sink := &fakeSink{name: "http"}
+	spool := newMemorySpool()
+	batcher := newTestBatcher(cfg, spool, sink)
+	aggregator := NewEventAggregator(cfg, batcher, batcher.stats)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	batcherDone := make(chan struct{})
+	go func() {
+		batcher.Run(ctx)
+		close(batcherDone)
+	}()
+
+	aggregatorDone := make(chan struct{})
+	go func() {
+		aggregator.Run(ctx)
+		batcher.Close()
+		close(aggregatorDone)
+	}()
+
+	aggregator.Push(backOffEvent("e1", 1, aggregationStart))
+	aggregator.Push(backOffEvent("e2", 1, aggregationStart))
+	cancel()
+
+	<-aggregatorDone
+	select {
+	case <-batcherDone:
+	case <-time.After(2 * time.Second):
+		t.Fatal("batcher did not stop after Close")
+	}
+
+	var spooled []*models.ClusterEvent
+	for _, batch := range spool.pending("http") {
+		var events []*models.ClusterEvent
+		require.NoError(t, json.Unmarshal(batch.Payload, &events))
+		spooled = append(spooled, events...)
+	}
+
+	require.Len(t, spooled, 1)
+	assert.Equal(t, int32(2), spooled[0].Count)
+	assert.Empty(t, sink.received())
+}
@@ -1,161 +1,185 @@
 package consumers
 
 import (
-	"bytes"
 	"cluster-agent/internal/config"
+	"cluster-agent/internal/models"
 	"context"
 	"encoding/json"
-	"fmt"
 	corev1 "k8s.io/api/core/v1"
 	"log"
-	"math"
-	"net"
-	"net/http"
+	"sync"
 	"time"
 )
 
+// EventBatcher maps events to ClusterEvents, cuts them into batches and hands
+// each batch to every configured sink.
 type EventBatcher struct {
-	eventsChan chan *corev1.Event
-	buffer     []*corev1.Event
+	configs    *config.Manager
+	eventsChan chan *models.ClusterEvent
+	buffer     []*models.ClusterEvent
 	batchSize  int
 	interval   time.Duration
-	httpClient *http.Client
-	cfg        *config.Config
+	workers    []*sinkWorker
+	stats      *EventStats
+	closed     chan struct{}
+	closeOnce  sync.Once
+
+	// maxBytes bounds the JSON array of a batch. bufferBytes is the size of
+	// the buffer encoded that way.
+	maxBytes    int64
+	bufferBytes int64
 }
 
-func NewEventBatcher(cfg *config.Config) *EventBatcher {
-	t := &http.Transport{
-		DialContext: (&net.Dialer{
-			Timeout:   5 * time.Second,
-			KeepAlive: 30 * time.Second,
-		}).DialContext,
-		TLSHandshakeTimeout:   5 * time.Second,
-		ResponseHeaderTimeout: 10 * time.Second,
-		MaxIdleConns:          100,
-		MaxIdleConnsPerHost:   10,
-		IdleConnTimeout:       90 * time.Second,
-	}
+// NewEventBatcher sizes the queue and batches at startup.
+func NewEventBatcher(configs *config.Manager, sinks []EventSink, spool EventSpool, stats *EventStats) *EventBatcher {
+	cfg := configs.Current()
 
-	client := &http.Client{
-		Transport: t,
-		Timeout:   15 * time.Second,
+	workers := make([]*sinkWorker, 0, len(sinks))
+	for _, sink := range sinks {
+		workers = append(workers, newSinkWorker(sink, spool, stats, cfg.EventFlushInterval))
 	}
 
 	return &EventBatcher{
-		eventsChan: make(chan *corev1.Event, 1000),
-		buffer:     make([]*corev1.Event, 0, 100),
-		batchSize:  100,
-		interval:   5 * time.Second,
-		cfg:        cfg,
-		httpClient: client,
+		configs:    configs,
+		eventsChan: make(chan *models.ClusterEvent, cfg.EventQueueSize),
+		buffer:     make([]*models.ClusterEvent, 0, cfg.EventBatchSize),
+		batchSize:  cfg.EventBatchSize,
+		interval:   cfg.EventFlushInterval,
+		workers:    workers,
+		stats:      stats,
+		maxBytes:   cfg.EventBatchMaxBytes,
+		closed:     make(chan struct{}),
 	}
 }
 
 func (b *EventBatcher) Push(event *corev1.Event) {
-	// This data is TOO big
-	event.ManagedFields = nil
-
 	select {
-	case b.eventsChan <- event:
+	case b.eventsChan <- models.NewClusterEvent(event, b.configs.Current().ClusterID):
 	default:
+		b.stats.droppedQueueFull.Add(1)
 		log.Println("Event channel full, dropping event")
 	}
 }
 
+// Stats reports the pipeline counters and how many batches wait in each
+// sink's spool.
+func (b *EventBatcher) Stats(ctx context.Context) (*models.EventPipelineStats, error) {
+	stats := b.stats.snapshot()
+
+	for _, worker := range b.workers {
+		pending, err := worker.pendingBatches(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		sinkStats := stats.Sinks[worker.sink.Name()]
+		sinkStats.SpoolPending = pending
+		stats.Sinks[worker.sink.Name()] = sinkStats
+	}
+
+	return &stats, nil
+}
+
+// Close makes Run flush what is queued and return. Events pushed afterwards
+// are not delivered.
+func (b *EventBatcher) Close() {
+	b.closeOnce.Do(func() { close(b.closed) })
+}
+
+// Run batches events until Close is called. Sinks deliver on ctx; once it is
+// canceled, they spool what they could not send.
 func (b *EventBatcher) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, worker := range b.workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			worker.run(ctx)
+		}()
+	}
+
 	ticker := time.NewTicker(b.interval)
 	defer ticker.Stop()
 
 	for {
 		select {
 		case event := <-b.eventsChan:
-			b.buffer = append(b.buffer, event)
-			if len(b.buffer) >= b.batchSize {
-				b.flush(ctx)
-			}
+			b.add(event)
 
 		case <-ticker.C:
 			if len(b.buffer) > 0 {
-				b.flush(ctx)
+				b.flush()
 			}
 
-		case <-ctx.Done():
+		case <-b.closed:
+			// Workers spool what they cannot send on the canceled context,
+			// so queued events are delivered after the restart.
+			b.takeQueued()
 			if len(b.buffer) > 0 {
-				b.flush(ctx)
+				b.flush()
+			}
+
+			for _, worker := range b.workers {
+				close(worker.batches)
 			}
+			wg.Wait()
 			return
 		}
 	}
 }
 
-func (b *EventBatcher) flush(ctx context.Context) {
-	count := len(b.buffer)
-	log.Printf("Flushing %d events to Laravel...", count)
-
-	payload, err := json.Marshal(b.buffer)
-	if err != nil {
-		log.Printf("Failed to marshal events: %v", err)
-		b.buffer = b.buffer[:0]
-		return
-	}
-
-	const maxRetries = 3
-	var attempt int
-
-	for attempt = 0; attempt < maxRetries; attempt++ {
-		if ctx.Err() != nil {
-			log.Println("Flush canceled due to context")
-			return
-		}
-
-		err = b.sendRequest(ctx, payload)
-		if err == nil {
-			break
-		}
-
-		log.Printf("⚠Attempt %d/%d failed: %v", attempt+1, maxRetries, err)
-
-		backoff := time.Duration(math.Pow(2, float64(attempt))) * time.Second
-
+// takeQueued moves whatever is waiting in the queue into the buffer.
+func (b *EventBatcher) takeQueued() {
+	for {
 		select {
-		case <-time.After(backoff):
-		case <-ctx.Done():
+		case event := <-b.eventsChan:
+			b.add(event)
+		default:
 			return
 		}
 	}
-
-	if err != nil {
-		log.Printf("Failed to send events after %d attempts. Dropping batch.", maxRetries)
-	} else {
-		log.Printf("Successfully sent %d events", count)
-	}
-
-	b.buffer = b.buffer[:0]
 }
 
-func (b *EventBatcher) sendRequest(ctx context.Context, payload []byte) error {
-	req, err := http.NewRequestWithContext(ctx, "POST", b.cfg.ApiURL, bytes.NewBuffer(payload))
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+// add buffers an event, flushing first when it would push the batch past
+// maxBytes and afterwards when the batch is full. An event larger than
+// maxBytes on its own still goes out, as a batch of one.
+func (b *EventBatcher) add(event *models.ClusterEvent) {
+	size := encodedSize(event)
+
+	if len(b.buffer) > 0 && b.bufferBytes+size > b.maxBytes {
+		b.flush()
 	}
 
-	req.Header.Set("Content-Type", "application/json")
+	b.buffer = append(b.buffer, event)
+	b.bufferBytes += size
 
-	resp, err := b.httpClient.Do(req)
-	if err != nil {
-		return err
+	if len(b.buffer) >= b.batchSize {
+		b.flush()
 	}
+}
+
+// flush hands the buffer to every sink. Sinks share the batch, so it must not
+// be modified afterwards and the buffer starts over with a new array.
+func (b *EventBatcher) flush() {
+	batch := b.buffer
+	b.buffer = make([]*models.ClusterEvent, 0, b.batchSize)
+	b.bufferBytes = 0
 
-	defer resp.Body.Close()
+	log.Printf("Flushing %d events to %d sinks...", len(batch), len(b.workers))
 
-	if resp.StatusCode >= 500 {
-		return fmt.Errorf("server error: %d", resp.StatusCode)
+	for _, worker := range b.workers {
+		worker.enqueue(batch)
 	}
+}
 
-	if resp.StatusCode >= 400 {
-		log.Printf("Laravel rejected events: %d. Check payload or auth.", resp.StatusCode)
-		return nil
+// encodedSize is the space an event takes in a batch's JSON array, with one
+// delimiter. The sum over a batch is one byte short of the whole array.
+func encodedSize(event *models.ClusterEvent) int64 {
+	data, err := json.Marshal(event)
+	if err != nil {
+		// Sinks fail on it as well; it only needs to count towards a batch.
+		return 0
 	}
 
-	return nil
+	return int64(len(data)) + 1
 }
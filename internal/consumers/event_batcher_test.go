@@ -0,0 +1,136 @@
+package consumers
+
+import (
+	"cluster-agent/internal/models"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sizedEvent(uid string, messageLength int) *models.ClusterEvent {
+	return &models.ClusterEvent{UID: uid, Message: strings.Repeat("x", messageLength)}
+}
+
+func TestEncodedSize(t *testing.T) {
+	batch := []*models.ClusterEvent{sizedEvent("a", 10), sizedEvent("b", 500), sizedEvent("c", 0)}
+
+	var sum int64
+	for _, event := range batch {
+		sum += encodedSize(event)
+	}
+
+	encoded, err := json.Marshal(batch)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(encoded)), sum+1)
+}
+
+func TestEventBatcher_Add(t *testing.T) {
+	type testCase struct {
+		name            string
+		batchSize       int
+		maxBytes        func(event int64) int64
+		events          []*models.ClusterEvent
+		expectedBatches [][]string
+		expectedBuffer  []string
+	}
+
+	tests := []testCase{
+		{
+			name:            "Cut by count",
+			batchSize:       2,
+			maxBytes:        func(int64) int64 { return 1 << 20 },
+			events:          []*models.ClusterEvent{sizedEvent("a", 10), sizedEvent("b", 10), sizedEvent("c", 10)},
+			expectedBatches: [][]string{{"a", "b"}},
+			expectedBuffer:  []string{"c"},
+		},
+		{
+			name:            "Cut by size before the event that does not fit",
+			batchSize:       100,
+			maxBytes:        func(event int64) int64 { return 2*event + event/2 },
+			events:          []*models.ClusterEvent{sizedEvent("a", 10), sizedEvent("b", 10), sizedEvent("c", 10), sizedEvent("d", 10)},
+			expectedBatches: [][]string{{"a", "b"}},
+			expectedBuffer:  []string{"c", "d"},
+		},
+		{
+			name:            "Oversize event goes alone",
+			batchSize:       100,
+			maxBytes:        func(event int64) int64 { return event },
+			events:          []*models.ClusterEvent{sizedEvent("a", 10), sizedEvent("big", 1000), sizedEvent("b", 10)},
+			expectedBatches: [][]string{{"a"}, {"big"}},
+			expectedBuffer:  []string{"b"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := testConfig()
+			cfg.EventBatchSize = tc.batchSize
+			cfg.EventBatchMaxBytes = tc.maxBytes(encodedSize(sizedEvent("a", 10)))
+
+			sink := &fakeSink{name: "http"}
+			batcher := newTestBatcher(cfg, newMemorySpool(), sink)
+
+			for _, event := range tc.events {
+				batcher.add(event)
+			}
+
+			var batches [][]string
+			close(batcher.workers[0].batches)
+			for batch := range batcher.workers[0].batches {
+				batches = append(batches, uids(batch))
+			}
+
+			assert.Equal(t, tc.expectedBatches, batches)
+			assert.Equal(t, tc.expectedBuffer, uids(batcher.buffer))
+		})
+	}
+}
+
+// Every sink receives every batch, and a failing sink spools on its own
+// without holding back the others.
+func TestEventBatcher_FanOut(t *testing.T) {
+	cfg := testConfig()
+	cfg.EventBatchSize = 2
+
+	healthy := &fakeSink{name: "file"}
+	failing := &fakeSink{name: "http", fail: func([]*models.ClusterEvent) error { return errUnavailable }}
+	spool := newMemorySpool()
+	batcher := newTestBatcher(cfg, spool, healthy, failing)
+	for _, worker := range batcher.workers {
+		worker.backoff = time.Millisecond
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		batcher.Run(ctx)
+		close(done)
+	}()
+
+	for _, uid := range []string{"a", "b", "c"} {
+		batcher.eventsChan <- &models.ClusterEvent{UID: uid}
+	}
+
+	require.Eventually(t, func() bool {
+		return len(uids(healthy.received()...)) == 3 && len(spool.pending("http")) == 2
+	}, 2*time.Second, 5*time.Millisecond)
+
+	batcher.Close()
+	<-done
+
+	assert.Equal(t, []string{"a", "b", "c"}, uids(healthy.received()...))
+	assert.Equal(t, []string{"a", "b", "c"}, spooledUIDs(t, spool, "http"))
+
+	stats, err := batcher.Stats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), stats.Sinks["file"].Sent)
+	assert.Equal(t, int64(3), stats.Sinks["http"].Spooled)
+	assert.Equal(t, int64(2), stats.Sinks["http"].SpoolPending)
+}
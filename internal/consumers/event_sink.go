@@ -0,0 +1,344 @@
+package consumers
+
+import (
+	"cluster-agent/internal/models"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	maxRetries = 3
+
+	// retryBackoff doubles after each failed attempt.
+	retryBackoff = time.Second
+
+	// sinkQueueSize is how many batches may wait for a sink that is still
+	// busy retrying, before further batches go straight to the spool.
+	sinkQueueSize = 16
+
+	// spoolDrainBatches caps how many spooled batches are resent per tick,
+	// so draining a long outage does not stall new events.
+	spoolDrainBatches = 20
+	spoolTimeout      = 5 * time.Second
+)
+
+var errSpoolDisabled = errors.New("event spool disabled")
+
+// RejectedError is returned by sinks whose destination refused some events
+// of a batch for good, e.g. as invalid. The rest of the batch was delivered,
+// and neither retrying nor spooling can help.
+type RejectedError struct {
+	Events int
+	Err    error
+}
+
+func (e *RejectedError) Error() string {
+	return fmt.Sprintf("%d events rejected: %v", e.Events, e.Err)
+}
+
+func (e *RejectedError) Unwrap() error {
+	return e.Err
+}
+
+// PartialError is returned by sinks that delivered part of a batch before
+// failing. Only Remaining is retried and spooled; Rejected events were
+// refused for good like in a RejectedError.
+type PartialError struct {
+	Remaining []*models.ClusterEvent
+	Rejected  int
+	Err       error
+}
+
+func (e *PartialError) Error() string {
+	return fmt.Sprintf("%d events left undelivered: %v", len(e.Remaining), e.Err)
+}
+
+func (e *PartialError) Unwrap() error {
+	return e.Err
+}
+
+// EventSink delivers batches of events to one destination. Write is only
+// called by the sink's own worker, one batch at a time.
+type EventSink interface {
+	Name() string
+	Write(ctx context.Context, events []*models.ClusterEvent) error
+}
+
+// EventSpool stores batches a sink could not take, oldest first, separately
+// for each sink.
+type EventSpool interface {
+	Enabled() bool
+	// AppendBatch returns how many events were discarded to stay within the
+	// size limit.
+	AppendBatch(ctx context.Context, sink string, events int, payload []byte) (int64, error)
+	// OldestBatch returns nil when the spool is empty.
+	OldestBatch(ctx context.Context, sink string) (*models.SpooledEventBatch, error)
+	RemoveBatch(ctx context.Context, sink string, id string) error
+	PendingBatches(ctx context.Context, sink string) (int64, error)
+}
+
+// sinkWorker delivers batches to one sink with its own retries and spool, so
+// a slow or failing sink does not hold back the others.
+type sinkWorker struct {
+	sink     EventSink
+	spool    EventSpool
+	stats    *sinkStats
+	interval time.Duration
+	backoff  time.Duration
+	batches  chan []*models.ClusterEvent
+
+	// spoolPending is set while the spool holds batches. New batches then
+	// queue behind them, so the sink receives events in order. enqueue sets
+	// it as well when it spools an overflowing batch.
+	spoolPending atomic.Bool
+}
+
+func newSinkWorker(sink EventSink, spool EventSpool, stats *EventStats, interval time.Duration) *sinkWorker {
+	return &sinkWorker{
+		sink:     sink,
+		spool:    spool,
+		stats:    stats.sink(sink.Name()),
+		interval: interval,
+		backoff:  retryBackoff,
+		batches:  make(chan []*models.ClusterEvent, sinkQueueSize),
+	}
+}
+
+// enqueue hands a batch to the worker. While the worker is stuck retrying an
+// unreachable sink, the queue fills up and further batches are spooled right
+// away, ahead of the queued ones, which only affects their order.
+func (w *sinkWorker) enqueue(batch []*models.ClusterEvent) {
+	select {
+	case w.batches <- batch:
+		return
+	default:
+	}
+
+	err := w.spoolBatch(batch)
+	if err == nil {
+		return
+	}
+
+	w.stats.droppedQueueFull.Add(int64(len(batch)))
+	log.Printf("Event sink %s is falling behind, dropping %d events: %v", w.sink.Name(), len(batch), err)
+}
+
+// run delivers batches until the queue is closed. Batches still queued at
+// shutdown fail on the canceled ctx and go to the spool.
+func (w *sinkWorker) run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	w.spoolPending.Store(w.hasSpooledBatches(ctx))
+
+	for {
+		select {
+		case batch, ok := <-w.batches:
+			if !ok {
+				return
+			}
+			w.deliver(ctx, batch)
+
+		case <-ticker.C:
+			w.drain(ctx)
+		}
+	}
+}
+
+func (w *sinkWorker) deliver(ctx context.Context, batch []*models.ClusterEvent) {
+	if w.spoolPending.Load() {
+		err := w.spoolBatch(batch)
+		if err == nil {
+			return
+		}
+		log.Printf("Failed to spool events for %s, sending them directly: %v", w.sink.Name(), err)
+	}
+
+	batch, err := w.send(ctx, batch)
+	count := len(batch)
+
+	var rejected *RejectedError
+	if errors.As(err, &rejected) {
+		w.reject(rejected)
+		w.stats.sent.Add(int64(count - rejected.Events))
+		return
+	}
+
+	if err != nil {
+		log.Printf("Failed to send events to %s: %v", w.sink.Name(), err)
+
+		if err := w.spoolBatch(batch); err != nil {
+			log.Printf("Dropping %d events for %s: %v", count, w.sink.Name(), err)
+			w.stats.droppedUndeliverable.Add(int64(count))
+		}
+		return
+	}
+
+	w.stats.sent.Add(int64(count))
+}
+
+// send retries the batch and returns what is still to be delivered, which
+// shrinks when the sink takes part of it.
+func (w *sinkWorker) send(ctx context.Context, batch []*models.ClusterEvent) ([]*models.ClusterEvent, error) {
+	var err error
+
+	for attempt := 0; attempt < maxRetries; attempt++ {
+		if ctx.Err() != nil {
+			return batch, ctx.Err()
+		}
+
+		err = w.sink.Write(ctx, batch)
+
+		var partial *PartialError
+		if errors.As(err, &partial) {
+			batch = w.partial(batch, partial)
+			err = partial.Err
+		}
+
+		var rejected *RejectedError
+		if err == nil || errors.As(err, &rejected) {
+			return batch, err
+		}
+
+		log.Printf("⚠Attempt %d/%d for %s failed: %v", attempt+1, maxRetries, w.sink.Name(), err)
+
+		backoff := time.Duration(math.Pow(2, float64(attempt))) * w.backoff
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return batch, ctx.Err()
+		}
+	}
+
+	return batch, fmt.Errorf("giving up after %d attempts: %w", maxRetries, err)
+}
+
+// partial counts what the sink took of a batch and returns the rest.
+func (w *sinkWorker) partial(batch []*models.ClusterEvent, partial *PartialError) []*models.ClusterEvent {
+	if partial.Rejected > 0 {
+		w.reject(&RejectedError{Events: partial.Rejected, Err: partial.Err})
+	}
+	w.stats.sent.Add(int64(len(batch) - len(partial.Remaining) - partial.Rejected))
+
+	return partial.Remaining
+}
+
+// spoolBatch keeps a batch the sink could not take. It uses its own timeout,
+// so batches are still saved while the agent shuts down.
+func (w *sinkWorker) spoolBatch(batch []*models.ClusterEvent) error {
+	if !w.spool.Enabled() {
+		return errSpoolDisabled
+	}
+
+	payload, err := json.Marshal(batch)
+	if err != nil {
+		return fmt.Errorf("failed to marshal events: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), spoolTimeout)
+	defer cancel()
+
+	overflow, err := w.spool.AppendBatch(ctx, w.sink.Name(), len(batch), payload)
+	if err != nil {
+		return err
+	}
+
+	w.spoolPending.Store(true)
+	w.stats.spooled.Add(int64(len(batch)))
+
+	if overflow > 0 {
+		w.stats.spoolOverflow.Add(overflow)
+		log.Printf("Event spool for %s full, discarded the %d oldest events", w.sink.Name(), overflow)
+	}
+
+	return nil
+}
+
+// drain resends spooled batches oldest first. It stops at the first failure,
+// so a batch is never delivered ahead of an older one. A batch the sink took
+// only part of stays whole in the spool, as respooling the rest would put it
+// behind newer batches; its delivered part is sent again later.
+func (w *sinkWorker) drain(ctx context.Context) {
+	if !w.spoolPending.Load() {
+		return
+	}
+
+	for i := 0; i < spoolDrainBatches; i++ {
+		spooled, err := w.spool.OldestBatch(ctx, w.sink.Name())
+		if err != nil {
+			log.Printf("Failed to read event spool for %s: %v", w.sink.Name(), err)
+			return
+		}
+
+		if spooled == nil {
+			w.spoolPending.Store(false)
+
+			// enqueue may have spooled an overflowing batch since the read.
+			if pending, err := w.pendingBatches(ctx); err != nil || pending > 0 {
+				w.spoolPending.Store(true)
+				return
+			}
+
+			log.Printf("Event spool for %s drained", w.sink.Name())
+			return
+		}
+
+		var batch []*models.ClusterEvent
+		var rejected *RejectedError
+		if err := json.Unmarshal(spooled.Payload, &batch); err != nil {
+			// Retrying cannot fix it, and leaving it would block the spool.
+			log.Printf("Discarding unreadable spooled batch for %s: %v", w.sink.Name(), err)
+			w.stats.droppedUndeliverable.Add(int64(spooled.Events))
+		} else if err := w.sink.Write(ctx, batch); errors.As(err, &rejected) {
+			w.reject(rejected)
+			w.stats.drained.Add(int64(spooled.Events - rejected.Events))
+		} else if err != nil {
+			log.Printf("Event sink %s still unavailable, keeping spooled events: %v", w.sink.Name(), err)
+			return
+		} else {
+			w.stats.drained.Add(int64(spooled.Events))
+		}
+
+		// A failed removal resends the batch later; duplicates are preferred
+		// over losing events.
+		if err := w.spool.RemoveBatch(ctx, w.sink.Name(), spooled.Id); err != nil {
+			log.Printf("Failed to remove delivered batch from the spool for %s: %v", w.sink.Name(), err)
+			return
+		}
+	}
+}
+
+func (w *sinkWorker) reject(rejected *RejectedError) {
+	log.Printf("Event sink %s rejected %d events, dropping them: %v", w.sink.Name(), rejected.Events, rejected.Err)
+	w.stats.rejected.Add(int64(rejected.Events))
+}
+
+func (w *sinkWorker) hasSpooledBatches(ctx context.Context) bool {
+	pending, err := w.pendingBatches(ctx)
+	if err != nil {
+		// Leftovers are delivered with the next batch that needs the spool.
+		log.Printf("Failed to read event spool for %s: %v", w.sink.Name(), err)
+		return false
+	}
+
+	if pending > 0 {
+		log.Printf("Found %d spooled event batches for %s, delivering them first", pending, w.sink.Name())
+	}
+
+	return pending > 0
+}
+
+func (w *sinkWorker) pendingBatches(ctx context.Context) (int64, error) {
+	if !w.spool.Enabled() {
+		return 0, nil
+	}
+
+	return w.spool.PendingBatches(ctx, w.sink.Name())
+}
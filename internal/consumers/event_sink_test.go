@@ -0,0 +1,278 @@
+package consumers
+
+import (
+	"cluster-agent/internal/models"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errUnavailable = errors.New("sink unavailable")
+
+func eventBatch(uids ...string) []*models.ClusterEvent {
+	batch := make([]*models.ClusterEvent, 0, len(uids))
+	for _, uid := range uids {
+		batch = append(batch, &models.ClusterEvent{UID: uid, Count: 1})
+	}
+	return batch
+}
+
+func uids(batches ...[]*models.ClusterEvent) []string {
+	var result []string
+	for _, batch := range batches {
+		for _, event := range batch {
+			result = append(result, event.UID)
+		}
+	}
+	return result
+}
+
+func spooledUIDs(t *testing.T, spool *memorySpool, sink string) []string {
+	var result []string
+	for _, batch := range spool.pending(sink) {
+		var events []*models.ClusterEvent
+		require.NoError(t, json.Unmarshal(batch.Payload, &events))
+		result = append(result, uids(events)...)
+	}
+	return result
+}
+
+func newTestWorker(sink *fakeSink, spool EventSpool) (*sinkWorker, *EventStats) {
+	stats := NewEventStats()
+	worker := newSinkWorker(sink, spool, stats, time.Hour)
+	worker.backoff = time.Millisecond
+	return worker, stats
+}
+
+func TestSinkWorker_Deliver(t *testing.T) {
+	type testCase struct {
+		name            string
+		fail            func(batch []*models.ClusterEvent) error
+		spoolDisabled   bool
+		expectedWrites  int
+		expectedSent    int64
+		expectedSpooled []string
+		expectedStats   models.EventSinkStats
+	}
+
+	tests := []testCase{
+		{
+			name:           "Delivered",
+			expectedWrites: 1,
+			expectedStats:  models.EventSinkStats{Sent: 2},
+		},
+		{
+			name:            "Retried then spooled",
+			fail:            func([]*models.ClusterEvent) error { return errUnavailable },
+			expectedWrites:  maxRetries,
+			expectedSpooled: []string{"a", "b"},
+			expectedStats:   models.EventSinkStats{Spooled: 2},
+		},
+		{
+			name:           "Dropped without a spool",
+			fail:           func([]*models.ClusterEvent) error { return errUnavailable },
+			spoolDisabled:  true,
+			expectedWrites: maxRetries,
+			expectedStats:  models.EventSinkStats{DroppedUndeliverable: 2},
+		},
+		{
+			name: "Rejected events are neither retried nor spooled",
+			fail: func([]*models.ClusterEvent) error {
+				return &RejectedError{Events: 1, Err: errors.New("invalid")}
+			},
+			expectedWrites: 1,
+			expectedStats:  models.EventSinkStats{Sent: 1, Rejected: 1},
+		},
+		{
+			name: "Only the undelivered part is retried",
+			fail: func(batch []*models.ClusterEvent) error {
+				if len(batch) == 2 {
+					return &PartialError{Remaining: batch[1:], Err: errUnavailable}
+				}
+				return nil
+			},
+			expectedWrites: 2,
+			expectedStats:  models.EventSinkStats{Sent: 2},
+		},
+		{
+			name: "Only the undelivered part is spooled",
+			fail: func(batch []*models.ClusterEvent) error {
+				if len(batch) == 2 {
+					return &PartialError{Remaining: batch[1:], Err: errUnavailable}
+				}
+				return errUnavailable
+			},
+			expectedWrites:  maxRetries,
+			expectedSpooled: []string{"b"},
+			expectedStats:   models.EventSinkStats{Sent: 1, Spooled: 1},
+		},
+		{
+			name: "Rejections in a partial delivery are counted",
+			fail: func(batch []*models.ClusterEvent) error {
+				if len(batch) == 2 {
+					return &PartialError{Remaining: batch[1:], Rejected: 1, Err: errUnavailable}
+				}
+				return nil
+			},
+			expectedWrites: 2,
+			expectedStats:  models.EventSinkStats{Sent: 1, Rejected: 1},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			sink := &fakeSink{name: "http", fail: tc.fail}
+			spool := newMemorySpool()
+			spool.disabled = tc.spoolDisabled
+			worker, stats := newTestWorker(sink, spool)
+
+			worker.deliver(context.Background(), eventBatch("a", "b"))
+
+			assert.Equal(t, tc.expectedWrites, sink.writes)
+			assert.Equal(t, tc.expectedSpooled, spooledUIDs(t, spool, "http"))
+			assert.Equal(t, tc.expectedStats, stats.snapshot().Sinks["http"])
+		})
+	}
+}
+
+// Once a batch is spooled, later batches queue behind it and the spool is
+// drained oldest first, so the sink sees events in order.
+func TestSinkWorker_DrainKeepsOrder(t *testing.T) {
+	available := false
+	sink := &fakeSink{name: "http", fail: func([]*models.ClusterEvent) error {
+		if !available {
+			return errUnavailable
+		}
+		return nil
+	}}
+	spool := newMemorySpool()
+	worker, stats := newTestWorker(sink, spool)
+	ctx := context.Background()
+
+	worker.deliver(ctx, eventBatch("a"))
+	worker.deliver(ctx, eventBatch("b"))
+	worker.deliver(ctx, eventBatch("c"))
+
+	assert.Equal(t, maxRetries, sink.writes, "batches behind the spool are not sent directly")
+	assert.Equal(t, []string{"a", "b", "c"}, spooledUIDs(t, spool, "http"))
+
+	worker.drain(ctx)
+	assert.Equal(t, []string{"a", "b", "c"}, spooledUIDs(t, spool, "http"), "failed drain keeps the spool")
+
+	available = true
+	worker.drain(ctx)
+
+	assert.Equal(t, []string{"a", "b", "c"}, uids(sink.received()...))
+	assert.Empty(t, spool.pending("http"))
+	assert.False(t, worker.spoolPending.Load())
+
+	worker.deliver(ctx, eventBatch("d"))
+	assert.Equal(t, []string{"a", "b", "c", "d"}, uids(sink.received()...))
+	assert.Equal(t, models.EventSinkStats{Sent: 1, Spooled: 3, Drained: 3}, stats.snapshot().Sinks["http"])
+}
+
+func TestSinkWorker_DrainSkipsUnusableBatches(t *testing.T) {
+	sink := &fakeSink{name: "http", fail: func(batch []*models.ClusterEvent) error {
+		if batch[0].UID == "invalid" {
+			return &RejectedError{Events: 1, Err: errors.New("invalid")}
+		}
+		return nil
+	}}
+	spool := newMemorySpool()
+	worker, stats := newTestWorker(sink, spool)
+	ctx := context.Background()
+
+	spool.AppendBatch(ctx, "http", 2, []byte("not json"))
+	payload, _ := json.Marshal(eventBatch("invalid"))
+	spool.AppendBatch(ctx, "http", 1, payload)
+	payload, _ = json.Marshal(eventBatch("a"))
+	spool.AppendBatch(ctx, "http", 1, payload)
+
+	worker.spoolPending.Store(true)
+	worker.drain(ctx)
+
+	assert.Equal(t, []string{"a"}, uids(sink.received()...))
+	assert.Empty(t, spool.pending("http"))
+	assert.Equal(t, models.EventSinkStats{Drained: 1, Rejected: 1, DroppedUndeliverable: 2}, stats.snapshot().Sinks["http"])
+}
+
+func TestSinkWorker_EnqueueSpoolsWhenFull(t *testing.T) {
+	spool := newMemorySpool()
+	worker, stats := newTestWorker(&fakeSink{name: "http"}, spool)
+
+	for i := 0; i < sinkQueueSize+1; i++ {
+		worker.enqueue(eventBatch("a", "b"))
+	}
+
+	assert.Len(t, spool.pending("http"), 1)
+	assert.True(t, worker.spoolPending.Load())
+	assert.Equal(t, models.EventSinkStats{Spooled: 2}, stats.snapshot().Sinks["http"])
+}
+
+func TestSinkWorker_EnqueueDropsWhenFullWithoutSpool(t *testing.T) {
+	spool := newMemorySpool()
+	spool.disabled = true
+	worker, stats := newTestWorker(&fakeSink{name: "http"}, spool)
+
+	for i := 0; i < sinkQueueSize+1; i++ {
+		worker.enqueue(eventBatch("a", "b"))
+	}
+
+	assert.Equal(t, int64(2), stats.snapshot().Sinks["http"].DroppedQueueFull)
+}
+
+// A sink that hangs in Write, like an unreachable backend waiting out its
+// timeouts, fills the queue; the batches behind it must reach the spool
+// instead of being dropped, and are delivered once the sink recovers.
+func TestSinkWorker_BlockingSinkSpoolsOverflow(t *testing.T) {
+	unblock := make(chan struct{})
+	sink := &fakeSink{name: "http", fail: func([]*models.ClusterEvent) error {
+		<-unblock
+		return nil
+	}}
+	spool := newMemorySpool()
+	stats := NewEventStats()
+	worker := newSinkWorker(sink, spool, stats, 10*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		worker.run(ctx)
+		close(done)
+	}()
+
+	worker.enqueue(eventBatch("blocked"))
+	assert.Eventually(t, func() bool {
+		return len(worker.batches) == 0
+	}, time.Second, time.Millisecond, "worker picks up the first batch")
+
+	for i := 0; i < sinkQueueSize; i++ {
+		worker.enqueue(eventBatch("queued"))
+	}
+	worker.enqueue(eventBatch("overflow-1"))
+	worker.enqueue(eventBatch("overflow-2"))
+
+	assert.Equal(t, []string{"overflow-1", "overflow-2"}, spooledUIDs(t, spool, "http"))
+	assert.Zero(t, stats.snapshot().Sinks["http"].DroppedQueueFull)
+
+	close(unblock)
+	assert.Eventually(t, func() bool {
+		return len(uids(sink.received()...)) == sinkQueueSize+3
+	}, time.Second, time.Millisecond, "queued and spooled batches are delivered")
+	assert.Eventually(t, func() bool {
+		return len(spool.pending("http")) == 0
+	}, time.Second, time.Millisecond)
+
+	close(worker.batches)
+	<-done
+
+	// The queued batches follow the overflow into the spool.
+	snapshot := stats.snapshot().Sinks["http"]
+	assert.Equal(t, models.EventSinkStats{Sent: 1, Spooled: sinkQueueSize + 2, Drained: sinkQueueSize + 2}, snapshot)
+}
@@ -0,0 +1,94 @@
+package consumers
+
+import (
+	"cluster-agent/internal/models"
+	"maps"
+	"sync"
+	"sync/atomic"
+)
+
+// EventStats counts events as they move through the pipeline, so losses are
+// visible instead of only logged.
+type EventStats struct {
+	received         atomic.Int64
+	aggregated       atomic.Int64
+	droppedQueueFull atomic.Int64
+
+	mu       sync.Mutex
+	filtered map[string]int64
+	sinks    map[string]*sinkStats
+}
+
+type sinkStats struct {
+	sent                 atomic.Int64
+	rejected             atomic.Int64
+	spooled              atomic.Int64
+	drained              atomic.Int64
+	spoolOverflow        atomic.Int64
+	droppedQueueFull     atomic.Int64
+	droppedUndeliverable atomic.Int64
+}
+
+func NewEventStats() *EventStats {
+	return &EventStats{
+		filtered: make(map[string]int64),
+		sinks:    make(map[string]*sinkStats),
+	}
+}
+
+// RecordFiltered counts an event dropped by the named filter rule.
+func (s *EventStats) RecordFiltered(rule string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.filtered[rule]++
+}
+
+func (s *EventStats) sink(name string) *sinkStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats, ok := s.sinks[name]
+	if !ok {
+		stats = &sinkStats{}
+		s.sinks[name] = stats
+	}
+
+	return stats
+}
+
+func (s *EventStats) snapshot() models.EventPipelineStats {
+	s.mu.Lock()
+	filteredByRule := maps.Clone(s.filtered)
+	sinks := make(map[string]models.EventSinkStats, len(s.sinks))
+	for name, stats := range s.sinks {
+		sinks[name] = stats.snapshot()
+	}
+	s.mu.Unlock()
+
+	var filtered int64
+	for _, count := range filteredByRule {
+		filtered += count
+	}
+
+	return models.EventPipelineStats{
+		Filtered:         filtered,
+		FilteredByRule:   filteredByRule,
+		Received:         s.received.Load(),
+		Aggregated:       s.aggregated.Load(),
+		DroppedQueueFull: s.droppedQueueFull.Load(),
+		Sinks:            sinks,
+	}
+}
+
+func (s *sinkStats) snapshot() models.EventSinkStats {
+	return models.EventSinkStats{
+		Sent:                 s.sent.Load(),
+		Rejected:             s.rejected.Load(),
+		Spooled:              s.spooled.Load(),
+		Drained:              s.drained.Load(),
+		SpoolOverflow:        s.spoolOverflow.Load(),
+		DroppedQueueFull:     s.droppedQueueFull.Load(),
+		DroppedUndeliverable: s.droppedUndeliverable.Load(),
+	}
+}
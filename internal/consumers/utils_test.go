@@ -0,0 +1,129 @@
+package consumers
+
+import (
+	"cluster-agent/internal/config"
+	"cluster-agent/internal/models"
+	"context"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// fakeSink records what it receives. Writes fail while fail returns an error.
+type fakeSink struct {
+	name string
+	fail func(batch []*models.ClusterEvent) error
+
+	mu      sync.Mutex
+	batches [][]*models.ClusterEvent
+	writes  int
+}
+
+func (s *fakeSink) Name() string {
+	return s.name
+}
+
+func (s *fakeSink) Write(_ context.Context, batch []*models.ClusterEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.writes++
+	if s.fail != nil {
+		if err := s.fail(batch); err != nil {
+			return err
+		}
+	}
+
+	s.batches = append(s.batches, batch)
+	return nil
+}
+
+func (s *fakeSink) received() [][]*models.ClusterEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([][]*models.ClusterEvent(nil), s.batches...)
+}
+
+// memorySpool is an in-memory EventSpool keeping batches per sink in order.
+type memorySpool struct {
+	disabled bool
+
+	mu      sync.Mutex
+	nextId  int
+	batches map[string][]*models.SpooledEventBatch
+}
+
+func newMemorySpool() *memorySpool {
+	return &memorySpool{batches: make(map[string][]*models.SpooledEventBatch)}
+}
+
+func (s *memorySpool) Enabled() bool {
+	return !s.disabled
+}
+
+func (s *memorySpool) AppendBatch(_ context.Context, sink string, events int, payload []byte) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextId++
+	s.batches[sink] = append(s.batches[sink], &models.SpooledEventBatch{
+		Id:      strconv.Itoa(s.nextId),
+		Events:  events,
+		Payload: payload,
+	})
+
+	return 0, nil
+}
+
+func (s *memorySpool) OldestBatch(_ context.Context, sink string) (*models.SpooledEventBatch, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.batches[sink]) == 0 {
+		return nil, nil
+	}
+
+	return s.batches[sink][0], nil
+}
+
+func (s *memorySpool) RemoveBatch(_ context.Context, sink string, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, batch := range s.batches[sink] {
+		if batch.Id == id {
+			s.batches[sink] = append(s.batches[sink][:i], s.batches[sink][i+1:]...)
+			break
+		}
+	}
+
+	return nil
+}
+
+func (s *memorySpool) PendingBatches(_ context.Context, sink string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return int64(len(s.batches[sink])), nil
+}
+
+func (s *memorySpool) pending(sink string) []*models.SpooledEventBatch {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]*models.SpooledEventBatch(nil), s.batches[sink]...)
+}
+
+func testConfig() *config.Config {
+	return &config.Config{
+		EventBatchSize:     100,
+		EventBatchMaxBytes: 1 << 20,
+		EventFlushInterval: 10 * time.Millisecond,
+		EventQueueSize:     1000,
+	}
+}
+
+func newTestBatcher(cfg *config.Config, spool EventSpool, sinks ...EventSink) *EventBatcher {
+	return NewEventBatcher(config.NewManager(cfg), sinks, spool, NewEventStats())
+}
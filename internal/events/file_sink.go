@@ -0,0 +1,133 @@
+package events
+
+import (
+	"bytes"
+	"cluster-agent/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strconv"
+	"sync"
+)
+
+// FileSink appends events to a JSON Lines file. Once the file would exceed
+// maxSize it is renamed to path.1, older files move up to path.<maxBackups>
+// and the oldest is removed.
+type FileSink struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+func NewFileSink(path string, maxSize int64, maxBackups int) (*FileSink, error) {
+	s := &FileSink{
+		path:       path,
+		maxSize:    maxSize,
+		maxBackups: maxBackups,
+	}
+
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (s *FileSink) Name() string {
+	return SinkFile
+}
+
+func (s *FileSink) Write(_ context.Context, events []*models.ClusterEvent) error {
+	var buf bytes.Buffer
+	if err := encodeLines(&buf, events); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// A batch larger than maxSize still goes into a file of its own.
+	if s.size > 0 && s.size+int64(buf.Len()) > s.maxSize {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := s.file.Write(buf.Bytes())
+	s.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("failed to write event log: %w", err)
+	}
+
+	return nil
+}
+
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.file.Close()
+}
+
+func (s *FileSink) open() error {
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open event log %s: %w", s.path, err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat event log %s: %w", s.path, err)
+	}
+
+	s.file = file
+	s.size = info.Size()
+	return nil
+}
+
+// rotate always reopens the log, so a failed rename leaves the sink writing
+// to the current file rather than a closed one.
+func (s *FileSink) rotate() error {
+	closeErr := s.file.Close()
+
+	var renameErr error
+	if s.maxBackups == 0 {
+		renameErr = ignoreMissing(os.Remove(s.path))
+	} else {
+		for i := s.maxBackups - 1; i >= 1 && renameErr == nil; i-- {
+			renameErr = ignoreMissing(os.Rename(s.backup(i), s.backup(i+1)))
+		}
+		if renameErr == nil {
+			renameErr = ignoreMissing(os.Rename(s.path, s.backup(1)))
+		}
+	}
+
+	if err := s.open(); err != nil {
+		return err
+	}
+
+	if err := errors.Join(closeErr, renameErr); err != nil {
+		return fmt.Errorf("failed to rotate event log: %w", err)
+	}
+
+	return nil
+}
+
+func (s *FileSink) backup(index int) string {
+	return s.path + "." + strconv.Itoa(index)
+}
+
+func ignoreMissing(err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	return err
+}
@@ -0,0 +1,160 @@
+package events
+
+import (
+	"bufio"
+	"cluster-agent/internal/models"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// loggedUIDs reads the UIDs of the events in a JSON Lines file, or nil when
+// the file does not exist.
+func loggedUIDs(t *testing.T, path string) []string {
+	t.Helper()
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	require.NoError(t, err)
+	defer file.Close()
+
+	var uids []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event models.ClusterEvent
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		uids = append(uids, event.UID)
+	}
+	require.NoError(t, scanner.Err())
+
+	return uids
+}
+
+// lineSize is the size of one event line, the same for every UID of the
+// same length.
+func lineSize(t *testing.T) int64 {
+	t.Helper()
+
+	data, err := json.Marshal(clusterEvents("a")[0])
+	require.NoError(t, err)
+	return int64(len(data)) + 1
+}
+
+func TestFileSink_Rotation(t *testing.T) {
+	ctx := context.Background()
+	size := lineSize(t)
+
+	t.Run("Rotates once the next batch would exceed the size", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "events.log")
+		sink, err := NewFileSink(path, 2*size, 3)
+		require.NoError(t, err)
+		defer sink.Close()
+
+		require.NoError(t, sink.Write(ctx, clusterEvents("a", "b")))
+		require.NoError(t, sink.Write(ctx, clusterEvents("c")))
+
+		assert.Equal(t, []string{"c"}, loggedUIDs(t, path))
+		assert.Equal(t, []string{"a", "b"}, loggedUIDs(t, path+".1"))
+	})
+
+	t.Run("Backups move up and the oldest is removed", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "events.log")
+		sink, err := NewFileSink(path, size, 2)
+		require.NoError(t, err)
+		defer sink.Close()
+
+		for _, uid := range []string{"a", "b", "c", "d"} {
+			require.NoError(t, sink.Write(ctx, clusterEvents(uid)))
+		}
+
+		assert.Equal(t, []string{"d"}, loggedUIDs(t, path))
+		assert.Equal(t, []string{"c"}, loggedUIDs(t, path+".1"))
+		assert.Equal(t, []string{"b"}, loggedUIDs(t, path+".2"))
+		assert.NoFileExists(t, path+".3")
+	})
+
+	t.Run("Without backups the log starts over", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "events.log")
+		sink, err := NewFileSink(path, size, 0)
+		require.NoError(t, err)
+		defer sink.Close()
+
+		require.NoError(t, sink.Write(ctx, clusterEvents("a")))
+		require.NoError(t, sink.Write(ctx, clusterEvents("b")))
+
+		assert.Equal(t, []string{"b"}, loggedUIDs(t, path))
+		assert.NoFileExists(t, path+".1")
+	})
+
+	t.Run("A batch larger than the size gets a file of its own", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "events.log")
+		sink, err := NewFileSink(path, size, 2)
+		require.NoError(t, err)
+		defer sink.Close()
+
+		require.NoError(t, sink.Write(ctx, clusterEvents("a", "b", "c")))
+		require.NoError(t, sink.Write(ctx, clusterEvents("d")))
+
+		assert.Equal(t, []string{"d"}, loggedUIDs(t, path))
+		assert.Equal(t, []string{"a", "b", "c"}, loggedUIDs(t, path+".1"))
+	})
+}
+
+func TestFileSink_Reopen(t *testing.T) {
+	ctx := context.Background()
+	size := lineSize(t)
+	path := filepath.Join(t.TempDir(), "events.log")
+
+	sink, err := NewFileSink(path, 3*size, 2)
+	require.NoError(t, err)
+	require.NoError(t, sink.Write(ctx, clusterEvents("a", "b")))
+	require.NoError(t, sink.Close())
+
+	t.Run("Appends to the existing log and counts its size", func(t *testing.T) {
+		sink, err = NewFileSink(path, 3*size, 2)
+		require.NoError(t, err)
+
+		require.NoError(t, sink.Write(ctx, clusterEvents("c")))
+		assert.Equal(t, []string{"a", "b", "c"}, loggedUIDs(t, path))
+
+		require.NoError(t, sink.Write(ctx, clusterEvents("d")))
+		assert.Equal(t, []string{"d"}, loggedUIDs(t, path))
+		assert.Equal(t, []string{"a", "b", "c"}, loggedUIDs(t, path+".1"))
+	})
+
+	t.Run("Writes after a rotation go to the new file", func(t *testing.T) {
+		require.NoError(t, sink.Write(ctx, clusterEvents("e")))
+		require.NoError(t, sink.Close())
+
+		assert.Equal(t, []string{"d", "e"}, loggedUIDs(t, path))
+		assert.Equal(t, []string{"a", "b", "c"}, loggedUIDs(t, path+".1"))
+	})
+
+	t.Run("A failed rotation leaves the sink usable", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "events.log")
+		sink, err := NewFileSink(path, size, 1)
+		require.NoError(t, err)
+		defer sink.Close()
+
+		require.NoError(t, sink.Write(ctx, clusterEvents("a")))
+		// A directory in place of the backup makes the rename fail.
+		require.NoError(t, os.Mkdir(path+".1", 0o700))
+		require.NoError(t, os.WriteFile(filepath.Join(path+".1", "keep"), nil, 0o600))
+
+		assert.ErrorContains(t, sink.Write(ctx, clusterEvents("b")), "failed to rotate event log")
+		assert.Equal(t, []string{"a"}, loggedUIDs(t, path), "the worker retries the batch")
+
+		require.NoError(t, os.RemoveAll(path+".1"))
+		require.NoError(t, sink.Write(ctx, clusterEvents("b")))
+		assert.Equal(t, []string{"b"}, loggedUIDs(t, path))
+		assert.Equal(t, []string{"a"}, loggedUIDs(t, path+".1"))
+	})
+}
@@ -0,0 +1,229 @@
+package events
+
+import (
+	"bytes"
+	"cluster-agent/internal/config"
+	"cluster-agent/internal/consumers"
+	"cluster-agent/internal/models"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// HeaderSchemaVersion carries models.ClusterEventSchemaVersion.
+const HeaderSchemaVersion = "X-Event-Schema-Version"
+
+var errRequestTooLarge = errors.New("backend rejected the request as too large")
+
+// HTTPSink posts batches as a JSON array to the backend at API_URL. The URL,
+// cluster id and credentials are read per batch, so it follows configuration
+// reloads and rotated secrets apply without a restart.
+type HTTPSink struct {
+	configs    *config.Manager
+	httpClient *http.Client
+	zstd       *zstd.Encoder
+}
+
+func NewHTTPSink(configs *config.Manager) (*HTTPSink, error) {
+	t := &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout:   5 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		TLSHandshakeTimeout:   5 * time.Second,
+		ResponseHeaderTimeout: 10 * time.Second,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   10,
+		IdleConnTimeout:       90 * time.Second,
+	}
+
+	// EncodeAll is safe for concurrent use and needs no per-request writer.
+	encoder, err := zstd.NewWriter(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create zstd encoder: %w", err)
+	}
+
+	return &HTTPSink{
+		configs: configs,
+		httpClient: &http.Client{
+			Transport: t,
+			Timeout:   15 * time.Second,
+		},
+		zstd: encoder,
+	}, nil
+}
+
+func (s *HTTPSink) Name() string {
+	return SinkHTTP
+}
+
+// Write splits a batch the backend refuses as too large (413) in halves until
+// it fits; single events that still do not fit are rejected. When a part
+// fails after earlier ones were accepted, the parts not delivered are returned
+// in a PartialError, so only they are retried.
+func (s *HTTPSink) Write(ctx context.Context, events []*models.ClusterEvent) error {
+	err := s.post(ctx, events)
+	if !errors.Is(err, errRequestTooLarge) {
+		return err
+	}
+
+	if len(events) == 1 {
+		object := events[0].InvolvedObject
+		log.Printf("Backend rejected event %s for %s %s/%s as too large", events[0].UID, object.Kind, object.Namespace, object.Name)
+		return &consumers.RejectedError{Events: 1, Err: err}
+	}
+
+	log.Printf("Backend rejected %d events as too large, splitting the batch", len(events))
+
+	half := len(events) / 2
+	rejected := 0
+
+	var remaining []*models.ClusterEvent
+	var failure error
+
+	for _, part := range [][]*models.ClusterEvent{events[:half], events[half:]} {
+		// Later parts wait for the failed one, so the backend gets events in
+		// order.
+		if failure != nil {
+			remaining = append(remaining, part...)
+			continue
+		}
+
+		err := s.Write(ctx, part)
+
+		var partRejected *consumers.RejectedError
+		var partial *consumers.PartialError
+		switch {
+		case errors.As(err, &partRejected):
+			rejected += partRejected.Events
+		case errors.As(err, &partial):
+			rejected += partial.Rejected
+			remaining = append(remaining, partial.Remaining...)
+			failure = partial.Err
+		case err != nil:
+			remaining = append(remaining, part...)
+			failure = err
+		}
+	}
+
+	if failure != nil {
+		if len(remaining) == len(events) {
+			return failure
+		}
+
+		return &consumers.PartialError{Remaining: remaining, Rejected: rejected, Err: failure}
+	}
+
+	if rejected > 0 {
+		return &consumers.RejectedError{Events: rejected, Err: errRequestTooLarge}
+	}
+
+	return nil
+}
+
+func (s *HTTPSink) post(ctx context.Context, events []*models.ClusterEvent) error {
+	payload, err := json.Marshal(events)
+	if err != nil {
+		return fmt.Errorf("failed to marshal events: %w", err)
+	}
+
+	cfg := s.configs.Current()
+
+	body, err := s.compress(cfg.EventCompression, payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", cfg.ApiURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderSchemaVersion, models.ClusterEventSchemaVersion)
+	if cfg.EventCompression != "none" {
+		req.Header.Set("Content-Encoding", cfg.EventCompression)
+	}
+	id := DeliveryID(payload)
+	req.Header.Set(HeaderDeliveryID, id)
+	SetCredentials(req, cfg, id, body)
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 500 {
+		return fmt.Errorf("server error: %d", resp.StatusCode)
+	}
+
+	// Credentials may be fixed by a reload, so these batches are kept for a
+	// later attempt instead of being dropped.
+	switch resp.StatusCode {
+	case http.StatusRequestEntityTooLarge:
+		return errRequestTooLarge
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return fmt.Errorf("backend refused credentials: %d", resp.StatusCode)
+	case http.StatusRequestTimeout, http.StatusTooManyRequests:
+		return fmt.Errorf("backend unavailable: %d", resp.StatusCode)
+	}
+
+	// Resending a rejected batch would be rejected again.
+	if resp.StatusCode >= 400 {
+		return &consumers.RejectedError{Events: len(events), Err: fmt.Errorf("backend rejected events: %d", resp.StatusCode)}
+	}
+
+	return nil
+}
+
+func (s *HTTPSink) compress(encoding string, payload []byte) ([]byte, error) {
+	switch encoding {
+	case "gzip":
+		var buf bytes.Buffer
+		writer := gzip.NewWriter(&buf)
+		if _, err := writer.Write(payload); err != nil {
+			return nil, fmt.Errorf("failed to compress events: %w", err)
+		}
+		if err := writer.Close(); err != nil {
+			return nil, fmt.Errorf("failed to compress events: %w", err)
+		}
+		return buf.Bytes(), nil
+
+	case "zstd":
+		return s.zstd.EncodeAll(payload, make([]byte, 0, len(payload)/4)), nil
+
+	default:
+		return payload, nil
+	}
+}
+
+// SetCredentials identifies and authenticates a request to the backend with
+// the cluster id, auth token and signature configured for event deliveries.
+// The audit http sink sends its requests with them too.
+func SetCredentials(req *http.Request, cfg *config.Config, deliveryID string, body []byte) {
+	if cfg.ClusterID != "" {
+		req.Header.Set(HeaderClusterID, cfg.ClusterID)
+	}
+
+	if cfg.EventAuthToken != "" {
+		if http.CanonicalHeaderKey(cfg.EventAuthHeader) == "Authorization" {
+			req.Header.Set("Authorization", "Bearer "+cfg.EventAuthToken)
+		} else {
+			req.Header.Set(cfg.EventAuthHeader, cfg.EventAuthToken)
+		}
+	}
+
+	if cfg.EventSigningSecret != "" {
+		signRequest(req, body, deliveryID, cfg.ClusterID, cfg.EventSigningSecret, time.Now())
+	}
+}
@@ -0,0 +1,249 @@
+package events
+
+import (
+	"cluster-agent/internal/config"
+	"cluster-agent/internal/consumers"
+	"cluster-agent/internal/models"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// backend decodes posted batches and answers with status(batch).
+type backend struct {
+	t      *testing.T
+	status func(batch []*models.ClusterEvent) int
+
+	mu       sync.Mutex
+	accepted [][]string
+	headers  []http.Header
+}
+
+func (b *backend) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var body io.Reader = r.Body
+	switch r.Header.Get("Content-Encoding") {
+	case "gzip":
+		reader, err := gzip.NewReader(r.Body)
+		require.NoError(b.t, err)
+		body = reader
+	case "zstd":
+		reader, err := zstd.NewReader(r.Body)
+		require.NoError(b.t, err)
+		defer reader.Close()
+		body = reader
+	}
+
+	var batch []*models.ClusterEvent
+	require.NoError(b.t, json.NewDecoder(body).Decode(&batch))
+
+	status := http.StatusOK
+	if b.status != nil {
+		status = b.status(batch)
+	}
+
+	b.mu.Lock()
+	b.headers = append(b.headers, r.Header.Clone())
+	if status < 300 {
+		accepted := make([]string, 0, len(batch))
+		for _, event := range batch {
+			accepted = append(accepted, event.UID)
+		}
+		b.accepted = append(b.accepted, accepted)
+	}
+	b.mu.Unlock()
+
+	w.WriteHeader(status)
+}
+
+func newTestHTTPSink(t *testing.T, cfg *config.Config, handler http.Handler) *HTTPSink {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	cfg.ApiURL = server.URL
+	if cfg.EventCompression == "" {
+		cfg.EventCompression = "none"
+	}
+
+	sink, err := NewHTTPSink(config.NewManager(cfg))
+	require.NoError(t, err)
+	return sink
+}
+
+func clusterEvents(uids ...string) []*models.ClusterEvent {
+	events := make([]*models.ClusterEvent, 0, len(uids))
+	for _, uid := range uids {
+		events = append(events, &models.ClusterEvent{UID: uid})
+	}
+	return events
+}
+
+func uids(events []*models.ClusterEvent) []string {
+	result := make([]string, 0, len(events))
+	for _, event := range events {
+		result = append(result, event.UID)
+	}
+	return result
+}
+
+func TestHTTPSink_Write(t *testing.T) {
+	type testCase struct {
+		name              string
+		compression       string
+		status            func(batch []*models.ClusterEvent) int
+		expectedAccepted  [][]string
+		expectedRejected  int
+		expectedRemaining []string
+		expectedError     bool
+	}
+
+	tests := []testCase{
+		{
+			name:             "Delivered",
+			expectedAccepted: [][]string{{"a", "b", "c"}},
+		},
+		{
+			name:             "Gzip",
+			compression:      "gzip",
+			expectedAccepted: [][]string{{"a", "b", "c"}},
+		},
+		{
+			name:             "Zstd",
+			compression:      "zstd",
+			expectedAccepted: [][]string{{"a", "b", "c"}},
+		},
+		{
+			name:             "Invalid batch is rejected",
+			status:           func([]*models.ClusterEvent) int { return http.StatusUnprocessableEntity },
+			expectedRejected: 3,
+		},
+		{
+			name:          "Refused credentials are retried",
+			status:        func([]*models.ClusterEvent) int { return http.StatusUnauthorized },
+			expectedError: true,
+		},
+		{
+			name:          "Server error is retried",
+			status:        func([]*models.ClusterEvent) int { return http.StatusBadGateway },
+			expectedError: true,
+		},
+		{
+			name: "Oversize batch is split until it fits",
+			status: func(batch []*models.ClusterEvent) int {
+				if len(batch) > 1 {
+					return http.StatusRequestEntityTooLarge
+				}
+				return http.StatusOK
+			},
+			expectedAccepted: [][]string{{"a"}, {"b"}, {"c"}},
+		},
+		{
+			name: "Single event that does not fit is rejected",
+			status: func(batch []*models.ClusterEvent) int {
+				if len(batch) > 1 || batch[0].UID == "b" {
+					return http.StatusRequestEntityTooLarge
+				}
+				return http.StatusOK
+			},
+			expectedAccepted: [][]string{{"a"}, {"c"}},
+			expectedRejected: 1,
+		},
+		{
+			name: "Only the failed half of a split remains",
+			status: func(batch []*models.ClusterEvent) int {
+				switch {
+				case len(batch) > 2:
+					return http.StatusRequestEntityTooLarge
+				case batch[0].UID == "b":
+					return http.StatusBadGateway
+				}
+				return http.StatusOK
+			},
+			expectedAccepted:  [][]string{{"a"}},
+			expectedRemaining: []string{"b", "c"},
+		},
+		{
+			name: "Failures deep in a split keep only what was not delivered",
+			status: func(batch []*models.ClusterEvent) int {
+				switch {
+				case len(batch) > 1:
+					return http.StatusRequestEntityTooLarge
+				case batch[0].UID == "c":
+					return http.StatusServiceUnavailable
+				}
+				return http.StatusOK
+			},
+			expectedAccepted:  [][]string{{"a"}, {"b"}},
+			expectedRemaining: []string{"c"},
+		},
+		{
+			name: "Rejections before a failure are reported with it",
+			status: func(batch []*models.ClusterEvent) int {
+				switch {
+				case len(batch) > 1 || batch[0].UID == "a":
+					return http.StatusRequestEntityTooLarge
+				case batch[0].UID == "c":
+					return http.StatusBadGateway
+				}
+				return http.StatusOK
+			},
+			expectedAccepted:  [][]string{{"b"}},
+			expectedRejected:  1,
+			expectedRemaining: []string{"c"},
+		},
+		{
+			name: "Failing first half holds back the second",
+			status: func(batch []*models.ClusterEvent) int {
+				switch {
+				case len(batch) > 2:
+					return http.StatusRequestEntityTooLarge
+				case batch[0].UID == "a":
+					return http.StatusBadGateway
+				}
+				return http.StatusOK
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			backend := &backend{t: t, status: tc.status}
+			sink := newTestHTTPSink(t, &config.Config{EventCompression: tc.compression}, backend)
+
+			err := sink.Write(context.Background(), clusterEvents("a", "b", "c"))
+
+			var rejected *consumers.RejectedError
+			var partial *consumers.PartialError
+			switch {
+			case tc.expectedRemaining != nil:
+				require.ErrorAs(t, err, &partial)
+				assert.Equal(t, tc.expectedRemaining, uids(partial.Remaining))
+				assert.Equal(t, tc.expectedRejected, partial.Rejected)
+			case tc.expectedRejected > 0:
+				require.ErrorAs(t, err, &rejected)
+				assert.Equal(t, tc.expectedRejected, rejected.Events)
+			case tc.expectedError:
+				assert.Error(t, err)
+				assert.False(t, errors.As(err, &rejected), "retryable errors must not be rejections")
+				assert.False(t, errors.As(err, &partial), "nothing was delivered")
+			default:
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, tc.expectedAccepted, backend.accepted)
+			for _, header := range backend.headers {
+				assert.Equal(t, models.ClusterEventSchemaVersion, header.Get(HeaderSchemaVersion))
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package events
+
+import (
+	"cluster-agent/internal/models"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisStreamSink appends each event to a capped Redis stream, for consumers
+// such as a log pipeline reading with XREAD.
+type RedisStreamSink struct {
+	redisClient *redis.Client
+	key         string
+	maxLen      int64
+}
+
+func NewRedisStreamSink(redisClient *redis.Client, key string, maxLen int64) *RedisStreamSink {
+	return &RedisStreamSink{
+		redisClient: redisClient,
+		key:         key,
+		maxLen:      maxLen,
+	}
+}
+
+func (s *RedisStreamSink) Name() string {
+	return SinkRedis
+}
+
+func (s *RedisStreamSink) Write(ctx context.Context, events []*models.ClusterEvent) error {
+	pipe := s.redisClient.Pipeline()
+
+	for _, event := range events {
+		payload, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("failed to marshal event: %w", err)
+		}
+
+		pipe.XAdd(ctx, &redis.XAddArgs{
+			Stream: s.key,
+			MaxLen: s.maxLen,
+			Approx: true,
+			Values: map[string]interface{}{"event": payload, "schema_version": models.ClusterEventSchemaVersion},
+		})
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to append events: %w", err)
+	}
+
+	return nil
+}
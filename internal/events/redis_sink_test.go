@@ -0,0 +1,76 @@
+package events
+
+import (
+	"cluster-agent/internal/models"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingHook answers pipelines itself, so the client never connects, and
+// keeps the arguments of each command.
+type recordingHook struct {
+	commands [][]interface{}
+	err      error
+}
+
+func (h *recordingHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return nil, errors.New("unexpected dial")
+	}
+}
+
+func (h *recordingHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return next
+}
+
+func (h *recordingHook) ProcessPipelineHook(redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(_ context.Context, cmds []redis.Cmder) error {
+		for _, cmd := range cmds {
+			h.commands = append(h.commands, cmd.Args())
+		}
+		return h.err
+	}
+}
+
+func newTestRedisSink(hook *recordingHook) *RedisStreamSink {
+	client := redis.NewClient(&redis.Options{Addr: "redis.invalid:6379"})
+	client.AddHook(hook)
+	return NewRedisStreamSink(client, "cluster-agent:events", 1000)
+}
+
+func TestRedisStreamSink_Write(t *testing.T) {
+	hook := &recordingHook{}
+	sink := newTestRedisSink(hook)
+
+	require.NoError(t, sink.Write(context.Background(), clusterEvents("a", "b")))
+
+	require.Len(t, hook.commands, 2)
+	for i, uid := range []string{"a", "b"} {
+		args := hook.commands[i]
+		require.Len(t, args, 10)
+		assert.Equal(t, []interface{}{"xadd", "cluster-agent:events", "maxlen", "~", int64(1000), "*"}, args[:6])
+
+		fields := map[interface{}]interface{}{args[6]: args[7], args[8]: args[9]}
+		assert.Equal(t, models.ClusterEventSchemaVersion, fields["schema_version"])
+
+		var event models.ClusterEvent
+		require.IsType(t, []byte{}, fields["event"])
+		require.NoError(t, json.Unmarshal(fields["event"].([]byte), &event))
+		assert.Equal(t, uid, event.UID)
+	}
+}
+
+func TestRedisStreamSink_WriteError(t *testing.T) {
+	hook := &recordingHook{err: errors.New("OOM command not allowed")}
+	sink := newTestRedisSink(hook)
+
+	err := sink.Write(context.Background(), clusterEvents("a"))
+	assert.ErrorContains(t, err, "failed to append events: OOM command not allowed")
+}
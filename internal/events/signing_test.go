@@ -0,0 +1,106 @@
+package events
+
+import (
+	"cluster-agent/internal/config"
+	"cluster-agent/internal/models"
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSigningSecret = "0123456789abcdef0123456789abcdef"
+
+func TestSignRequest(t *testing.T) {
+	req, _ := http.NewRequest("POST", "http://backend", nil)
+	body := []byte(`[{"uid":"e1"}]`)
+
+	signRequest(req, body, "4f0c1a2b3c4d5e6f7a8b9c0d1e2f3a4b", "prod", testSigningSecret, time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC))
+
+	assert.Equal(t, "1767268800", req.Header.Get(HeaderTimestamp))
+	assert.Equal(t, "sha256=c84adaf9b9f77375666e3619693246186e29f1528a7ddeebc7635edcddf64534", req.Header.Get(HeaderSignature))
+}
+
+func TestDeliveryID(t *testing.T) {
+	assert.Equal(t, "52ee8bcb79371c7576667121d5cca96d", DeliveryID([]byte(`[{"uid":"e1"}]`)))
+	assert.NotEqual(t, DeliveryID([]byte(`[{"uid":"e1"}]`)), DeliveryID([]byte(`[{"uid":"e2"}]`)))
+}
+
+func TestSetCredentials(t *testing.T) {
+	type testCase struct {
+		name     string
+		cfg      *config.Config
+		expected http.Header
+	}
+
+	tests := []testCase{
+		{
+			name:     "Nothing configured",
+			cfg:      &config.Config{EventAuthHeader: "Authorization"},
+			expected: http.Header{},
+		},
+		{
+			name: "Bearer token",
+			cfg:  &config.Config{ClusterID: "prod", EventAuthHeader: "Authorization", EventAuthToken: "token"},
+			expected: http.Header{
+				"X-Cluster-Id":  {"prod"},
+				"Authorization": {"Bearer token"},
+			},
+		},
+		{
+			name: "API key header",
+			cfg:  &config.Config{EventAuthHeader: "X-API-Key", EventAuthToken: "key"},
+			expected: http.Header{
+				"X-Api-Key": {"key"},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req, _ := http.NewRequest("POST", "http://backend", nil)
+			SetCredentials(req, tc.cfg, "id", nil)
+			assert.Equal(t, tc.expected, req.Header)
+		})
+	}
+}
+
+// A retried batch keeps its delivery id, so the backend can drop duplicates,
+// and its signature verifies against the body as received.
+func TestHTTPSink_SignedDeliveries(t *testing.T) {
+	var requests []*http.Request
+	var bodies [][]byte
+
+	sink := newTestHTTPSink(t, &config.Config{
+		ClusterID:          "prod",
+		EventAuthHeader:    "Authorization",
+		EventAuthToken:     "token",
+		EventSigningSecret: testSigningSecret,
+	}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		requests = append(requests, r)
+		bodies = append(bodies, body)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+
+	batch := []*models.ClusterEvent{{UID: "e1"}}
+	assert.Error(t, sink.Write(context.Background(), batch))
+	assert.Error(t, sink.Write(context.Background(), batch))
+	assert.Error(t, sink.Write(context.Background(), []*models.ClusterEvent{{UID: "e2"}}))
+
+	require.Len(t, requests, 3)
+	assert.Equal(t, requests[0].Header.Get(HeaderDeliveryID), requests[1].Header.Get(HeaderDeliveryID))
+	assert.NotEqual(t, requests[0].Header.Get(HeaderDeliveryID), requests[2].Header.Get(HeaderDeliveryID))
+
+	for i, req := range requests {
+		expected := signature(testSigningSecret, req.Header.Get(HeaderTimestamp), req.Header.Get(HeaderDeliveryID), "prod", bodies[i])
+		assert.Equal(t, signatureScheme+expected, req.Header.Get(HeaderSignature))
+		assert.Equal(t, "prod", req.Header.Get(HeaderClusterID))
+		assert.Equal(t, "Bearer token", req.Header.Get("Authorization"))
+	}
+}
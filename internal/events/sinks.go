@@ -0,0 +1,60 @@
+package events
+
+import (
+	"cluster-agent/internal/config"
+	"cluster-agent/internal/consumers"
+	"fmt"
+	"os"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	SinkHTTP   = "http"
+	SinkRedis  = "redis"
+	SinkFile   = "file"
+	SinkStdout = "stdout"
+)
+
+// NewSinks builds the event sinks listed in the config. Every sink receives
+// every batch.
+func NewSinks(configs *config.Manager, redisClient *redis.Client) ([]consumers.EventSink, func(), error) {
+	cfg := configs.Current()
+	sinks := make([]consumers.EventSink, 0, len(cfg.EventSinks))
+	var files []*FileSink
+
+	cleanup := func() {
+		for _, file := range files {
+			file.Close()
+		}
+	}
+
+	for _, name := range cfg.EventSinks {
+		switch name {
+		case SinkHTTP:
+			sink, err := NewHTTPSink(configs)
+			if err != nil {
+				cleanup()
+				return nil, nil, err
+			}
+			sinks = append(sinks, sink)
+		case SinkRedis:
+			sinks = append(sinks, NewRedisStreamSink(redisClient, cfg.EventStreamKey, cfg.EventStreamMaxLen))
+		case SinkFile:
+			sink, err := NewFileSink(cfg.EventFilePath, cfg.EventFileMaxSize, cfg.EventFileMaxBackups)
+			if err != nil {
+				cleanup()
+				return nil, nil, err
+			}
+			files = append(files, sink)
+			sinks = append(sinks, sink)
+		case SinkStdout:
+			sinks = append(sinks, NewWriterSink(SinkStdout, os.Stdout))
+		default:
+			cleanup()
+			return nil, nil, fmt.Errorf("unknown event sink %q", name)
+		}
+	}
+
+	return sinks, cleanup, nil
+}
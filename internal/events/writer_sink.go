@@ -0,0 +1,54 @@
+package events
+
+import (
+	"bufio"
+	"cluster-agent/internal/models"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// WriterSink writes events as JSON Lines, e.g. to stdout for a log collector
+// that scrapes container output.
+type WriterSink struct {
+	name   string
+	mu     sync.Mutex
+	writer io.Writer
+}
+
+func NewWriterSink(name string, writer io.Writer) *WriterSink {
+	return &WriterSink{
+		name:   name,
+		writer: writer,
+	}
+}
+
+func (s *WriterSink) Name() string {
+	return s.name
+}
+
+func (s *WriterSink) Write(_ context.Context, events []*models.ClusterEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	writer := bufio.NewWriter(s.writer)
+	if err := encodeLines(writer, events); err != nil {
+		return err
+	}
+
+	return writer.Flush()
+}
+
+func encodeLines(writer io.Writer, events []*models.ClusterEvent) error {
+	encoder := json.NewEncoder(writer)
+
+	for _, event := range events {
+		if err := encoder.Encode(event); err != nil {
+			return fmt.Errorf("failed to encode event: %w", err)
+		}
+	}
+
+	return nil
+}
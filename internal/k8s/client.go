@@ -1,10 +1,12 @@
 package k8s
 
 import (
+	"cluster-agent/internal/config"
 	"fmt"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/util/flowcontrol"
 	"k8s.io/client-go/util/homedir"
 	"os"
 	"path/filepath"
@@ -23,7 +25,7 @@ func (c *Client) GetConfig() *rest.Config {
 	return c.config
 }
 
-func NewClient() (*Client, error) {
+func NewClient(cfg *config.Config) (*Client, error) {
 	config, err := rest.InClusterConfig()
 	if err != nil {
 		kubeconfig := os.Getenv("KUBECONFIG")
@@ -39,8 +41,11 @@ func NewClient() (*Client, error) {
 		}
 	}
 
-	config.QPS = 100
-	config.Burst = 200
+	// A limiter set on the config is shared by every client built from a copy
+	// of it, impersonated clients included.
+	config.QPS = cfg.K8sQPS
+	config.Burst = cfg.K8sBurst
+	config.RateLimiter = flowcontrol.NewTokenBucketRateLimiter(cfg.K8sQPS, cfg.K8sBurst)
 
 	clientset, err := kubernetes.NewForConfig(config)
 	if err != nil {
@@ -0,0 +1,183 @@
+package k8s
+
+import (
+	"cluster-agent/internal/config"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	impersonatedClientTTL  = 10 * time.Minute
+	maxImpersonatedClients = 1000
+)
+
+var ErrNoIdentity = errors.New("no caller identity to impersonate")
+
+// Identity is the Kubernetes user a request is executed as.
+type Identity struct {
+	User   string
+	Groups []string
+}
+
+// NewIdentity builds the identity impersonated for a caller. Groups get the
+// same prefix as the user, so a token claiming a group such as
+// "system:masters" cannot pick up that group's RBAC bindings. Without a
+// prefix, groups are not impersonated at all.
+func NewIdentity(prefix, user string, groups []string) Identity {
+	identity := Identity{User: prefix + user}
+	if prefix == "" {
+		return identity
+	}
+
+	for _, group := range groups {
+		identity.Groups = append(identity.Groups, prefix+group)
+	}
+
+	return identity
+}
+
+type identityKey struct{}
+
+func WithIdentity(ctx context.Context, identity Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+func IdentityFrom(ctx context.Context) (Identity, bool) {
+	identity, ok := ctx.Value(identityKey{}).(Identity)
+	return identity, ok
+}
+
+// ClientProvider hands out the clients a request should use. With
+// impersonation enabled they act as the caller found in the context, so
+// Kubernetes RBAC applies on top of the token permissions. The agent's service
+// account then needs the "impersonate" verb on users and groups. Informer-backed
+// reads such as topology keep using the agent's own identity.
+type ClientProvider interface {
+	Clientset(ctx context.Context) (kubernetes.Interface, error)
+	Config(ctx context.Context) (*rest.Config, error)
+}
+
+type impersonatedClient struct {
+	clientset kubernetes.Interface
+	config    *rest.Config
+	expiresAt time.Time
+}
+
+type clientProvider struct {
+	base        *Client
+	impersonate bool
+
+	mu      sync.Mutex
+	clients map[string]*impersonatedClient
+}
+
+func NewClientProvider(client *Client, cfg *config.Config) ClientProvider {
+	return &clientProvider{
+		base:        client,
+		impersonate: cfg.K8sImpersonation,
+		clients:     make(map[string]*impersonatedClient),
+	}
+}
+
+func (p *clientProvider) Clientset(ctx context.Context) (kubernetes.Interface, error) {
+	if !p.impersonate {
+		return p.base.GetClientset(), nil
+	}
+
+	client, err := p.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return client.clientset, nil
+}
+
+func (p *clientProvider) Config(ctx context.Context) (*rest.Config, error) {
+	if !p.impersonate {
+		return p.base.GetConfig(), nil
+	}
+
+	client, err := p.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return client.config, nil
+}
+
+// client returns a cached clientset for the caller, so each user keeps one
+// connection pool instead of dialing the API server on every request.
+func (p *clientProvider) client(ctx context.Context) (*impersonatedClient, error) {
+	identity, ok := IdentityFrom(ctx)
+	if !ok || identity.User == "" {
+		return nil, ErrNoIdentity
+	}
+
+	groups := slices.Clone(identity.Groups)
+	slices.Sort(groups)
+	key := identity.User + "\x00" + strings.Join(groups, "\x00")
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	if client, ok := p.clients[key]; ok && now.Before(client.expiresAt) {
+		return client, nil
+	}
+
+	// The copy keeps the base config's RateLimiter, so K8S_QPS and K8S_BURST
+	// bound the agent's total load however many callers it serves.
+	config := rest.CopyConfig(p.base.GetConfig())
+	config.Impersonate = rest.ImpersonationConfig{
+		UserName: identity.User,
+		Groups:   groups,
+	}
+
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create impersonated clientset: %w", err)
+	}
+
+	p.evict(now)
+
+	client := &impersonatedClient{
+		clientset: clientset,
+		config:    config,
+		expiresAt: now.Add(impersonatedClientTTL),
+	}
+	p.clients[key] = client
+
+	return client, nil
+}
+
+// evict drops expired clients and, if the cache is still full, the one
+// closest to expiring.
+func (p *clientProvider) evict(now time.Time) {
+	for key, client := range p.clients {
+		if !now.Before(client.expiresAt) {
+			delete(p.clients, key)
+		}
+	}
+
+	if len(p.clients) < maxImpersonatedClients {
+		return
+	}
+
+	var oldestKey string
+	var oldest time.Time
+	for key, client := range p.clients {
+		if oldestKey == "" || client.expiresAt.Before(oldest) {
+			oldestKey, oldest = key, client.expiresAt
+		}
+	}
+
+	delete(p.clients, oldestKey)
+}
@@ -0,0 +1,181 @@
+package k8s
+
+import (
+	"cluster-agent/internal/config"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/util/flowcontrol"
+)
+
+func TestNewIdentity(t *testing.T) {
+	type testCase struct {
+		name     string
+		prefix   string
+		user     string
+		groups   []string
+		expected Identity
+	}
+
+	tests := []testCase{
+		{
+			name:     "Prefixed user and groups",
+			prefix:   "agent:",
+			user:     "42",
+			groups:   []string{"developers", "system:masters"},
+			expected: Identity{User: "agent:42", Groups: []string{"agent:developers", "agent:system:masters"}},
+		},
+		{
+			name:     "No groups",
+			prefix:   "agent:",
+			user:     "42",
+			expected: Identity{User: "agent:42"},
+		},
+		{
+			name:     "Groups are dropped without a prefix",
+			user:     "42",
+			groups:   []string{"system:masters"},
+			expected: Identity{User: "42"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, NewIdentity(tt.prefix, tt.user, tt.groups))
+		})
+	}
+}
+
+func newTestProvider(t *testing.T, impersonate bool) *clientProvider {
+	t.Helper()
+
+	restConfig := &rest.Config{
+		Host:        "https://kubernetes.invalid",
+		RateLimiter: flowcontrol.NewTokenBucketRateLimiter(10, 20),
+	}
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	require.NoError(t, err)
+
+	provider := NewClientProvider(&Client{clientset: clientset, config: restConfig}, &config.Config{K8sImpersonation: impersonate})
+	return provider.(*clientProvider)
+}
+
+func withIdentity(user string, groups ...string) context.Context {
+	return WithIdentity(context.Background(), Identity{User: user, Groups: groups})
+}
+
+func TestClientProvider_WithoutImpersonation(t *testing.T) {
+	provider := newTestProvider(t, false)
+
+	clientset, err := provider.Clientset(context.Background())
+	require.NoError(t, err)
+	assert.Same(t, provider.base.GetClientset(), clientset)
+
+	restConfig, err := provider.Config(context.Background())
+	require.NoError(t, err)
+	assert.Same(t, provider.base.GetConfig(), restConfig)
+}
+
+func TestClientProvider_Impersonation(t *testing.T) {
+	provider := newTestProvider(t, true)
+
+	t.Run("Missing identity", func(t *testing.T) {
+		_, err := provider.Clientset(context.Background())
+		assert.ErrorIs(t, err, ErrNoIdentity)
+
+		_, err = provider.Config(withIdentity(""))
+		assert.ErrorIs(t, err, ErrNoIdentity)
+	})
+
+	t.Run("Config impersonates the caller", func(t *testing.T) {
+		restConfig, err := provider.Config(withIdentity("agent:42", "agent:ops", "agent:dev"))
+		require.NoError(t, err)
+
+		assert.Equal(t, "agent:42", restConfig.Impersonate.UserName)
+		assert.Equal(t, []string{"agent:dev", "agent:ops"}, restConfig.Impersonate.Groups)
+		assert.Same(t, provider.base.GetConfig().RateLimiter, restConfig.RateLimiter)
+		assert.Empty(t, provider.base.GetConfig().Impersonate.UserName)
+	})
+
+	t.Run("Clients are cached per user and group set", func(t *testing.T) {
+		first, err := provider.Clientset(withIdentity("agent:7", "agent:ops", "agent:dev"))
+		require.NoError(t, err)
+
+		same, err := provider.Clientset(withIdentity("agent:7", "agent:dev", "agent:ops"))
+		require.NoError(t, err)
+		assert.Same(t, first, same)
+
+		otherGroups, err := provider.Clientset(withIdentity("agent:7", "agent:dev"))
+		require.NoError(t, err)
+		assert.NotSame(t, first, otherGroups)
+
+		otherUser, err := provider.Clientset(withIdentity("agent:8", "agent:dev", "agent:ops"))
+		require.NoError(t, err)
+		assert.NotSame(t, first, otherUser)
+	})
+}
+
+func TestClientProvider_Expiry(t *testing.T) {
+	provider := newTestProvider(t, true)
+	ctx := withIdentity("agent:42")
+
+	first, err := provider.Clientset(ctx)
+	require.NoError(t, err)
+
+	for _, client := range provider.clients {
+		assert.WithinDuration(t, time.Now().Add(impersonatedClientTTL), client.expiresAt, time.Second)
+		client.expiresAt = time.Now().Add(-time.Second)
+	}
+
+	renewed, err := provider.Clientset(ctx)
+	require.NoError(t, err)
+	assert.NotSame(t, first, renewed)
+	assert.Len(t, provider.clients, 1)
+}
+
+func cached(provider *clientProvider, key string) bool {
+	_, ok := provider.clients[key]
+	return ok
+}
+
+func TestClientProvider_Eviction(t *testing.T) {
+	provider := newTestProvider(t, true)
+	now := time.Now()
+
+	t.Run("Expired clients are dropped", func(t *testing.T) {
+		provider.clients = map[string]*impersonatedClient{
+			"expired": {expiresAt: now.Add(-time.Minute)},
+			"live":    {expiresAt: now.Add(time.Minute)},
+		}
+
+		_, err := provider.Clientset(withIdentity("agent:42"))
+		require.NoError(t, err)
+
+		assert.False(t, cached(provider, "expired"))
+		assert.True(t, cached(provider, "live"))
+		assert.Len(t, provider.clients, 2)
+	})
+
+	t.Run("A full cache evicts the client closest to expiring", func(t *testing.T) {
+		provider.clients = make(map[string]*impersonatedClient, maxImpersonatedClients)
+		for i := range maxImpersonatedClients {
+			provider.clients[fmt.Sprintf("user-%d", i)] = &impersonatedClient{
+				expiresAt: now.Add(time.Minute + time.Duration(i)*time.Second),
+			}
+		}
+
+		_, err := provider.Clientset(withIdentity("agent:42"))
+		require.NoError(t, err)
+
+		assert.Len(t, provider.clients, maxImpersonatedClients)
+		assert.False(t, cached(provider, "user-0"))
+		assert.True(t, cached(provider, "user-1"))
+		assert.True(t, cached(provider, "agent:42\x00"))
+	})
+}
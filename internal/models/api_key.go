@@ -0,0 +1,36 @@
+package models
+
+import (
+	"cluster-agent/internal/auth/permissions"
+	"time"
+)
+
+type APIKey struct {
+	Id          string                   `json:"id"`
+	Name        string                   `json:"name"`
+	Permissions []permissions.Permission `json:"permissions"`
+	Namespaces  []string                 `json:"namespaces,omitempty"`
+	CreatedBy   string                   `json:"created_by"`
+	CreatedAt   time.Time                `json:"created_at"`
+	ExpiresAt   *time.Time               `json:"expires_at,omitempty"`
+	LastUsedAt  *time.Time               `json:"last_used_at,omitempty"`
+}
+
+// StoredAPIKey is the persisted form of a key. Only a digest of the secret is
+// kept, the key itself is shown once on creation.
+type StoredAPIKey struct {
+	APIKey
+	SecretHash string `json:"secret_hash"`
+}
+
+type CreateAPIKeyParams struct {
+	Name        string                   `json:"name" binding:"required"`
+	Permissions []permissions.Permission `json:"permissions" binding:"required,min=1"`
+	Namespaces  []string                 `json:"namespaces"`
+	ExpiresAt   *time.Time               `json:"expires_at"`
+}
+
+type CreatedAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"cluster-agent/internal/auth"
+	"time"
+)
+
+type AuditOutcome string
+
+const (
+	AuditOutcomeSuccess AuditOutcome = "success"
+	AuditOutcomeDenied  AuditOutcome = "denied"
+	AuditOutcomeFailure AuditOutcome = "failure"
+)
+
+type AuditTarget struct {
+	Namespace string   `json:"namespace,omitempty"`
+	Name      string   `json:"name,omitempty"`
+	Container string   `json:"container,omitempty"`
+	Key       string   `json:"key,omitempty"`
+	Command   []string `json:"command,omitempty"`
+}
+
+type AuditEntry struct {
+	Id         string       `json:"id,omitempty"`
+	Time       time.Time    `json:"time"`
+	UserId     string       `json:"user_id"`
+	Action     string       `json:"action"`
+	Method     string       `json:"method"`
+	Route      string       `json:"route"`
+	Target     AuditTarget  `json:"target"`
+	BodyDigest string       `json:"body_digest,omitempty"`
+	Status     int          `json:"status"`
+	Outcome    AuditOutcome `json:"outcome"`
+	DurationMs int64        `json:"duration_ms"`
+	ClientIP   string       `json:"client_ip"`
+}
+
+type AuditQuery struct {
+	UserId    string    `form:"user_id"`
+	Action    string    `form:"action"`
+	Namespace string    `form:"namespace"`
+	Since     time.Time `form:"since" time_format:"2006-01-02T15:04:05Z07:00"`
+	Limit     int       `form:"limit" binding:"omitempty,min=1,max=1000"`
+
+	// Scope holds the namespaces the caller may see, nil for all. Sinks apply
+	// it with the other filters, before the limit.
+	Scope *auth.NamespaceScope `form:"-"`
+}
+
+// Matches reports whether the entry satisfies every filter set on the query.
+func (q AuditQuery) Matches(entry AuditEntry) bool {
+	if q.UserId != "" && entry.UserId != q.UserId {
+		return false
+	}
+
+	if q.Action != "" && entry.Action != q.Action {
+		return false
+	}
+
+	if q.Namespace != "" && entry.Target.Namespace != q.Namespace {
+		return false
+	}
+
+	if !q.Since.IsZero() && entry.Time.Before(q.Since) {
+		return false
+	}
+
+	return q.Scope.Allows(entry.Target.Namespace)
+}
@@ -0,0 +1,140 @@
+package models
+
+import (
+	"cluster-agent/internal/auth"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ClusterEventSchemaVersion is sent with every batch of ClusterEvents. Raise
+// it on changes that break existing fields; adding fields keeps the version.
+const ClusterEventSchemaVersion = "1"
+
+// ClusterEvent is the form in which Kubernetes events leave the agent, so the
+// backend does not depend on the Kubernetes API types. Count is how often the
+// event occurred between FirstTimestamp and LastTimestamp; with aggregation
+// enabled that is one window, so the records of a long-running event add up
+// to its total instead of each carrying its whole history.
+type ClusterEvent struct {
+	UID            string         `json:"uid"`
+	ClusterID      string         `json:"cluster_id"`
+	InvolvedObject EventObjectRef `json:"involved_object"`
+	Reason         string         `json:"reason"`
+	Type           string         `json:"type"`
+	Message        string         `json:"message"`
+	Count          int32          `json:"count"`
+	FirstTimestamp time.Time      `json:"first_timestamp"`
+	LastTimestamp  time.Time      `json:"last_timestamp"`
+	Source         EventSource    `json:"source"`
+}
+
+type EventObjectRef struct {
+	Kind       string `json:"kind"`
+	APIVersion string `json:"api_version"`
+	Namespace  string `json:"namespace"`
+	Name       string `json:"name"`
+	UID        string `json:"uid"`
+	FieldPath  string `json:"field_path,omitempty"`
+}
+
+type EventSource struct {
+	Component string `json:"component"`
+	Host      string `json:"host,omitempty"`
+}
+
+func NewClusterEvent(event *corev1.Event, clusterID string) *ClusterEvent {
+	first, last := EventSpan(event)
+
+	// Events written through events.k8s.io/v1 report their source in the
+	// Reporting fields instead.
+	source := EventSource{
+		Component: event.Source.Component,
+		Host:      event.Source.Host,
+	}
+	if source.Component == "" {
+		source.Component = event.ReportingController
+	}
+	if source.Host == "" {
+		source.Host = event.ReportingInstance
+	}
+
+	return &ClusterEvent{
+		UID:       string(event.UID),
+		ClusterID: clusterID,
+		InvolvedObject: EventObjectRef{
+			Kind:       event.InvolvedObject.Kind,
+			APIVersion: event.InvolvedObject.APIVersion,
+			Namespace:  event.InvolvedObject.Namespace,
+			Name:       event.InvolvedObject.Name,
+			UID:        string(event.InvolvedObject.UID),
+			FieldPath:  event.InvolvedObject.FieldPath,
+		},
+		Reason:         event.Reason,
+		Type:           event.Type,
+		Message:        event.Message,
+		Count:          max(event.Count, 1),
+		FirstTimestamp: first,
+		LastTimestamp:  last,
+		Source:         source,
+	}
+}
+
+// EventSpan returns when the event was first and last seen, falling back to
+// the newer EventTime and then the creation time when the legacy timestamps
+// are not set.
+func EventSpan(event *corev1.Event) (time.Time, time.Time) {
+	fallback := event.EventTime.Time
+	if fallback.IsZero() {
+		fallback = event.CreationTimestamp.Time
+	}
+
+	first, last := event.FirstTimestamp.Time, event.LastTimestamp.Time
+	if first.IsZero() {
+		first = fallback
+	}
+	if last.IsZero() {
+		last = first
+	}
+
+	return first, last
+}
+
+// EventQuery filters events by their involved object, type and reason.
+type EventQuery struct {
+	Namespace string `form:"namespace"`
+	Kind      string `form:"kind"`
+	Name      string `form:"name"`
+	Type      string `form:"type" binding:"omitempty,oneof=Normal Warning"`
+	Reason    string `form:"reason"`
+	Limit     int    `form:"limit" binding:"omitempty,min=1,max=1000"`
+
+	// Scope holds the namespaces the caller may see, nil for all. Events of
+	// cluster-scoped objects need an unrestricted scope.
+	Scope *auth.NamespaceScope `form:"-"`
+}
+
+// Matches reports whether the event satisfies every filter set on the query.
+func (q EventQuery) Matches(event *ClusterEvent) bool {
+	if q.Namespace != "" && event.InvolvedObject.Namespace != q.Namespace {
+		return false
+	}
+
+	if q.Kind != "" && event.InvolvedObject.Kind != q.Kind {
+		return false
+	}
+
+	if q.Name != "" && event.InvolvedObject.Name != q.Name {
+		return false
+	}
+
+	if q.Type != "" && event.Type != q.Type {
+		return false
+	}
+
+	if q.Reason != "" && event.Reason != q.Reason {
+		return false
+	}
+
+	return q.Scope.Allows(event.InvolvedObject.Namespace)
+}
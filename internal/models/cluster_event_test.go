@@ -0,0 +1,149 @@
+package models
+
+import (
+	"cluster-agent/internal/auth"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNewClusterEvent(t *testing.T) {
+	first := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	last := first.Add(time.Minute)
+	eventTime := first.Add(time.Hour)
+
+	involved := corev1.ObjectReference{
+		Kind:       "Pod",
+		APIVersion: "v1",
+		Namespace:  "default",
+		Name:       "api",
+		UID:        "pod-1",
+		FieldPath:  "spec.containers{app}",
+	}
+	ref := EventObjectRef{
+		Kind:       "Pod",
+		APIVersion: "v1",
+		Namespace:  "default",
+		Name:       "api",
+		UID:        "pod-1",
+		FieldPath:  "spec.containers{app}",
+	}
+
+	type testCase struct {
+		name     string
+		event    *corev1.Event
+		expected *ClusterEvent
+	}
+
+	tests := []testCase{
+		{
+			name: "Legacy event",
+			event: &corev1.Event{
+				ObjectMeta:     metav1.ObjectMeta{UID: "e1", ManagedFields: []metav1.ManagedFieldsEntry{{Manager: "kubelet"}}},
+				InvolvedObject: involved,
+				Reason:         "BackOff",
+				Type:           corev1.EventTypeWarning,
+				Message:        "Back-off restarting failed container",
+				Count:          4,
+				FirstTimestamp: metav1.NewTime(first),
+				LastTimestamp:  metav1.NewTime(last),
+				Source:         corev1.EventSource{Component: "kubelet", Host: "node-1"},
+			},
+			expected: &ClusterEvent{
+				UID:            "e1",
+				ClusterID:      "prod",
+				InvolvedObject: ref,
+				Reason:         "BackOff",
+				Type:           corev1.EventTypeWarning,
+				Message:        "Back-off restarting failed container",
+				Count:          4,
+				FirstTimestamp: first,
+				LastTimestamp:  last,
+				Source:         EventSource{Component: "kubelet", Host: "node-1"},
+			},
+		},
+		{
+			name: "events.k8s.io event",
+			event: &corev1.Event{
+				ObjectMeta:          metav1.ObjectMeta{UID: "e2"},
+				InvolvedObject:      involved,
+				Reason:              "Scheduled",
+				Type:                corev1.EventTypeNormal,
+				EventTime:           metav1.NewMicroTime(eventTime),
+				ReportingController: "default-scheduler",
+				ReportingInstance:   "scheduler-1",
+			},
+			expected: &ClusterEvent{
+				UID:            "e2",
+				ClusterID:      "prod",
+				InvolvedObject: ref,
+				Reason:         "Scheduled",
+				Type:           corev1.EventTypeNormal,
+				Count:          1,
+				FirstTimestamp: eventTime,
+				LastTimestamp:  eventTime,
+				Source:         EventSource{Component: "default-scheduler", Host: "scheduler-1"},
+			},
+		},
+		{
+			name: "Creation time fallback",
+			event: &corev1.Event{
+				ObjectMeta:     metav1.ObjectMeta{UID: "e3", CreationTimestamp: metav1.NewTime(first)},
+				InvolvedObject: involved,
+			},
+			expected: &ClusterEvent{
+				UID:            "e3",
+				ClusterID:      "prod",
+				InvolvedObject: ref,
+				Count:          1,
+				FirstTimestamp: first,
+				LastTimestamp:  first,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, NewClusterEvent(tc.event, "prod"))
+		})
+	}
+}
+
+func TestEventQuery_Matches(t *testing.T) {
+	event := &ClusterEvent{
+		InvolvedObject: EventObjectRef{Kind: "Pod", Namespace: "payments", Name: "api"},
+		Type:           corev1.EventTypeWarning,
+		Reason:         "BackOff",
+	}
+	nodeEvent := &ClusterEvent{InvolvedObject: EventObjectRef{Kind: "Node", Name: "node-1"}}
+
+	type testCase struct {
+		name     string
+		query    EventQuery
+		event    *ClusterEvent
+		expected bool
+	}
+
+	tests := []testCase{
+		{name: "No filters", query: EventQuery{}, event: event, expected: true},
+		{name: "All filters match", query: EventQuery{Namespace: "payments", Kind: "Pod", Name: "api", Type: "Warning", Reason: "BackOff"}, event: event, expected: true},
+		{name: "Other namespace", query: EventQuery{Namespace: "frontend"}, event: event},
+		{name: "Other kind", query: EventQuery{Kind: "Deployment"}, event: event},
+		{name: "Other name", query: EventQuery{Name: "web"}, event: event},
+		{name: "Other type", query: EventQuery{Type: "Normal"}, event: event},
+		{name: "Other reason", query: EventQuery{Reason: "Pulled"}, event: event},
+		{name: "Granted namespace", query: EventQuery{Scope: auth.NewNamespaceScope("payments")}, event: event, expected: true},
+		{name: "Namespace not granted", query: EventQuery{Scope: auth.NewNamespaceScope("frontend")}, event: event},
+		{name: "Cluster-scoped object needs all namespaces", query: EventQuery{Scope: auth.NewNamespaceScope("payments")}, event: nodeEvent},
+		{name: "Cluster-scoped object with all namespaces", query: EventQuery{Scope: auth.AllNamespaces()}, event: nodeEvent, expected: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.query.Matches(tc.event))
+		})
+	}
+}
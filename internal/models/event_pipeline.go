@@ -0,0 +1,49 @@
+package models
+
+// SpooledEventBatch is a batch a sink could not take, kept until it can be
+// delivered. Payload is the JSON encoded batch.
+type SpooledEventBatch struct {
+	Id      string
+	Events  int
+	Payload []byte
+}
+
+// EventPipelineStats counts events since the agent started.
+type EventPipelineStats struct {
+	// Filtered were dropped by the event filters, per rule in
+	// FilteredByRule. Received passed the filters.
+	Filtered       int64            `json:"filtered"`
+	FilteredByRule map[string]int64 `json:"filtered_by_rule"`
+	Received       int64            `json:"received"`
+	// Aggregated were folded into the record of an earlier, identical event
+	// instead of being forwarded on their own.
+	Aggregated int64 `json:"aggregated"`
+	// DroppedQueueFull were rejected because the batcher queue was full.
+	DroppedQueueFull int64 `json:"dropped_queue_full"`
+
+	Sinks map[string]EventSinkStats `json:"sinks"`
+}
+
+// EventSinkStats counts the events of one sink, which retries and spools
+// independently of the others. SpoolPending is the current spool length in
+// batches.
+type EventSinkStats struct {
+	Sent int64 `json:"sent"`
+	// Rejected were refused by the destination for good, e.g. as invalid,
+	// and dropped since resending cannot help.
+	Rejected int64 `json:"rejected"`
+	// Spooled were written to the spool after a failed delivery, Drained
+	// were delivered from it later.
+	Spooled int64 `json:"spooled"`
+	Drained int64 `json:"drained"`
+	// SpoolOverflow were the oldest spooled events, discarded to keep the
+	// spool within its size limit.
+	SpoolOverflow int64 `json:"spool_overflow"`
+	// DroppedQueueFull were rejected while the sink was still busy with
+	// earlier batches and the spool could not take them either.
+	DroppedQueueFull int64 `json:"dropped_queue_full"`
+	// DroppedUndeliverable were lost because neither the sink nor the spool
+	// could take them.
+	DroppedUndeliverable int64 `json:"dropped_undeliverable"`
+	SpoolPending         int64 `json:"spool_pending"`
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"cluster-agent/internal/auth"
+	"cluster-agent/internal/auth/permissions"
+)
+
+type RouteAuth string
+
+const (
+	RouteAuthToken  RouteAuth = "token"
+	RouteAuthTicket RouteAuth = "ticket"
+)
+
+// RouteInfo describes a registered route. Routes without a permission are
+// open to every authenticated caller.
+type RouteInfo struct {
+	Method         string                 `json:"method"`
+	Path           string                 `json:"path"`
+	Permission     permissions.Permission `json:"permission,omitempty"`
+	ClusterScoped  bool                   `json:"cluster_scoped"`
+	Authentication RouteAuth              `json:"authentication"`
+	AuditAction    string                 `json:"audit_action,omitempty"`
+}
+
+type RouteAccess struct {
+	RouteInfo
+	Allowed bool `json:"allowed"`
+}
+
+// EffectivePermission is a permission the caller holds, with the namespaces it
+// applies to. AllNamespaces is set for cluster-wide grants.
+type EffectivePermission struct {
+	Permission    permissions.Permission `json:"permission"`
+	AllNamespaces bool                   `json:"all_namespaces"`
+	Namespaces    []string               `json:"namespaces,omitempty"`
+}
+
+type CallerInfo struct {
+	UserId      string                `json:"user_id"`
+	Claims      *auth.UserClaims      `json:"claims"`
+	Permissions []EffectivePermission `json:"permissions"`
+	Routes      []RouteAccess         `json:"routes"`
+}
@@ -10,12 +10,29 @@ type SecretListInfo struct {
 	Age       time.Time `json:"age"`
 }
 
+// MaskedSecretValue stands in for a value that was not revealed.
+const MaskedSecretValue = "********"
+
+// SecretKeyInfo describes a secret value without exposing it. The digest is an
+// HMAC keyed by the agent, so it lets callers compare values across secrets
+// and over time without offering a hash to brute-force.
+type SecretKeyInfo struct {
+	Value  string `json:"value"`
+	Size   int    `json:"size"`
+	Digest string `json:"hmac_sha256"`
+}
+
 type SecretDetails struct {
 	SecretListInfo
-	Data   map[string][]byte `json:"data"`
-	UID    string            `json:"uid"`
-	Labels map[string]string `json:"labels"`
+	Data   map[string]SecretKeyInfo `json:"data"`
+	UID    string                   `json:"uid"`
+	Labels map[string]string        `json:"labels"`
 
 	Annotations map[string]string `json:"annotations"`
 	Immutable   *bool             `json:"immutable,omitempty"`
 }
+
+type SecretValue struct {
+	Key   string `json:"key"`
+	Value []byte `json:"value"`
+}
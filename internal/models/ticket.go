@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// TicketRoute names the WebSocket route a ticket may be redeemed on.
+type TicketRoute string
+
+const (
+	TicketRoutePodLogs TicketRoute = "pods.logs"
+	TicketRoutePodExec TicketRoute = "pods.exec"
+	TicketRouteEvents  TicketRoute = "events.stream"
+)
+
+// CreateTicketParams binds a ticket to a single route and, for pod routes, a
+// single pod. Event stream tickets name no pod; the namespace filter is
+// checked against the caller's permissions when the stream opens.
+type CreateTicketParams struct {
+	Route     TicketRoute `json:"route" binding:"required,oneof=pods.logs pods.exec events.stream"`
+	Namespace string      `json:"namespace" binding:"required_unless=Route events.stream,excluded_if=Route events.stream"`
+	Pod       string      `json:"pod" binding:"required_unless=Route events.stream,excluded_if=Route events.stream"`
+}
+
+type WebSocketTicket struct {
+	Ticket    string    `json:"ticket"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
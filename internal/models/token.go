@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// RevokeTokenParams revokes either a single token by its jti or every token
+// issued to a user before a point in time. A token revocation needs the
+// token's exp as ExpiresAt so it lasts as long as the token does.
+type RevokeTokenParams struct {
+	TokenId   string    `json:"jti"`
+	ExpiresAt time.Time `json:"expires_at" binding:"required_with=TokenId"`
+	UserId    string    `json:"user_id"`
+	Before    time.Time `json:"before"`
+}
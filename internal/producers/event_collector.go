@@ -1,25 +1,34 @@
 package producers
 
 import (
+	"cluster-agent/internal/config"
 	"cluster-agent/internal/consumers"
 	"log"
+	"sync/atomic"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/tools/cache"
 )
 
 type EventCollector struct {
-	informer cache.SharedIndexInformer
-	batcher  *consumers.EventBatcher
+	informer   cache.SharedIndexInformer
+	aggregator *consumers.EventAggregator
+	configs    *config.Manager
+	stats      *consumers.EventStats
+	filter     atomic.Pointer[eventFilter]
 }
 
 func NewEventCollector(
-	batcher *consumers.EventBatcher,
+	aggregator *consumers.EventAggregator,
 	informer cache.SharedIndexInformer,
+	configs *config.Manager,
+	stats *consumers.EventStats,
 ) *EventCollector {
 	collector := &EventCollector{
-		informer: informer,
-		batcher:  batcher,
+		informer:   informer,
+		aggregator: aggregator,
+		configs:    configs,
+		stats:      stats,
 	}
 
 	_, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
@@ -40,9 +49,13 @@ func (e *EventCollector) handleEvent(obj interface{}) {
 		return
 	}
 
+	if e.filtered(event) {
+		return
+	}
+
 	log.Printf("New Event: %s/%s - %s", event.InvolvedObject.Kind, event.InvolvedObject.Name, event.Reason)
 
-	e.batcher.Push(event)
+	e.aggregator.Push(event)
 }
 
 func (e *EventCollector) handleEventUpdate(oldObj, newObj interface{}) {
@@ -50,5 +63,29 @@ func (e *EventCollector) handleEventUpdate(oldObj, newObj interface{}) {
 	if !ok {
 		return
 	}
-	e.batcher.Push(newEvent)
+
+	if e.filtered(newEvent) {
+		return
+	}
+
+	e.aggregator.Push(newEvent)
+}
+
+// filtered applies the configured event filters, recompiling them after a
+// configuration reload, and counts what they drop.
+func (e *EventCollector) filtered(event *corev1.Event) bool {
+	cfg := e.configs.Current()
+
+	filter := e.filter.Load()
+	if filter == nil || filter.source != cfg {
+		filter = newEventFilter(cfg)
+		e.filter.Store(filter)
+	}
+
+	rule, drop := filter.match(event)
+	if drop {
+		e.stats.RecordFiltered(rule)
+	}
+
+	return drop
 }
@@ -0,0 +1,76 @@
+package producers
+
+import (
+	"cluster-agent/internal/config"
+	"log"
+	"regexp"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+type eventRule struct {
+	name    string
+	drop    bool
+	rule    config.EventFilterRule
+	message *regexp.Regexp
+}
+
+// eventFilter is compiled from one configuration and replaced when a reload
+// swaps the configuration.
+type eventFilter struct {
+	source *config.Config
+	rules  []eventRule
+}
+
+func newEventFilter(cfg *config.Config) *eventFilter {
+	filter := &eventFilter{source: cfg}
+
+	for i, rule := range cfg.EventFilters {
+		compiled := eventRule{
+			name: rule.RuleName(i),
+			drop: rule.Action == config.EventFilterDrop,
+			rule: rule,
+		}
+
+		if rule.Message != "" {
+			message, err := regexp.Compile(rule.Message)
+			if err != nil {
+				// Validation rejects these. For configurations built elsewhere
+				// the rule is left out, so it matches no event and the rules
+				// after it still apply.
+				log.Printf("Skipping event filter %s: %v", compiled.name, err)
+				continue
+			}
+			compiled.message = message
+		}
+
+		filter.rules = append(filter.rules, compiled)
+	}
+
+	return filter
+}
+
+// match returns the rule deciding the event and whether it drops it. Events
+// matching no rule are kept.
+func (f *eventFilter) match(event *corev1.Event) (string, bool) {
+	for _, rule := range f.rules {
+		if rule.matches(event) {
+			return rule.name, rule.drop
+		}
+	}
+
+	return "", false
+}
+
+func (r *eventRule) matches(event *corev1.Event) bool {
+	return matchesAny(r.rule.Namespaces, event.Namespace) &&
+		matchesAny(r.rule.Types, event.Type) &&
+		matchesAny(r.rule.Reasons, event.Reason) &&
+		matchesAny(r.rule.Kinds, event.InvolvedObject.Kind) &&
+		(r.message == nil || r.message.MatchString(event.Message))
+}
+
+func matchesAny(values []string, value string) bool {
+	return len(values) == 0 || slices.Contains(values, value)
+}
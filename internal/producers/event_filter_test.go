@@ -0,0 +1,152 @@
+package producers
+
+import (
+	"cluster-agent/internal/config"
+	"cluster-agent/internal/consumers"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func testEvent(namespace, kind, reason, message string) *corev1.Event {
+	return &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Namespace: namespace},
+		InvolvedObject: corev1.ObjectReference{Kind: kind},
+		Type:           corev1.EventTypeNormal,
+		Reason:         reason,
+		Message:        message,
+	}
+}
+
+func TestEventFilter_Match(t *testing.T) {
+	filter := newEventFilter(&config.Config{EventFilters: []config.EventFilterRule{
+		{Name: "keep-payment-failures", Action: config.EventFilterKeep, Namespaces: []string{"payments"}, Reasons: []string{"Failed"}},
+		{Name: "drop-payments", Action: config.EventFilterDrop, Namespaces: []string{"payments"}},
+		{Name: "drop-probes", Action: config.EventFilterDrop, Kinds: []string{"Pod"}, Message: `^(Liveness|Readiness) probe`},
+		{Action: config.EventFilterDrop, Types: []string{corev1.EventTypeNormal}, Reasons: []string{"Pulled"}},
+		{Name: "broken", Action: config.EventFilterDrop, Message: `(`},
+	}})
+
+	type testCase struct {
+		name         string
+		event        *corev1.Event
+		expectedRule string
+		expectedDrop bool
+	}
+
+	tests := []testCase{
+		{
+			name:         "First matching rule wins over a later drop",
+			event:        testEvent("payments", "Pod", "Failed", "Error: ImagePullBackOff"),
+			expectedRule: "keep-payment-failures",
+		},
+		{
+			name:         "Namespace rule drops the rest of the namespace",
+			event:        testEvent("payments", "Pod", "Scheduled", "Successfully assigned"),
+			expectedRule: "drop-payments",
+			expectedDrop: true,
+		},
+		{
+			name:         "Message pattern and kind match",
+			event:        testEvent("default", "Pod", "Unhealthy", "Readiness probe failed: 503"),
+			expectedRule: "drop-probes",
+			expectedDrop: true,
+		},
+		{
+			name:  "Message pattern needs the kind as well",
+			event: testEvent("default", "Node", "Unhealthy", "Readiness probe failed: 503"),
+		},
+		{
+			name:  "Message pattern is not matched elsewhere in the message",
+			event: testEvent("default", "Pod", "Unhealthy", "Container restarted after Liveness probe"),
+		},
+		{
+			name:         "Unnamed rules are named by position",
+			event:        testEvent("default", "Pod", "Pulled", "Container image already present"),
+			expectedRule: "rule 4",
+			expectedDrop: true,
+		},
+		{
+			name:  "Rules with an invalid pattern match no event",
+			event: testEvent("default", "Service", "Created", "("),
+		},
+		{
+			name:  "Events matching no rule are kept",
+			event: testEvent("default", "Deployment", "ScalingReplicaSet", "Scaled up"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, drop := filter.match(tt.event)
+
+			assert.Equal(t, tt.expectedRule, rule)
+			assert.Equal(t, tt.expectedDrop, drop)
+		})
+	}
+
+	t.Run("Rules with an invalid pattern are skipped", func(t *testing.T) {
+		assert.Len(t, filter.rules, 4)
+	})
+}
+
+func filteredByRule(t *testing.T, configs *config.Manager, stats *consumers.EventStats) map[string]int64 {
+	t.Helper()
+
+	pipeline, err := consumers.NewEventBatcher(configs, nil, nil, stats).Stats(context.Background())
+	require.NoError(t, err)
+	return pipeline.FilteredByRule
+}
+
+func TestEventCollector_Filtered(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeFilters := func(filters string) {
+		t.Helper()
+		content := "api_url: https://api.example.com\njwks_url: https://auth.example.com/jwks.json\n" + filters
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+
+	writeFilters("event_filters:\n  - name: drop-kube-system\n    action: drop\n    namespaces: [kube-system]\n")
+	cfg, err := config.Load(path)
+	require.NoError(t, err)
+
+	configs := config.NewManager(cfg)
+	stats := consumers.NewEventStats()
+	collector := &EventCollector{configs: configs, stats: stats}
+
+	system := testEvent("kube-system", "Pod", "Pulled", "Container image already present")
+	payments := testEvent("payments", "Pod", "Pulled", "Container image already present")
+
+	t.Run("Dropped events are counted per rule", func(t *testing.T) {
+		assert.True(t, collector.filtered(system))
+		assert.True(t, collector.filtered(system))
+		assert.False(t, collector.filtered(payments))
+
+		assert.Equal(t, map[string]int64{"drop-kube-system": 2}, filteredByRule(t, configs, stats))
+	})
+
+	t.Run("Rules are recompiled after a reload", func(t *testing.T) {
+		compiled := collector.filter.Load()
+
+		writeFilters("event_filters:\n  - name: drop-payments\n    action: drop\n    namespaces: [payments]\n")
+		require.NoError(t, configs.Reload("test"))
+
+		assert.False(t, collector.filtered(system))
+		assert.True(t, collector.filtered(payments))
+		assert.NotSame(t, compiled, collector.filter.Load())
+
+		assert.Equal(t, map[string]int64{"drop-kube-system": 2, "drop-payments": 1}, filteredByRule(t, configs, stats))
+	})
+
+	t.Run("Rules are compiled once per configuration", func(t *testing.T) {
+		compiled := collector.filter.Load()
+		collector.filtered(payments)
+		assert.Same(t, compiled, collector.filter.Load())
+	})
+}
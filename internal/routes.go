@@ -0,0 +1,99 @@
+package internal
+
+import (
+	"cluster-agent/internal/auth/permissions"
+	"cluster-agent/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// access describes what a route requires of its caller.
+type access struct {
+	permission permissions.Permission
+	cluster    bool
+	audit      string
+	ticket     models.TicketRoute
+	limit      string
+}
+
+// public routes only require an authenticated caller.
+func public() access {
+	return access{}
+}
+
+func requires(permission permissions.Permission) access {
+	return access{permission: permission}
+}
+
+// requiresCluster is for cluster-scoped resources and administration, where a
+// grant limited to some namespaces is not enough.
+func requiresCluster(permission permissions.Permission) access {
+	return access{permission: permission, cluster: true}
+}
+
+func (a access) audited(action string) access {
+	a.audit = action
+	return a
+}
+
+// viaTicket authenticates the route with a WebSocket ticket instead of the
+// group's Authorization header middleware.
+func (a access) viaTicket(route models.TicketRoute) access {
+	a.ticket = route
+	return a
+}
+
+// limited rate limits a route outside the rate limited groups. Ticket routes
+// are limited by client address before the ticket is redeemed, so a rejected
+// request leaves the ticket usable.
+func (a access) limited(group string) access {
+	a.limit = group
+	return a
+}
+
+// handle registers a route behind the middleware its access requires and adds
+// it to the route catalog, so the catalog cannot drift from the router.
+func (app *App) handle(group *gin.RouterGroup, method, path string, a access, handlers ...gin.HandlerFunc) {
+	var chain []gin.HandlerFunc
+
+	authentication := models.RouteAuthToken
+	if a.ticket != "" {
+		authentication = models.RouteAuthTicket
+		if a.limit != "" {
+			chain = append(chain, app.rateLimitMiddleware.LimitClient(a.limit))
+		}
+		// Audit before the ticket is redeemed, so failed and replayed
+		// redemptions are recorded too.
+		if a.audit != "" {
+			chain = append(chain, app.auditMiddleware.Record(a.audit))
+		}
+		chain = append(chain, app.authorizedMiddleware.HandleTicket(a.ticket))
+	} else {
+		if a.limit != "" {
+			chain = append(chain, app.rateLimitMiddleware.Limit(a.limit))
+		}
+		// Audit before the permission check, so denied attempts are recorded too.
+		if a.audit != "" {
+			chain = append(chain, app.auditMiddleware.Record(a.audit))
+		}
+	}
+
+	switch {
+	case a.permission == "":
+	case a.cluster:
+		chain = append(chain, app.authorizedMiddleware.HasClusterPermission(a.permission))
+	default:
+		chain = append(chain, app.authorizedMiddleware.HasPermission(a.permission))
+	}
+
+	group.Handle(method, path, append(chain, handlers...)...)
+
+	app.routes.Add(models.RouteInfo{
+		Method:         method,
+		Path:           group.BasePath() + path,
+		Permission:     a.permission,
+		ClusterScoped:  a.cluster,
+		Authentication: authentication,
+		AuditAction:    a.audit,
+	})
+}
@@ -0,0 +1,213 @@
+package internal
+
+import (
+	"cluster-agent/internal/api/handlers"
+	"cluster-agent/internal/api/middleware"
+	"cluster-agent/internal/auth"
+	"cluster-agent/internal/auth/permissions"
+	"cluster-agent/internal/config"
+	"cluster-agent/internal/models"
+	"cluster-agent/internal/services"
+	"cluster-agent/internal/services/mock"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	testifyMock "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type routesTest struct {
+	app     *App
+	apiKeys *mock.APIKeyServiceMock
+	tickets *mock.TicketServiceMock
+	audit   *mock.AuditServiceMock
+}
+
+// rejectingLimiter turns every request away.
+type rejectingLimiter struct{}
+
+func (rejectingLimiter) Allow(context.Context, string, int, time.Duration) (bool, int, time.Duration, error) {
+	return false, 0, time.Minute, nil
+}
+
+// countingLimiter allows limit requests per key.
+type countingLimiter struct {
+	limit int
+	seen  map[string]int
+}
+
+func (l *countingLimiter) Allow(_ context.Context, key string, _ int, _ time.Duration) (bool, int, time.Duration, error) {
+	l.seen[key]++
+	if l.seen[key] > l.limit {
+		return false, 0, time.Minute, nil
+	}
+
+	return true, l.limit - l.seen[key], 0, nil
+}
+
+// newRoutesTest registers the real routes in front of empty handlers, so only
+// requests the middleware stops may be sent.
+func newRoutesTest(t *testing.T) *routesTest {
+	return newRateLimitedRoutesTest(t, nil, nil)
+}
+
+func newRateLimitedRoutesTest(t *testing.T, limits map[string]config.RateLimit, limiter middleware.RateLimiter) *routesTest {
+	gin.SetMode(gin.TestMode)
+
+	configs := config.NewManager(&config.Config{RateLimits: limits})
+	test := &routesTest{
+		apiKeys: new(mock.APIKeyServiceMock),
+		tickets: new(mock.TicketServiceMock),
+		audit:   new(mock.AuditServiceMock),
+	}
+
+	router, err := newRouter(&config.Config{})
+	require.NoError(t, err)
+
+	test.app = &App{
+		Router:   router,
+		Handlers: &handlers.HandlerContainer{},
+		authorizedMiddleware: middleware.NewAuthorizedMiddleware(
+			configs,
+			auth.NewConfigKeySet(configs),
+			new(mock.RevocationServiceMock),
+			test.tickets,
+			test.apiKeys,
+		),
+		auditMiddleware:     middleware.NewAuditMiddleware(test.audit),
+		rateLimitMiddleware: middleware.NewRateLimitMiddleware(configs, limiter),
+		routes:              handlers.NewRouteCatalog(),
+	}
+	test.app.setRoutes()
+
+	return test
+}
+
+func (r *routesTest) route(t *testing.T, method, path string) models.RouteInfo {
+	t.Helper()
+
+	for _, route := range r.app.routes.Routes() {
+		if route.Method == method && route.Path == path {
+			return route
+		}
+	}
+
+	require.Failf(t, "route not registered", "%s %s", method, path)
+	return models.RouteInfo{}
+}
+
+func TestRoutes_AuditedActions(t *testing.T) {
+	test := newRoutesTest(t)
+
+	type testCase struct {
+		method   string
+		path     string
+		expected string
+	}
+
+	tests := []testCase{
+		{method: http.MethodPost, path: "/api/v1/tokens/revoke", expected: "tokens.revoke"},
+		{method: http.MethodPost, path: "/api/v1/apikeys", expected: "apikeys.create"},
+		{method: http.MethodDelete, path: "/api/v1/apikeys/:id", expected: "apikeys.revoke"},
+		{method: http.MethodGet, path: "/api/v1/pods/:namespace/:name/exec", expected: "pods.exec"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.expected, test.route(t, tt.method, tt.path).AuditAction)
+		})
+	}
+}
+
+func TestRoutes_DeniedRevocationIsAudited(t *testing.T) {
+	test := newRoutesTest(t)
+	test.apiKeys.On("Authenticate", testifyMock.Anything, "ci.secret").Return(&auth.UserClaims{
+		UserId:      "apikey:ci",
+		Permissions: []permissions.Permission{permissions.TokensRevoke.Scoped("payments")},
+	}, nil)
+	test.audit.On("Record", testifyMock.MatchedBy(func(entry models.AuditEntry) bool {
+		return entry.Action == "tokens.revoke" &&
+			entry.UserId == "apikey:ci" &&
+			entry.Status == http.StatusForbidden
+	})).Return()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/tokens/revoke", nil)
+	req.Header.Set("X-API-Key", "ci.secret")
+	w := httptest.NewRecorder()
+	test.app.Router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	test.audit.AssertExpectations(t)
+}
+
+func TestRoutes_FailedTicketRedemptionIsAudited(t *testing.T) {
+	test := newRoutesTest(t)
+	test.tickets.On("Redeem", testifyMock.Anything, "ticket-1", models.CreateTicketParams{
+		Route:     models.TicketRoutePodExec,
+		Namespace: "payments",
+		Pod:       "web",
+	}).Return(nil, services.ErrInvalidTicket)
+	test.audit.On("Record", testifyMock.MatchedBy(func(entry models.AuditEntry) bool {
+		return entry.Action == "pods.exec" &&
+			entry.UserId == "" &&
+			entry.Status == http.StatusUnauthorized &&
+			entry.Target.Namespace == "payments" &&
+			entry.Target.Name == "web" &&
+			assert.ObjectsAreEqual([]string{"cat", "/etc/passwd"}, entry.Target.Command)
+	})).Return()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/pods/payments/web/exec?ticket=ticket-1&command=cat&command=/etc/passwd", nil)
+	w := httptest.NewRecorder()
+	test.app.Router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	test.audit.AssertExpectations(t)
+}
+
+func TestRoutes_RateLimitedTicketIsNotRedeemed(t *testing.T) {
+	test := newRateLimitedRoutesTest(t, map[string]config.RateLimit{
+		config.DefaultRateLimit: {Requests: 10, Window: time.Minute},
+	}, rejectingLimiter{})
+
+	paths := []string{
+		"/api/v1/pods/payments/web/logs?ticket=ticket-1",
+		"/api/v1/pods/payments/web/exec?ticket=ticket-1",
+		"/api/v1/events/stream?ticket=ticket-1",
+	}
+
+	for _, path := range paths {
+		t.Run(path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			test.app.Router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+
+			assert.Equal(t, http.StatusTooManyRequests, w.Code)
+			test.tickets.AssertNotCalled(t, "Redeem", testifyMock.Anything, testifyMock.Anything, testifyMock.Anything)
+		})
+	}
+}
+
+func TestRoutes_ForwardedForDoesNotResetClientLimit(t *testing.T) {
+	limiter := &countingLimiter{limit: 1, seen: make(map[string]int)}
+	test := newRateLimitedRoutesTest(t, map[string]config.RateLimit{
+		config.DefaultRateLimit: {Requests: 1, Window: time.Minute},
+	}, limiter)
+
+	codes := make([]int, 0, 2)
+	for _, forwardedFor := range []string{"198.51.100.1", "198.51.100.2"} {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/events/stream", nil)
+		req.RemoteAddr = "192.0.2.7:51234"
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+
+		w := httptest.NewRecorder()
+		test.app.Router.ServeHTTP(w, req)
+		codes = append(codes, w.Code)
+	}
+
+	assert.Equal(t, []int{http.StatusUnauthorized, http.StatusTooManyRequests}, codes)
+	assert.Equal(t, map[string]int{"events:ip:192.0.2.7": 2}, limiter.seen)
+}
@@ -0,0 +1,164 @@
+package services
+
+import (
+	"cluster-agent/internal/auth"
+	"cluster-agent/internal/models"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// apiKeyUserPrefix marks API key callers in audit logs and impersonation.
+const apiKeyUserPrefix = "apikey:"
+
+var ErrInvalidAPIKey = errors.New("invalid api key")
+
+type (
+	APIKeyService interface {
+		Create(ctx context.Context, createdBy string, params models.CreateAPIKeyParams) (*models.CreatedAPIKey, error)
+		List(ctx context.Context) ([]models.APIKey, error)
+		Revoke(ctx context.Context, id string) error
+		// Authenticate resolves a presented key to the claims it was issued with.
+		Authenticate(ctx context.Context, key string) (*auth.UserClaims, error)
+	}
+
+	APIKeyStorage interface {
+		SaveAPIKey(ctx context.Context, key models.StoredAPIKey, ttl time.Duration) error
+		// GetAPIKey returns nil when the key does not exist or has expired.
+		GetAPIKey(ctx context.Context, id string) (*models.StoredAPIKey, error)
+		ListAPIKeys(ctx context.Context) ([]models.StoredAPIKey, error)
+		DeleteAPIKey(ctx context.Context, id string) (bool, error)
+		TouchAPIKey(ctx context.Context, id string, usedAt time.Time) error
+	}
+)
+
+type apiKeyService struct {
+	storage APIKeyStorage
+}
+
+func NewAPIKeyService(storage APIKeyStorage) APIKeyService {
+	return &apiKeyService{
+		storage: storage,
+	}
+}
+
+func (s *apiKeyService) Create(ctx context.Context, createdBy string, params models.CreateAPIKeyParams) (*models.CreatedAPIKey, error) {
+	var ttl time.Duration
+	if params.ExpiresAt != nil {
+		ttl = time.Until(*params.ExpiresAt)
+		if ttl <= 0 {
+			return nil, fmt.Errorf("expires_at must be in the future")
+		}
+	}
+
+	id, err := randomToken(8, hex.EncodeToString)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := randomToken(32, base64.RawURLEncoding.EncodeToString)
+	if err != nil {
+		return nil, err
+	}
+
+	key := models.APIKey{
+		Id:          id,
+		Name:        params.Name,
+		Permissions: params.Permissions,
+		Namespaces:  params.Namespaces,
+		CreatedBy:   createdBy,
+		CreatedAt:   time.Now().UTC(),
+		ExpiresAt:   params.ExpiresAt,
+	}
+
+	stored := models.StoredAPIKey{APIKey: key, SecretHash: hashSecret(secret)}
+	if err := s.storage.SaveAPIKey(ctx, stored, ttl); err != nil {
+		return nil, fmt.Errorf("failed to save api key: %w", err)
+	}
+
+	return &models.CreatedAPIKey{
+		APIKey: key,
+		Key:    id + "." + secret,
+	}, nil
+}
+
+func (s *apiKeyService) List(ctx context.Context) ([]models.APIKey, error) {
+	stored, err := s.storage.ListAPIKeys(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+
+	keys := make([]models.APIKey, 0, len(stored))
+	for _, key := range stored {
+		keys = append(keys, key.APIKey)
+	}
+
+	return keys, nil
+}
+
+func (s *apiKeyService) Revoke(ctx context.Context, id string) error {
+	deleted, err := s.storage.DeleteAPIKey(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to revoke api key: %w", err)
+	}
+
+	if !deleted {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (s *apiKeyService) Authenticate(ctx context.Context, key string) (*auth.UserClaims, error) {
+	id, secret, ok := strings.Cut(key, ".")
+	if !ok || id == "" || secret == "" {
+		return nil, ErrInvalidAPIKey
+	}
+
+	stored, err := s.storage.GetAPIKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if stored == nil || subtle.ConstantTimeCompare([]byte(stored.SecretHash), []byte(hashSecret(secret))) != 1 {
+		return nil, ErrInvalidAPIKey
+	}
+
+	now := time.Now()
+	if stored.ExpiresAt != nil && !now.Before(*stored.ExpiresAt) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	// Usage tracking is best effort and must not lock machine clients out.
+	if err := s.storage.TouchAPIKey(ctx, id, now); err != nil {
+		log.Printf("Failed to record api key %s usage: %v", id, err)
+	}
+
+	return &auth.UserClaims{
+		UserId:      apiKeyUserPrefix + stored.Id,
+		Permissions: stored.Permissions,
+		Namespaces:  stored.Namespaces,
+	}, nil
+}
+
+func randomToken(size int, encode func([]byte) string) (string, error) {
+	data := make([]byte, size)
+	if _, err := rand.Read(data); err != nil {
+		return "", fmt.Errorf("failed to generate api key: %w", err)
+	}
+
+	return encode(data), nil
+}
+
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
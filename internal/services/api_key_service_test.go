@@ -0,0 +1,194 @@
+package services
+
+import (
+	"cluster-agent/internal/auth"
+	"cluster-agent/internal/auth/permissions"
+	"cluster-agent/internal/models"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryAPIKeys is an in-memory APIKeyStorage. Keys are returned until they
+// are deleted, whatever their expiry, like Redis between the expiry and the
+// key's TTL running out. Reads fail with err when it is set.
+type memoryAPIKeys struct {
+	keys     map[string]models.StoredAPIKey
+	err      error
+	touchErr error
+}
+
+func newMemoryAPIKeys() *memoryAPIKeys {
+	return &memoryAPIKeys{keys: make(map[string]models.StoredAPIKey)}
+}
+
+func (s *memoryAPIKeys) SaveAPIKey(_ context.Context, key models.StoredAPIKey, _ time.Duration) error {
+	s.keys[key.Id] = key
+	return nil
+}
+
+func (s *memoryAPIKeys) GetAPIKey(_ context.Context, id string) (*models.StoredAPIKey, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	key, ok := s.keys[id]
+	if !ok {
+		return nil, nil
+	}
+
+	return &key, nil
+}
+
+func (s *memoryAPIKeys) ListAPIKeys(context.Context) ([]models.StoredAPIKey, error) {
+	keys := make([]models.StoredAPIKey, 0, len(s.keys))
+	for _, key := range s.keys {
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+func (s *memoryAPIKeys) DeleteAPIKey(_ context.Context, id string) (bool, error) {
+	_, ok := s.keys[id]
+	delete(s.keys, id)
+	return ok, nil
+}
+
+func (s *memoryAPIKeys) TouchAPIKey(_ context.Context, id string, usedAt time.Time) error {
+	if s.touchErr != nil {
+		return s.touchErr
+	}
+
+	key := s.keys[id]
+	key.LastUsedAt = &usedAt
+	s.keys[id] = key
+	return nil
+}
+
+func TestAPIKeyService_Authenticate(t *testing.T) {
+	ctx := context.Background()
+	params := models.CreateAPIKeyParams{
+		Name:        "ci",
+		Permissions: []permissions.Permission{permissions.PodsView.Scoped("payments")},
+		Namespaces:  []string{"payments"},
+	}
+
+	create := func(t *testing.T, service APIKeyService, params models.CreateAPIKeyParams) *models.CreatedAPIKey {
+		t.Helper()
+		created, err := service.Create(ctx, "42", params)
+		require.NoError(t, err)
+		return created
+	}
+
+	t.Run("Valid key returns its claims", func(t *testing.T) {
+		storage := newMemoryAPIKeys()
+		service := NewAPIKeyService(storage)
+		created := create(t, service, params)
+
+		claims, err := service.Authenticate(ctx, created.Key)
+		require.NoError(t, err)
+		assert.Equal(t, &auth.UserClaims{
+			UserId:      apiKeyUserPrefix + created.Id,
+			Permissions: params.Permissions,
+			Namespaces:  params.Namespaces,
+		}, claims)
+		assert.NotNil(t, storage.keys[created.Id].LastUsedAt)
+	})
+
+	t.Run("Keys are stored by hash only", func(t *testing.T) {
+		storage := newMemoryAPIKeys()
+		created := create(t, NewAPIKeyService(storage), params)
+
+		_, secret, _ := strings.Cut(created.Key, ".")
+		assert.NotEqual(t, secret, storage.keys[created.Id].SecretHash)
+		assert.Equal(t, hashSecret(secret), storage.keys[created.Id].SecretHash)
+	})
+
+	t.Run("Secret mismatch", func(t *testing.T) {
+		service := NewAPIKeyService(newMemoryAPIKeys())
+		created := create(t, service, params)
+
+		_, err := service.Authenticate(ctx, created.Id+".wrong-secret")
+		assert.ErrorIs(t, err, ErrInvalidAPIKey)
+	})
+
+	t.Run("Malformed keys", func(t *testing.T) {
+		service := NewAPIKeyService(newMemoryAPIKeys())
+		created := create(t, service, params)
+
+		for _, key := range []string{"", created.Id, created.Id + ".", "." + created.Key} {
+			_, err := service.Authenticate(ctx, key)
+			assert.ErrorIs(t, err, ErrInvalidAPIKey, key)
+		}
+	})
+
+	t.Run("Unknown key", func(t *testing.T) {
+		service := NewAPIKeyService(newMemoryAPIKeys())
+
+		_, err := service.Authenticate(ctx, "unknown.secret")
+		assert.ErrorIs(t, err, ErrInvalidAPIKey)
+	})
+
+	t.Run("Revoked key is not served again", func(t *testing.T) {
+		service := NewAPIKeyService(newMemoryAPIKeys())
+		created := create(t, service, params)
+
+		_, err := service.Authenticate(ctx, created.Key)
+		require.NoError(t, err)
+
+		require.NoError(t, service.Revoke(ctx, created.Id))
+
+		_, err = service.Authenticate(ctx, created.Key)
+		assert.ErrorIs(t, err, ErrInvalidAPIKey)
+		assert.ErrorIs(t, service.Revoke(ctx, created.Id), ErrNotFound)
+	})
+
+	t.Run("Expired key still in storage", func(t *testing.T) {
+		storage := newMemoryAPIKeys()
+		service := NewAPIKeyService(storage)
+
+		expiresAt := time.Now().Add(time.Hour)
+		expiring := params
+		expiring.ExpiresAt = &expiresAt
+		created := create(t, service, expiring)
+
+		_, err := service.Authenticate(ctx, created.Key)
+		require.NoError(t, err)
+
+		stored := storage.keys[created.Id]
+		expired := time.Now().Add(-time.Second)
+		stored.ExpiresAt = &expired
+		storage.keys[created.Id] = stored
+
+		_, err = service.Authenticate(ctx, created.Key)
+		assert.ErrorIs(t, err, ErrInvalidAPIKey)
+	})
+
+	t.Run("Storage errors are not invalid keys", func(t *testing.T) {
+		storage := newMemoryAPIKeys()
+		service := NewAPIKeyService(storage)
+		created := create(t, service, params)
+
+		storage.err = errRedisDown
+
+		_, err := service.Authenticate(ctx, created.Key)
+		assert.ErrorIs(t, err, errRedisDown)
+		assert.NotErrorIs(t, err, ErrInvalidAPIKey)
+	})
+
+	t.Run("Failing usage tracking does not lock the key out", func(t *testing.T) {
+		storage := newMemoryAPIKeys()
+		service := NewAPIKeyService(storage)
+		created := create(t, service, params)
+
+		storage.touchErr = errRedisDown
+
+		_, err := service.Authenticate(ctx, created.Key)
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"cluster-agent/internal/models"
+	"context"
+	"errors"
+	"log"
+	"time"
+)
+
+const (
+	auditQueueSize    = 1000
+	auditBatchSize    = 100
+	auditDefaultLimit = 100
+)
+
+var ErrAuditQueryUnsupported = errors.New("no configured audit sink supports queries")
+
+type (
+	AuditService interface {
+		Record(entry models.AuditEntry)
+		Query(ctx context.Context, query models.AuditQuery) ([]models.AuditEntry, error)
+		Run(ctx context.Context)
+	}
+
+	AuditSink interface {
+		Name() string
+		Write(ctx context.Context, entries []models.AuditEntry) error
+	}
+
+	// AuditQuerier is implemented by sinks that can read back what they stored.
+	AuditQuerier interface {
+		Query(ctx context.Context, query models.AuditQuery) ([]models.AuditEntry, error)
+	}
+)
+
+type auditService struct {
+	sinks   []AuditSink
+	entries chan models.AuditEntry
+}
+
+func NewAuditService(sinks []AuditSink) AuditService {
+	return &auditService{
+		sinks:   sinks,
+		entries: make(chan models.AuditEntry, auditQueueSize),
+	}
+}
+
+// Record queues the entry so auditing never blocks the audited request.
+func (s *auditService) Record(entry models.AuditEntry) {
+	select {
+	case s.entries <- entry:
+	default:
+		log.Printf("Audit queue full, dropping entry: user=%s action=%s", entry.UserId, entry.Action)
+	}
+}
+
+func (s *auditService) Query(ctx context.Context, query models.AuditQuery) ([]models.AuditEntry, error) {
+	if query.Limit == 0 {
+		query.Limit = auditDefaultLimit
+	}
+
+	for _, sink := range s.sinks {
+		if querier, ok := sink.(AuditQuerier); ok {
+			return querier.Query(ctx, query)
+		}
+	}
+
+	return nil, ErrAuditQueryUnsupported
+}
+
+func (s *auditService) Run(ctx context.Context) {
+	batch := make([]models.AuditEntry, 0, auditBatchSize)
+
+	for {
+		select {
+		case entry := <-s.entries:
+			batch = append(batch[:0], entry)
+			batch = s.drain(batch)
+			s.write(ctx, batch)
+
+		case <-ctx.Done():
+			// Flush what is queued with a fresh deadline, the run context is gone.
+			flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			batch = s.drain(batch[:0])
+			if len(batch) > 0 {
+				s.write(flushCtx, batch)
+			}
+			return
+		}
+	}
+}
+
+func (s *auditService) drain(batch []models.AuditEntry) []models.AuditEntry {
+	for len(batch) < auditBatchSize {
+		select {
+		case entry := <-s.entries:
+			batch = append(batch, entry)
+		default:
+			return batch
+		}
+	}
+
+	return batch
+}
+
+func (s *auditService) write(ctx context.Context, batch []models.AuditEntry) {
+	for _, sink := range s.sinks {
+		if err := sink.Write(ctx, batch); err != nil {
+			log.Printf("Failed to write %d audit entries to %s sink: %v", len(batch), sink.Name(), err)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"cluster-agent/internal/k8s"
 	"cluster-agent/internal/models"
 	"context"
 	"fmt"
@@ -8,7 +9,6 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/kubernetes"
 )
 
 type ConfigMapService interface {
@@ -17,17 +17,22 @@ type ConfigMapService interface {
 }
 
 type configMapService struct {
-	clientset kubernetes.Interface
+	clients k8s.ClientProvider
 }
 
-func NewConfigMapService(c kubernetes.Interface) ConfigMapService {
+func NewConfigMapService(clients k8s.ClientProvider) ConfigMapService {
 	return &configMapService{
-		clientset: c,
+		clients: clients,
 	}
 }
 
 func (s *configMapService) List(ctx context.Context, namespace string) ([]models.ConfigMapListInfo, error) {
-	list, err := s.clientset.CoreV1().ConfigMaps(namespace).List(ctx, metav1.ListOptions{})
+	clientset, err := s.clients.Clientset(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := clientset.CoreV1().ConfigMaps(namespace).List(ctx, metav1.ListOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("failed list cm: %w", err)
 	}
@@ -40,7 +45,12 @@ func (s *configMapService) List(ctx context.Context, namespace string) ([]models
 }
 
 func (s *configMapService) Get(ctx context.Context, namespace, name string) (*models.ConfigMapDetails, error) {
-	item, err := s.clientset.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+	clientset, err := s.clients.Clientset(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := clientset.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
 		if k8serrors.IsNotFound(err) {
 			return nil, ErrNotFound
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"cluster-agent/internal/k8s"
 	"cluster-agent/internal/models"
 	"context"
 	"fmt"
@@ -8,8 +9,6 @@ import (
 	v1 "k8s.io/api/apps/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-
-	"k8s.io/client-go/kubernetes"
 )
 
 type DeploymentService interface {
@@ -21,17 +20,22 @@ type DeploymentService interface {
 }
 
 type deploymentService struct {
-	clientset kubernetes.Interface
+	clients k8s.ClientProvider
 }
 
-func NewDeploymentService(clientset kubernetes.Interface) DeploymentService {
+func NewDeploymentService(clients k8s.ClientProvider) DeploymentService {
 	return &deploymentService{
-		clientset: clientset,
+		clients: clients,
 	}
 }
 
 func (d *deploymentService) GetDeployments(ctx context.Context, namespace string) ([]models.DeploymentInfo, error) {
-	list, err := d.clientset.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
+	clientset, err := d.clients.Clientset(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := clientset.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to list deployments in namespace %s: %w", namespace, err)
 	}
@@ -53,7 +57,12 @@ func (d *deploymentService) GetDeployments(ctx context.Context, namespace string
 }
 
 func (d *deploymentService) GetDeployment(ctx context.Context, namespace string, deploymentName string) (*v1.Deployment, error) {
-	deployment, err := d.clientset.AppsV1().Deployments(namespace).Get(ctx, deploymentName, metav1.GetOptions{})
+	clientset, err := d.clients.Clientset(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	deployment, err := clientset.AppsV1().Deployments(namespace).Get(ctx, deploymentName, metav1.GetOptions{})
 
 	if err != nil {
 		if k8serrors.IsNotFound(err) {
@@ -71,7 +80,12 @@ func (d *deploymentService) GetDeployment(ctx context.Context, namespace string,
 }
 
 func (d *deploymentService) CreateDeployment(ctx context.Context, deployment *v1.Deployment) error {
-	_, err := d.clientset.AppsV1().Deployments(deployment.Namespace).Create(ctx, deployment, metav1.CreateOptions{})
+	clientset, err := d.clients.Clientset(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = clientset.AppsV1().Deployments(deployment.Namespace).Create(ctx, deployment, metav1.CreateOptions{})
 
 	if err != nil {
 		return fmt.Errorf("failed to create deployment: %w", err)
@@ -81,7 +95,12 @@ func (d *deploymentService) CreateDeployment(ctx context.Context, deployment *v1
 }
 
 func (d *deploymentService) DeleteDeployment(ctx context.Context, namespace string, deploymentName string) error {
-	err := d.clientset.AppsV1().Deployments(namespace).Delete(ctx, deploymentName, metav1.DeleteOptions{})
+	clientset, err := d.clients.Clientset(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = clientset.AppsV1().Deployments(namespace).Delete(ctx, deploymentName, metav1.DeleteOptions{})
 	if err != nil {
 		if k8serrors.IsNotFound(err) {
 			return ErrNotFound
@@ -94,8 +113,13 @@ func (d *deploymentService) DeleteDeployment(ctx context.Context, namespace stri
 }
 
 func (d *deploymentService) ScaleDeployment(ctx context.Context, params models.ScaleDeploymentParams) error {
+	clientset, err := d.clients.Clientset(ctx)
+	if err != nil {
+		return err
+	}
+
 	return executeWithRetry("scale deployment", func() error {
-		scale, err := d.clientset.AppsV1().Deployments(params.Namespace).GetScale(ctx, params.Name, metav1.GetOptions{})
+		scale, err := clientset.AppsV1().Deployments(params.Namespace).GetScale(ctx, params.Name, metav1.GetOptions{})
 		if err != nil {
 			if k8serrors.IsNotFound(err) {
 				return ErrNotFound
@@ -105,7 +129,7 @@ func (d *deploymentService) ScaleDeployment(ctx context.Context, params models.S
 		}
 
 		scale.Spec.Replicas = params.Replicas
-		_, err = d.clientset.AppsV1().Deployments(params.Namespace).UpdateScale(ctx, params.Name, scale, metav1.UpdateOptions{})
+		_, err = clientset.AppsV1().Deployments(params.Namespace).UpdateScale(ctx, params.Name, scale, metav1.UpdateOptions{})
 		return err
 	})
 }
@@ -0,0 +1,12 @@
+package services
+
+import (
+	"cluster-agent/internal/models"
+	"context"
+)
+
+// EventPipelineService reports what happened to the events the agent
+// forwards. It is implemented by consumers.EventBatcher.
+type EventPipelineService interface {
+	Stats(ctx context.Context) (*models.EventPipelineStats, error)
+}
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"cluster-agent/internal/config"
+	"cluster-agent/internal/models"
+	"context"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	eventDefaultLimit = 100
+
+	// eventWatchBuffer is how many events may wait for a slow watcher before
+	// it misses some. The informer is shared and must not wait for clients.
+	eventWatchBuffer = 64
+)
+
+type EventService interface {
+	List(ctx context.Context, query models.EventQuery) ([]models.ClusterEvent, error)
+	// Watch sends matching events as they are created or repeated until ctx
+	// is canceled. The channel is never closed, so callers select on ctx.
+	Watch(ctx context.Context, query models.EventQuery) (<-chan models.ClusterEvent, error)
+}
+
+type eventService struct {
+	informer cache.SharedIndexInformer
+	configs  *config.Manager
+}
+
+func NewEventService(informer cache.SharedIndexInformer, configs *config.Manager) EventService {
+	return &eventService{
+		informer: informer,
+		configs:  configs,
+	}
+}
+
+// List returns the events the informer holds, most recent first.
+func (s *eventService) List(_ context.Context, query models.EventQuery) ([]models.ClusterEvent, error) {
+	if query.Limit == 0 {
+		query.Limit = eventDefaultLimit
+	}
+
+	clusterID := s.configs.Current().ClusterID
+	result := make([]models.ClusterEvent, 0)
+
+	for _, obj := range s.informer.GetStore().List() {
+		event, ok := obj.(*corev1.Event)
+		if !ok {
+			continue
+		}
+
+		mapped := models.NewClusterEvent(event, clusterID)
+		if query.Matches(mapped) {
+			result = append(result, *mapped)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].LastTimestamp.After(result[j].LastTimestamp)
+	})
+
+	if len(result) > query.Limit {
+		result = result[:query.Limit]
+	}
+
+	return result, nil
+}
+
+func (s *eventService) Watch(ctx context.Context, query models.EventQuery) (<-chan models.ClusterEvent, error) {
+	events := make(chan models.ClusterEvent, eventWatchBuffer)
+
+	send := func(obj interface{}) {
+		event, ok := obj.(*corev1.Event)
+		if !ok {
+			return
+		}
+
+		mapped := models.NewClusterEvent(event, s.configs.Current().ClusterID)
+		if !query.Matches(mapped) {
+			return
+		}
+
+		select {
+		case events <- *mapped:
+		default:
+		}
+	}
+
+	registration, err := s.informer.AddEventHandler(cache.ResourceEventHandlerDetailedFuncs{
+		// The events already in the cache are replayed on registration, List
+		// serves those.
+		AddFunc: func(obj interface{}, isInInitialList bool) {
+			if !isInInitialList {
+				send(obj)
+			}
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			// Periodic resyncs deliver unchanged events.
+			oldEvent, oldOk := oldObj.(*corev1.Event)
+			newEvent, newOk := newObj.(*corev1.Event)
+			if oldOk && newOk && oldEvent.ResourceVersion == newEvent.ResourceVersion {
+				return
+			}
+			send(newObj)
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		<-ctx.Done()
+		s.informer.RemoveEventHandler(registration)
+	}()
+
+	return events, nil
+}
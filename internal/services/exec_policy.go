@@ -0,0 +1,68 @@
+package services
+
+import (
+	"cluster-agent/internal/config"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+var ErrExecNotAllowed = errors.New("exec not allowed")
+
+// readOnlyCommands can inspect a container but not change it. Commands that
+// can run others (env, find -exec, xargs, sh -c) are deliberately missing.
+var readOnlyCommands = []string{
+	"cat", "df", "du", "free", "head", "id", "ls",
+	"printenv", "ps", "pwd", "stat", "tail", "uptime", "whoami",
+}
+
+// readOnlyWithoutArguments only read when run bare: "date -s" sets the clock
+// and "hostname name" renames the host.
+var readOnlyWithoutArguments = []string{"date", "hostname"}
+
+// authorizeExec checks a command against the namespace's exec policy. An
+// empty command asks for an interactive shell.
+func authorizeExec(policies map[string]config.ExecPolicy, namespace string, command []string) error {
+	policy, ok := policies[namespace]
+	if !ok {
+		policy, ok = policies[config.DefaultExecPolicy]
+	}
+
+	if !ok {
+		policy = config.ExecPolicy{Mode: config.ExecModeShell}
+	}
+
+	switch policy.Mode {
+	case config.ExecModeShell:
+		return nil
+
+	case config.ExecModeDeny:
+		return fmt.Errorf("%w: exec is disabled in namespace %s", ErrExecNotAllowed, namespace)
+
+	case config.ExecModeReadOnly, config.ExecModeAllowlist:
+		allowed := readOnlyCommands
+		if policy.Mode == config.ExecModeAllowlist {
+			allowed = policy.Commands
+		}
+
+		if len(command) == 0 {
+			return fmt.Errorf("%w: interactive shells are disabled in namespace %s, pass a command", ErrExecNotAllowed, namespace)
+		}
+
+		if policy.Mode == config.ExecModeReadOnly && slices.Contains(readOnlyWithoutArguments, command[0]) {
+			if len(command) > 1 {
+				return fmt.Errorf("%w: command %q is only allowed without arguments in namespace %s", ErrExecNotAllowed, command[0], namespace)
+			}
+			return nil
+		}
+
+		if !slices.Contains(allowed, command[0]) {
+			return fmt.Errorf("%w: command %q is not allowed in namespace %s", ErrExecNotAllowed, command[0], namespace)
+		}
+
+		return nil
+
+	default:
+		return fmt.Errorf("%w: unknown exec policy %q for namespace %s", ErrExecNotAllowed, policy.Mode, namespace)
+	}
+}
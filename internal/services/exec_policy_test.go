@@ -0,0 +1,55 @@
+package services
+
+import (
+	"cluster-agent/internal/config"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthorizeExec(t *testing.T) {
+	type testCase struct {
+		name      string
+		policies  map[string]config.ExecPolicy
+		namespace string
+		command   []string
+		allowed   bool
+	}
+
+	policies := map[string]config.ExecPolicy{
+		"payments":               {Mode: config.ExecModeReadOnly},
+		"batch":                  {Mode: config.ExecModeAllowlist, Commands: []string{"/app/healthcheck"}},
+		"default":                {Mode: config.ExecModeShell},
+		config.DefaultExecPolicy: {Mode: config.ExecModeDeny},
+	}
+
+	tests := []testCase{
+		{name: "Read-only command", policies: policies, namespace: "payments", command: []string{"ls", "-la"}, allowed: true},
+		{name: "Read-only printenv", policies: policies, namespace: "payments", command: []string{"printenv"}, allowed: true},
+		{name: "Read-only bare date", policies: policies, namespace: "payments", command: []string{"date"}, allowed: true},
+		{name: "Read-only rejects setting the date", policies: policies, namespace: "payments", command: []string{"date", "-s", "2030-01-01"}},
+		{name: "Read-only rejects renaming the host", policies: policies, namespace: "payments", command: []string{"hostname", "foo"}},
+		{name: "Read-only rejects env running a shell", policies: policies, namespace: "payments", command: []string{"env", "/bin/sh"}},
+		{name: "Read-only rejects shell", policies: policies, namespace: "payments", command: []string{"sh", "-c", "ls"}},
+		{name: "Read-only rejects interactive shell", policies: policies, namespace: "payments"},
+		{name: "Allowlisted command", policies: policies, namespace: "batch", command: []string{"/app/healthcheck"}, allowed: true},
+		{name: "Command outside allowlist", policies: policies, namespace: "batch", command: []string{"ls"}},
+		{name: "Policy for the default namespace", policies: policies, namespace: "default", allowed: true},
+		{name: "Fallback policy", policies: policies, namespace: "frontend", command: []string{"ls"}},
+		{name: "Default namespace policy is not the fallback", policies: map[string]config.ExecPolicy{"default": {Mode: config.ExecModeDeny}}, namespace: "frontend", allowed: true},
+		{name: "No policies", namespace: "frontend", allowed: true},
+		{name: "Unknown mode", policies: map[string]config.ExecPolicy{"payments": {Mode: "sudo"}}, namespace: "payments", command: []string{"ls"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := authorizeExec(tc.policies, tc.namespace, tc.command)
+
+			if tc.allowed {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrExecNotAllowed)
+			}
+		})
+	}
+}
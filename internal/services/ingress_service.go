@@ -1,13 +1,13 @@
 package services
 
 import (
+	"cluster-agent/internal/k8s"
 	"cluster-agent/internal/models"
 	"context"
 	"fmt"
 
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/kubernetes"
 )
 
 type IngressService interface {
@@ -15,12 +15,17 @@ type IngressService interface {
 	Get(ctx context.Context, namespace, name string) (*models.IngressDetails, error)
 }
 
-type ingressService struct{ clientset kubernetes.Interface }
+type ingressService struct{ clients k8s.ClientProvider }
 
-func NewIngressService(c kubernetes.Interface) IngressService { return &ingressService{c} }
+func NewIngressService(clients k8s.ClientProvider) IngressService { return &ingressService{clients} }
 
 func (s *ingressService) List(ctx context.Context, namespace string) ([]models.IngressListInfo, error) {
-	list, err := s.clientset.NetworkingV1().Ingresses(namespace).List(ctx, metav1.ListOptions{})
+	clientset, err := s.clients.Clientset(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := clientset.NetworkingV1().Ingresses(namespace).List(ctx, metav1.ListOptions{})
 	if err != nil {
 		return nil, err
 	}
@@ -48,7 +53,12 @@ func (s *ingressService) List(ctx context.Context, namespace string) ([]models.I
 }
 
 func (s *ingressService) Get(ctx context.Context, namespace, name string) (*models.IngressDetails, error) {
-	item, err := s.clientset.NetworkingV1().Ingresses(namespace).Get(ctx, name, metav1.GetOptions{})
+	clientset, err := s.clients.Clientset(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := clientset.NetworkingV1().Ingresses(namespace).Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
 		if k8serrors.IsNotFound(err) {
 			return nil, ErrNotFound
@@ -0,0 +1,39 @@
+package mock
+
+import (
+	"cluster-agent/internal/auth"
+	"cluster-agent/internal/models"
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type APIKeyServiceMock struct {
+	mock.Mock
+}
+
+func (m *APIKeyServiceMock) Create(ctx context.Context, createdBy string, params models.CreateAPIKeyParams) (*models.CreatedAPIKey, error) {
+	args := m.Called(ctx, createdBy, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.CreatedAPIKey), args.Error(1)
+}
+
+func (m *APIKeyServiceMock) List(ctx context.Context) ([]models.APIKey, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]models.APIKey), args.Error(1)
+}
+
+func (m *APIKeyServiceMock) Revoke(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *APIKeyServiceMock) Authenticate(ctx context.Context, key string) (*auth.UserClaims, error) {
+	args := m.Called(ctx, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*auth.UserClaims), args.Error(1)
+}
@@ -0,0 +1,25 @@
+package mock
+
+import (
+	"cluster-agent/internal/models"
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type AuditServiceMock struct {
+	mock.Mock
+}
+
+func (m *AuditServiceMock) Record(entry models.AuditEntry) {
+	m.Called(entry)
+}
+
+func (m *AuditServiceMock) Query(ctx context.Context, query models.AuditQuery) ([]models.AuditEntry, error) {
+	args := m.Called(ctx, query)
+	return args.Get(0).([]models.AuditEntry), args.Error(1)
+}
+
+func (m *AuditServiceMock) Run(ctx context.Context) {
+	m.Called(ctx)
+}
@@ -0,0 +1,17 @@
+package mock
+
+import (
+	"cluster-agent/internal/models"
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type EventPipelineServiceMock struct {
+	mock.Mock
+}
+
+func (m *EventPipelineServiceMock) Stats(ctx context.Context) (*models.EventPipelineStats, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(*models.EventPipelineStats), args.Error(1)
+}
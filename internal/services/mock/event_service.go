@@ -0,0 +1,25 @@
+package mock
+
+import (
+	"cluster-agent/internal/models"
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type EventServiceMock struct {
+	mock.Mock
+}
+
+func (m *EventServiceMock) List(ctx context.Context, query models.EventQuery) ([]models.ClusterEvent, error) {
+	args := m.Called(ctx, query)
+	return args.Get(0).([]models.ClusterEvent), args.Error(1)
+}
+
+func (m *EventServiceMock) Watch(ctx context.Context, query models.EventQuery) (<-chan models.ClusterEvent, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(<-chan models.ClusterEvent), args.Error(1)
+}
@@ -0,0 +1,16 @@
+package mock
+
+import (
+	"cluster-agent/internal/config"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type ReloadServiceMock struct {
+	mock.Mock
+}
+
+func (m *ReloadServiceMock) Status() config.ReloadStatus {
+	args := m.Called()
+	return args.Get(0).(config.ReloadStatus)
+}
@@ -0,0 +1,28 @@
+package mock
+
+import (
+	"cluster-agent/internal/auth"
+	"context"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type RevocationServiceMock struct {
+	mock.Mock
+}
+
+func (m *RevocationServiceMock) RevokeToken(ctx context.Context, tokenId string, expiresAt time.Time) error {
+	args := m.Called(ctx, tokenId, expiresAt)
+	return args.Error(0)
+}
+
+func (m *RevocationServiceMock) RevokeUser(ctx context.Context, userId string, before time.Time) error {
+	args := m.Called(ctx, userId, before)
+	return args.Error(0)
+}
+
+func (m *RevocationServiceMock) IsRevoked(ctx context.Context, claims *auth.UserClaims) (bool, error) {
+	args := m.Called(ctx, claims)
+	return args.Bool(0), args.Error(1)
+}
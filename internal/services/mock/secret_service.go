@@ -20,3 +20,8 @@ func (m *SecretServiceMock) Get(ctx context.Context, namespace, name string) (*m
 	args := m.Called(ctx, namespace, name)
 	return args.Get(0).(*models.SecretDetails), args.Error(1)
 }
+
+func (m *SecretServiceMock) Reveal(ctx context.Context, namespace, name, key string) (*models.SecretValue, error) {
+	args := m.Called(ctx, namespace, name, key)
+	return args.Get(0).(*models.SecretValue), args.Error(1)
+}
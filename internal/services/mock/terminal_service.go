@@ -1,6 +1,8 @@
 package mock
 
 import (
+	"context"
+
 	"github.com/stretchr/testify/mock"
 	"k8s.io/client-go/tools/remotecommand"
 )
@@ -9,8 +11,13 @@ type TerminalServiceMock struct {
 	mock.Mock
 }
 
-func (m *TerminalServiceMock) GetAuthExecutor(namespace, podName, container string) (remotecommand.Executor, error) {
-	args := m.Called(namespace, podName, container)
+func (m *TerminalServiceMock) Authorize(namespace string, command []string) error {
+	args := m.Called(namespace, command)
+	return args.Error(0)
+}
+
+func (m *TerminalServiceMock) GetAuthExecutor(ctx context.Context, namespace, podName, container string, command []string) (remotecommand.Executor, error) {
+	args := m.Called(ctx, namespace, podName, container, command)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
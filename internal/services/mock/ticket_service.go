@@ -0,0 +1,29 @@
+package mock
+
+import (
+	"cluster-agent/internal/auth"
+	"cluster-agent/internal/models"
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type TicketServiceMock struct {
+	mock.Mock
+}
+
+func (m *TicketServiceMock) Issue(ctx context.Context, claims *auth.UserClaims, params models.CreateTicketParams) (*models.WebSocketTicket, error) {
+	args := m.Called(ctx, claims, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.WebSocketTicket), args.Error(1)
+}
+
+func (m *TicketServiceMock) Redeem(ctx context.Context, ticket string, binding models.CreateTicketParams) (*auth.UserClaims, error) {
+	args := m.Called(ctx, ticket, binding)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*auth.UserClaims), args.Error(1)
+}
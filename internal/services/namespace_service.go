@@ -1,10 +1,10 @@
 package services
 
 import (
+	"cluster-agent/internal/k8s"
 	"context"
 	"fmt"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/kubernetes"
 )
 
 type NamespaceService interface {
@@ -12,17 +12,22 @@ type NamespaceService interface {
 }
 
 type namespaceService struct {
-	clientset kubernetes.Interface
+	clients k8s.ClientProvider
 }
 
-func NewNamespaceService(clientset kubernetes.Interface) NamespaceService {
+func NewNamespaceService(clients k8s.ClientProvider) NamespaceService {
 	return &namespaceService{
-		clientset: clientset,
+		clients: clients,
 	}
 }
 
 func (n *namespaceService) GetNamespaces(ctx context.Context) ([]string, error) {
-	list, err := n.clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	clientset, err := n.clients.Clientset(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to list namespaces: %w", err)
 	}
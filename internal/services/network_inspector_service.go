@@ -2,6 +2,7 @@ package services
 
 import (
 	"bufio"
+	"cluster-agent/internal/k8s"
 	"context"
 	"encoding/hex"
 	"fmt"
@@ -10,8 +11,6 @@ import (
 	"strconv"
 	"strings"
 
-	"k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/remotecommand"
 )
 
@@ -66,14 +65,12 @@ type NetworkInspectorService interface {
 }
 
 type networkInspectorService struct {
-	clientset kubernetes.Interface
-	config    *rest.Config
+	clients k8s.ClientProvider
 }
 
-func NewNetworkInspectorService(clientset kubernetes.Interface, config *rest.Config) NetworkInspectorService {
+func NewNetworkInspectorService(clients k8s.ClientProvider) NetworkInspectorService {
 	return &networkInspectorService{
-		clientset: clientset,
-		config:    config,
+		clients: clients,
 	}
 }
 
@@ -84,7 +81,17 @@ func (s *networkInspectorService) GetPodNetworkConnections(ctx context.Context,
 		"cat /proc/net/tcp /proc/net/tcp6 2>/dev/null",
 	}
 
-	req := s.clientset.CoreV1().RESTClient().Post().
+	clientset, err := s.clients.Clientset(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := s.clients.Config(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	req := clientset.CoreV1().RESTClient().Post().
 		Resource("pods").
 		Name(podName).
 		Namespace(namespace).
@@ -99,7 +106,7 @@ func (s *networkInspectorService) GetPodNetworkConnections(ctx context.Context,
 		req.Param("command", c)
 	}
 
-	executor, err := remotecommand.NewSPDYExecutor(s.config, "POST", req.URL())
+	executor, err := remotecommand.NewSPDYExecutor(config, "POST", req.URL())
 	if err != nil {
 		return nil, fmt.Errorf("failed to create executor: %w", err)
 	}
@@ -1,10 +1,10 @@
 package services
 
 import (
+	"cluster-agent/internal/k8s"
 	"cluster-agent/internal/models"
 	"context"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/kubernetes"
 )
 
 type NodeService interface {
@@ -12,17 +12,22 @@ type NodeService interface {
 }
 
 type nodeService struct {
-	clientset kubernetes.Interface
+	clients k8s.ClientProvider
 }
 
-func NewNodeService(clientset kubernetes.Interface) NodeService {
+func NewNodeService(clients k8s.ClientProvider) NodeService {
 	return &nodeService{
-		clientset: clientset,
+		clients: clients,
 	}
 }
 
 func (n *nodeService) GetNodes(ctx context.Context) ([]models.Node, error) {
-	nodes, err := n.clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	clientset, err := n.clients.Clientset(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes, err := clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
 	if err != nil {
 		return nil, err
 	}
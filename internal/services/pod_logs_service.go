@@ -1,10 +1,10 @@
 package services
 
 import (
+	"cluster-agent/internal/k8s"
 	"context"
 	"io"
 	corev1 "k8s.io/api/core/v1"
-	"k8s.io/client-go/kubernetes"
 )
 
 type PodLogsService interface {
@@ -12,12 +12,12 @@ type PodLogsService interface {
 }
 
 type podLogsService struct {
-	client kubernetes.Interface
+	clients k8s.ClientProvider
 }
 
-func NewPodLogsService(client kubernetes.Interface) PodLogsService {
+func NewPodLogsService(clients k8s.ClientProvider) PodLogsService {
 	return &podLogsService{
-		client: client,
+		clients: clients,
 	}
 }
 
@@ -31,7 +31,12 @@ func (p podLogsService) StreamLogs(ctx context.Context, namespace string, podNam
 
 	*logOpts.TailLines = 100
 
-	req := p.client.CoreV1().Pods(namespace).GetLogs(podName, logOpts)
+	clientset, err := p.clients.Clientset(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	req := clientset.CoreV1().Pods(namespace).GetLogs(podName, logOpts)
 
 	stream, err := req.Stream(ctx)
 
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"cluster-agent/internal/k8s"
 	"cluster-agent/internal/models"
 	"context"
 	"fmt"
@@ -8,7 +9,6 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/kubernetes"
 )
 
 type PodService interface {
@@ -17,17 +17,22 @@ type PodService interface {
 }
 
 type podService struct {
-	clientset kubernetes.Interface
+	clients k8s.ClientProvider
 }
 
-func NewPodService(clientset kubernetes.Interface) PodService {
+func NewPodService(clients k8s.ClientProvider) PodService {
 	return &podService{
-		clientset: clientset,
+		clients: clients,
 	}
 }
 
 func (p *podService) GetPods(ctx context.Context, namespace string) ([]models.PodListInfo, error) {
-	list, err := p.clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	clientset, err := p.clients.Clientset(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to list pods in namespace %s: %w", namespace, err)
@@ -61,7 +66,12 @@ func (p *podService) GetPods(ctx context.Context, namespace string) ([]models.Po
 }
 
 func (p *podService) GetPod(ctx context.Context, namespace, name string) (*models.PodDetails, error) {
-	rawPod, err := p.clientset.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+	clientset, err := p.clients.Clientset(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rawPod, err := clientset.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
 		if k8serrors.IsNotFound(err) {
 			return nil, ErrNotFound
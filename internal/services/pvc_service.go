@@ -1,6 +1,7 @@
 package services
 
 import (
+	"cluster-agent/internal/k8s"
 	"cluster-agent/internal/models"
 	"context"
 	"fmt"
@@ -9,7 +10,6 @@ import (
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
-	"k8s.io/client-go/kubernetes"
 	v1 "k8s.io/client-go/listers/core/v1"
 )
 
@@ -19,22 +19,27 @@ type PVCService interface {
 }
 
 type pvcService struct {
-	clientset kubernetes.Interface
+	clients   k8s.ClientProvider
 	podLister v1.PodLister
 }
 
 func NewPVCService(
-	c kubernetes.Interface,
+	clients k8s.ClientProvider,
 	podLister v1.PodLister,
 ) PVCService {
 	return &pvcService{
-		clientset: c,
+		clients:   clients,
 		podLister: podLister,
 	}
 }
 
 func (s *pvcService) List(ctx context.Context, namespace string) ([]models.PVCListInfo, error) {
-	list, err := s.clientset.CoreV1().PersistentVolumeClaims(namespace).List(ctx, metav1.ListOptions{})
+	clientset, err := s.clients.Clientset(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := clientset.CoreV1().PersistentVolumeClaims(namespace).List(ctx, metav1.ListOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("failed list pvcs: %w", err)
 	}
@@ -47,7 +52,12 @@ func (s *pvcService) List(ctx context.Context, namespace string) ([]models.PVCLi
 }
 
 func (s *pvcService) Get(ctx context.Context, namespace, name string) (*models.PVCDetails, error) {
-	item, err := s.clientset.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, name, metav1.GetOptions{})
+	clientset, err := s.clients.Clientset(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := clientset.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
 		if k8serrors.IsNotFound(err) {
 			return nil, ErrNotFound
@@ -0,0 +1,9 @@
+package services
+
+import "cluster-agent/internal/config"
+
+// ReloadService reports which configuration the agent is running with. It is
+// implemented by config.Manager, which performs the reloads.
+type ReloadService interface {
+	Status() config.ReloadStatus
+}
@@ -0,0 +1,154 @@
+package services
+
+import (
+	"cluster-agent/internal/auth"
+	"cluster-agent/internal/config"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errRedisDown = errors.New("redis: connection refused")
+
+// memoryRevocations is an in-memory RevocationStorage. Reads fail with err
+// when it is set.
+type memoryRevocations struct {
+	tokens map[string]time.Duration
+	users  map[string]time.Time
+	err    error
+}
+
+func newMemoryRevocations() *memoryRevocations {
+	return &memoryRevocations{
+		tokens: make(map[string]time.Duration),
+		users:  make(map[string]time.Time),
+	}
+}
+
+func (s *memoryRevocations) RevokeToken(_ context.Context, tokenId string, ttl time.Duration) error {
+	s.tokens[tokenId] = ttl
+	return nil
+}
+
+func (s *memoryRevocations) IsTokenRevoked(_ context.Context, tokenId string) (bool, error) {
+	if s.err != nil {
+		return false, s.err
+	}
+
+	_, ok := s.tokens[tokenId]
+	return ok, nil
+}
+
+func (s *memoryRevocations) SetUserRevokedBefore(_ context.Context, userId string, before time.Time) error {
+	s.users[userId] = before
+	return nil
+}
+
+func (s *memoryRevocations) GetUserRevokedBefore(_ context.Context, userId string) (time.Time, error) {
+	if s.err != nil {
+		return time.Time{}, s.err
+	}
+
+	return s.users[userId], nil
+}
+
+func TestRevocationService_IsRevoked(t *testing.T) {
+	revokedAt := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	claims := func(id, userId string, issuedAt time.Time) *auth.UserClaims {
+		claims := &auth.UserClaims{RegisteredClaims: jwt.RegisteredClaims{ID: id}, UserId: userId}
+		if !issuedAt.IsZero() {
+			claims.IssuedAt = jwt.NewNumericDate(issuedAt)
+		}
+		return claims
+	}
+
+	storage := newMemoryRevocations()
+	storage.tokens["leaked"] = time.Hour
+	storage.users["42"] = revokedAt
+	storage.users["43"] = revokedAt.Add(300 * time.Millisecond)
+	service := NewRevocationService(storage, config.NewManager(&config.Config{JWTLeeway: 30 * time.Second}))
+
+	// Most issuers send whole seconds, which NewNumericDate truncates to.
+	fractional := claims("new", "43", time.Time{})
+	fractional.IssuedAt = &jwt.NumericDate{Time: revokedAt.Add(700 * time.Millisecond)}
+
+	type testCase struct {
+		name     string
+		claims   *auth.UserClaims
+		expected bool
+	}
+
+	tests := []testCase{
+		{name: "Revoked token id", claims: claims("leaked", "7", revokedAt.Add(time.Hour)), expected: true},
+		{name: "Other token id", claims: claims("fresh", "7", revokedAt.Add(-time.Hour))},
+		{name: "Token without id", claims: claims("", "7", revokedAt.Add(-time.Hour))},
+		{name: "Issued before the user was revoked", claims: claims("old", "42", revokedAt.Add(-time.Minute)), expected: true},
+		{name: "Issued when the user was revoked", claims: claims("old", "42", revokedAt), expected: true},
+		{name: "Issued after the user was revoked", claims: claims("new", "42", revokedAt.Add(time.Second))},
+		{name: "Whole-second iat in the second of the revocation", claims: claims("new", "43", revokedAt.Add(700*time.Millisecond)), expected: true},
+		{name: "Fractional iat just after the revocation", claims: fractional},
+		{name: "Revoked user without iat", claims: claims("unknown", "42", time.Time{}), expected: true},
+		{name: "Other user without iat", claims: claims("unknown", "7", time.Time{})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			revoked, err := service.IsRevoked(context.Background(), tt.claims)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, revoked)
+		})
+	}
+}
+
+func TestRevocationService_IsRevoked_StorageError(t *testing.T) {
+	storage := newMemoryRevocations()
+	storage.err = errRedisDown
+	service := NewRevocationService(storage, config.NewManager(&config.Config{JWTLeeway: 30 * time.Second}))
+
+	for _, claims := range []*auth.UserClaims{
+		{RegisteredClaims: jwt.RegisteredClaims{ID: "token-1"}, UserId: "42"},
+		{UserId: "42"},
+	} {
+		revoked, err := service.IsRevoked(context.Background(), claims)
+
+		assert.ErrorIs(t, err, errRedisDown)
+		assert.False(t, revoked)
+	}
+}
+
+func TestRevocationService_RevokeToken(t *testing.T) {
+	storage := newMemoryRevocations()
+	service := NewRevocationService(storage, config.NewManager(&config.Config{JWTLeeway: 30 * time.Second}))
+	ctx := context.Background()
+
+	assert.ErrorIs(t, service.RevokeToken(ctx, "token-1", time.Time{}), ErrRevocationExpiryRequired)
+
+	require.NoError(t, service.RevokeToken(ctx, "expired", time.Now().Add(-time.Minute)))
+	assert.NotContains(t, storage.tokens, "expired", "tokens past exp and leeway need no revocation")
+
+	require.NoError(t, service.RevokeToken(ctx, "token-1", time.Now().Add(time.Hour)))
+	assert.InDelta(t, time.Hour+30*time.Second, storage.tokens["token-1"], float64(time.Second), "revocation outlives the token")
+
+	// Tokens are accepted until JWT_LEEWAY past exp, so a revocation near exp
+	// must last through the leeway.
+	require.NoError(t, service.RevokeToken(ctx, "near-exp", time.Now().Add(time.Second)))
+	assert.InDelta(t, 31*time.Second, storage.tokens["near-exp"], float64(time.Second))
+
+	require.NoError(t, service.RevokeToken(ctx, "within-leeway", time.Now().Add(-10*time.Second)))
+	assert.InDelta(t, 20*time.Second, storage.tokens["within-leeway"], float64(time.Second))
+
+	revoked, err := service.IsRevoked(ctx, &auth.UserClaims{RegisteredClaims: jwt.RegisteredClaims{ID: "within-leeway"}, UserId: "42"})
+	require.NoError(t, err)
+	assert.True(t, revoked, "an expired token inside the leeway is still rejected")
+
+	revoked, err = service.IsRevoked(ctx, &auth.UserClaims{RegisteredClaims: jwt.RegisteredClaims{ID: "token-1"}, UserId: "42"})
+	require.NoError(t, err)
+	assert.True(t, revoked)
+}
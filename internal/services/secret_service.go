@@ -1,33 +1,53 @@
 package services
 
 import (
+	"cluster-agent/internal/config"
+	"cluster-agent/internal/k8s"
 	"cluster-agent/internal/models"
 	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"maps"
 
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/kubernetes"
 )
 
 type SecretService interface {
 	List(ctx context.Context, namespace string) ([]models.SecretListInfo, error)
 	Get(ctx context.Context, namespace, name string) (*models.SecretDetails, error)
+	Reveal(ctx context.Context, namespace, name, key string) (*models.SecretValue, error)
 }
 
 type secretService struct {
-	clientset kubernetes.Interface
+	clients k8s.ClientProvider
+	configs *config.Manager
+	// randomKey keys digests while SecretDigestKey is unset.
+	randomKey []byte
 }
 
-func NewSecretService(c kubernetes.Interface) SecretService {
+func NewSecretService(clients k8s.ClientProvider, configs *config.Manager) SecretService {
+	randomKey := make([]byte, sha256.Size)
+	rand.Read(randomKey)
+
 	return &secretService{
-		clientset: c,
+		clients:   clients,
+		configs:   configs,
+		randomKey: randomKey,
 	}
 }
 
 func (s *secretService) List(ctx context.Context, namespace string) ([]models.SecretListInfo, error) {
-	list, err := s.clientset.CoreV1().Secrets(namespace).List(ctx, metav1.ListOptions{})
+	clientset, err := s.clients.Clientset(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := clientset.CoreV1().Secrets(namespace).List(ctx, metav1.ListOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("failed list secrets: %w", err)
 	}
@@ -39,25 +59,90 @@ func (s *secretService) List(ctx context.Context, namespace string) ([]models.Se
 	return result, nil
 }
 
+// Get returns the secret with masked values, revealing them requires Reveal.
 func (s *secretService) Get(ctx context.Context, namespace, name string) (*models.SecretDetails, error) {
-	item, err := s.clientset.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+	item, err := s.get(ctx, namespace, name)
 	if err != nil {
-		if k8serrors.IsNotFound(err) {
-			return nil, ErrNotFound
+		return nil, err
+	}
+
+	digestKey := s.randomKey
+	if key := s.configs.Current().SecretDigestKey; key != "" {
+		digestKey = []byte(key)
+	}
+
+	data := make(map[string]models.SecretKeyInfo, len(item.Data))
+	for key, value := range item.Data {
+		mac := hmac.New(sha256.New, digestKey)
+		mac.Write(value)
+		data[key] = models.SecretKeyInfo{
+			Value:  models.MaskedSecretValue,
+			Size:   len(value),
+			Digest: hex.EncodeToString(mac.Sum(nil)),
 		}
-		return nil, fmt.Errorf("failed to get secret: %w", err)
 	}
 
 	return &models.SecretDetails{
 		SecretListInfo: s.mapToListInfo(item),
-		Data:           item.Data,
+		Data:           data,
 		UID:            string(item.UID),
 		Labels:         item.Labels,
-		Annotations:    item.Annotations,
+		Annotations:    maskAnnotations(item.Annotations),
 		Immutable:      item.Immutable,
 	}, nil
 }
 
+// dataAnnotations hold a copy of the secret's data, kubectl apply stores the
+// whole manifest in last-applied-configuration.
+var dataAnnotations = []string{
+	corev1.LastAppliedConfigAnnotation,
+}
+
+func maskAnnotations(annotations map[string]string) map[string]string {
+	masked := maps.Clone(annotations)
+	for _, key := range dataAnnotations {
+		if _, ok := masked[key]; ok {
+			masked[key] = models.MaskedSecretValue
+		}
+	}
+
+	return masked
+}
+
+func (s *secretService) Reveal(ctx context.Context, namespace, name, key string) (*models.SecretValue, error) {
+	item, err := s.get(ctx, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+
+	value, ok := item.Data[key]
+	if !ok {
+		return nil, fmt.Errorf("%w: key %q", ErrNotFound, key)
+	}
+
+	return &models.SecretValue{
+		Key:   key,
+		Value: value,
+	}, nil
+}
+
+func (s *secretService) get(ctx context.Context, namespace, name string) (*corev1.Secret, error) {
+	clientset, err := s.clients.Clientset(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := clientset.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get secret: %w", err)
+	}
+
+	return item, nil
+}
+
 func (s *secretService) mapToListInfo(item *corev1.Secret) models.SecretListInfo {
 	keys := make([]string, 0, len(item.Data))
 	for k := range item.Data {
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"cluster-agent/internal/config"
+	"cluster-agent/internal/models"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+)
+
+type fakeClientProvider struct {
+	clientset kubernetes.Interface
+}
+
+func (p fakeClientProvider) Clientset(context.Context) (kubernetes.Interface, error) {
+	return p.clientset, nil
+}
+
+func (p fakeClientProvider) Config(context.Context) (*rest.Config, error) {
+	return &rest.Config{}, nil
+}
+
+func TestSecretService_Get_Digests(t *testing.T) {
+	const digestKey = "0123456789abcdef0123456789abcdef"
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"},
+		Data: map[string][]byte{
+			"password": []byte("1234"),
+			"copy":     []byte("1234"),
+			"user":     []byte("admin"),
+		},
+	}
+	clients := fakeClientProvider{clientset: fake.NewClientset(secret)}
+
+	get := func(t *testing.T, service SecretService) map[string]models.SecretKeyInfo {
+		t.Helper()
+		details, err := service.Get(context.Background(), "default", "db")
+		require.NoError(t, err)
+		return details.Data
+	}
+
+	t.Run("Configured key", func(t *testing.T) {
+		data := get(t, NewSecretService(clients, config.NewManager(&config.Config{SecretDigestKey: digestKey})))
+
+		mac := hmac.New(sha256.New, []byte(digestKey))
+		mac.Write([]byte("1234"))
+		assert.Equal(t, hex.EncodeToString(mac.Sum(nil)), data["password"].Digest)
+
+		plain := sha256.Sum256([]byte("1234"))
+		assert.NotEqual(t, hex.EncodeToString(plain[:]), data["password"].Digest)
+
+		assert.Equal(t, models.MaskedSecretValue, data["password"].Value)
+		assert.Equal(t, 4, data["password"].Size)
+		assert.Equal(t, data["password"].Digest, data["copy"].Digest)
+		assert.NotEqual(t, data["password"].Digest, data["user"].Digest)
+	})
+
+	t.Run("Same key gives stable digests across agents", func(t *testing.T) {
+		first := get(t, NewSecretService(clients, config.NewManager(&config.Config{SecretDigestKey: digestKey})))
+		second := get(t, NewSecretService(clients, config.NewManager(&config.Config{SecretDigestKey: digestKey})))
+		assert.Equal(t, first["password"].Digest, second["password"].Digest)
+	})
+
+	t.Run("Random key without configuration", func(t *testing.T) {
+		configs := config.NewManager(&config.Config{})
+		service := NewSecretService(clients, configs)
+
+		data := get(t, service)
+		assert.Equal(t, data["password"].Digest, data["copy"].Digest)
+		assert.Equal(t, data["password"].Digest, get(t, service)["password"].Digest)
+		assert.NotEqual(t, data["password"].Digest, get(t, NewSecretService(clients, configs))["password"].Digest)
+	})
+}
+
+func TestSecretService_Get_MasksDataAnnotations(t *testing.T) {
+	values := map[string][]byte{
+		"password": []byte("hunter2-password"),
+		"token":    []byte("s3cr3t-token"),
+	}
+
+	lastApplied, err := json.Marshal(map[string]any{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata":   map[string]any{"name": "db", "namespace": "default"},
+		"data":       map[string][]byte{"password": values["password"]},
+		"stringData": map[string]string{"token": string(values["token"])},
+	})
+	require.NoError(t, err)
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "db",
+			Namespace: "default",
+			Annotations: map[string]string{
+				corev1.LastAppliedConfigAnnotation: string(lastApplied),
+				"team":                             "payments",
+			},
+		},
+		Data: values,
+	}
+	clients := fakeClientProvider{clientset: fake.NewClientset(secret)}
+	service := NewSecretService(clients, config.NewManager(&config.Config{}))
+
+	details, err := service.Get(context.Background(), "default", "db")
+	require.NoError(t, err)
+
+	assert.Equal(t, models.MaskedSecretValue, details.Annotations[corev1.LastAppliedConfigAnnotation])
+	assert.Equal(t, "payments", details.Annotations["team"])
+
+	response, err := json.Marshal(details)
+	require.NoError(t, err)
+	for _, value := range values {
+		assert.NotContains(t, string(response), string(value))
+		assert.NotContains(t, string(response), base64.StdEncoding.EncodeToString(value))
+	}
+}
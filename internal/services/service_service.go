@@ -1,13 +1,13 @@
 package services
 
 import (
+	"cluster-agent/internal/k8s"
 	"cluster-agent/internal/models"
 	"context"
 	"fmt"
 
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/kubernetes"
 )
 
 type KubernetesServiceService interface {
@@ -16,17 +16,22 @@ type KubernetesServiceService interface {
 }
 
 type service struct {
-	clientset kubernetes.Interface
+	clients k8s.ClientProvider
 }
 
-func NewServiceService(clientset kubernetes.Interface) KubernetesServiceService {
+func NewServiceService(clients k8s.ClientProvider) KubernetesServiceService {
 	return &service{
-		clientset: clientset,
+		clients: clients,
 	}
 }
 
 func (s *service) List(ctx context.Context, namespace string) ([]models.ServiceInfo, error) {
-	list, err := s.clientset.CoreV1().Services(namespace).List(ctx, metav1.ListOptions{})
+	clientset, err := s.clients.Clientset(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := clientset.CoreV1().Services(namespace).List(ctx, metav1.ListOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to list services: %w", err)
 	}
@@ -51,7 +56,12 @@ func (s *service) List(ctx context.Context, namespace string) ([]models.ServiceI
 }
 
 func (s *service) Get(ctx context.Context, namespace, name string) (*models.ServiceDetails, error) {
-	svc, err := s.clientset.CoreV1().Services(namespace).Get(ctx, name, metav1.GetOptions{})
+	clientset, err := s.clients.Clientset(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	svc, err := clientset.CoreV1().Services(namespace).Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
 		if k8serrors.IsNotFound(err) {
 			return nil, ErrNotFound
@@ -1,29 +1,59 @@
 package services
 
 import (
-	"k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/rest"
+	"cluster-agent/internal/config"
+	"cluster-agent/internal/k8s"
+	"context"
+
 	"k8s.io/client-go/tools/remotecommand"
 )
 
+const defaultShell = "/bin/sh"
+
 type TerminalService interface {
-	GetAuthExecutor(namespace, podName, container string) (remotecommand.Executor, error)
+	// Authorize checks the command against the namespace exec policy before
+	// the connection is upgraded. An empty command asks for a shell.
+	Authorize(namespace string, command []string) error
+	GetAuthExecutor(ctx context.Context, namespace, podName, container string, command []string) (remotecommand.Executor, error)
 }
 
 type terminalService struct {
-	clientset kubernetes.Interface
-	config    *rest.Config
+	clients k8s.ClientProvider
+	configs *config.Manager
 }
 
-func NewTerminalService(clientset kubernetes.Interface, config *rest.Config) TerminalService {
+func NewTerminalService(clients k8s.ClientProvider, configs *config.Manager) TerminalService {
 	return &terminalService{
-		clientset: clientset,
-		config:    config,
+		clients: clients,
+		configs: configs,
 	}
 }
 
-func (t *terminalService) GetAuthExecutor(namespace, podName, container string) (remotecommand.Executor, error) {
-	req := t.clientset.CoreV1().RESTClient().Get().
+func (t *terminalService) Authorize(namespace string, command []string) error {
+	return authorizeExec(t.configs.Current().ExecPolicies, namespace, command)
+}
+
+// GetAuthExecutor re-checks the policy, so callers cannot skip Authorize.
+func (t *terminalService) GetAuthExecutor(ctx context.Context, namespace, podName, container string, command []string) (remotecommand.Executor, error) {
+	if err := t.Authorize(namespace, command); err != nil {
+		return nil, err
+	}
+
+	if len(command) == 0 {
+		command = []string{defaultShell}
+	}
+
+	clientset, err := t.clients.Clientset(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := t.clients.Config(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	req := clientset.CoreV1().RESTClient().Get().
 		Resource("pods").
 		Name(podName).
 		Namespace(namespace).
@@ -32,8 +62,11 @@ func (t *terminalService) GetAuthExecutor(namespace, podName, container string)
 		Param("stdin", "true").
 		Param("stdout", "true").
 		Param("stderr", "true").
-		Param("tty", "true").
-		Param("command", "/bin/sh")
+		Param("tty", "true")
+
+	for _, arg := range command {
+		req.Param("command", arg)
+	}
 
-	return remotecommand.NewWebSocketExecutor(t.config, "GET", req.URL().String())
+	return remotecommand.NewWebSocketExecutor(config, "GET", req.URL().String())
 }
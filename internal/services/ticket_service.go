@@ -0,0 +1,102 @@
+package services
+
+import (
+	"cluster-agent/internal/auth"
+	"cluster-agent/internal/models"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const ticketTTL = 30 * time.Second
+
+var ErrInvalidTicket = errors.New("invalid or expired ticket")
+
+type (
+	// TicketService exchanges a bearer token for a short-lived, single-use
+	// ticket, so browser WebSockets never put the token in a URL.
+	TicketService interface {
+		Issue(ctx context.Context, claims *auth.UserClaims, params models.CreateTicketParams) (*models.WebSocketTicket, error)
+		Redeem(ctx context.Context, ticket string, binding models.CreateTicketParams) (*auth.UserClaims, error)
+	}
+
+	TicketStorage interface {
+		SaveTicket(ctx context.Context, key string, value []byte, ttl time.Duration) error
+		// TakeTicket returns and deletes the ticket, or nil when it does not exist.
+		TakeTicket(ctx context.Context, key string) ([]byte, error)
+	}
+)
+
+type ticketRecord struct {
+	Claims  *auth.UserClaims          `json:"claims"`
+	Binding models.CreateTicketParams `json:"binding"`
+}
+
+type ticketService struct {
+	storage TicketStorage
+}
+
+func NewTicketService(storage TicketStorage) TicketService {
+	return &ticketService{
+		storage: storage,
+	}
+}
+
+func (s *ticketService) Issue(ctx context.Context, claims *auth.UserClaims, params models.CreateTicketParams) (*models.WebSocketTicket, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("failed to generate ticket: %w", err)
+	}
+
+	ticket := base64.RawURLEncoding.EncodeToString(secret)
+
+	data, err := json.Marshal(ticketRecord{Claims: claims, Binding: params})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode ticket: %w", err)
+	}
+
+	if err := s.storage.SaveTicket(ctx, ticketKey(ticket), data, ticketTTL); err != nil {
+		return nil, fmt.Errorf("failed to save ticket: %w", err)
+	}
+
+	return &models.WebSocketTicket{
+		Ticket:    ticket,
+		ExpiresAt: time.Now().Add(ticketTTL),
+	}, nil
+}
+
+// Redeem consumes the ticket even when it was presented for the wrong route,
+// so a leaked ticket cannot be retried against other pods.
+func (s *ticketService) Redeem(ctx context.Context, ticket string, binding models.CreateTicketParams) (*auth.UserClaims, error) {
+	data, err := s.storage.TakeTicket(ctx, ticketKey(ticket))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ticket: %w", err)
+	}
+
+	if data == nil {
+		return nil, ErrInvalidTicket
+	}
+
+	var record ticketRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("failed to decode ticket: %w", err)
+	}
+
+	if record.Claims == nil || record.Binding != binding {
+		return nil, ErrInvalidTicket
+	}
+
+	return record.Claims, nil
+}
+
+// ticketKey stores tickets by digest, so a Redis dump holds no usable tickets.
+func ticketKey(ticket string) string {
+	sum := sha256.Sum256([]byte(ticket))
+	return hex.EncodeToString(sum[:])
+}
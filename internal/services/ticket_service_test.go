@@ -0,0 +1,199 @@
+package services
+
+import (
+	"cluster-agent/internal/auth"
+	"cluster-agent/internal/auth/permissions"
+	"cluster-agent/internal/models"
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type storedTicket struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+// memoryTickets is an in-memory TicketStorage taking tickets like GETDEL.
+// Tickets expire against now, which tests move forward.
+type memoryTickets struct {
+	mu      sync.Mutex
+	now     time.Time
+	tickets map[string]storedTicket
+	err     error
+}
+
+func newMemoryTickets() *memoryTickets {
+	return &memoryTickets{
+		now:     time.Now(),
+		tickets: make(map[string]storedTicket),
+	}
+}
+
+func (s *memoryTickets) SaveTicket(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tickets[key] = storedTicket{value: value, expiresAt: s.now.Add(ttl)}
+	return nil
+}
+
+func (s *memoryTickets) TakeTicket(_ context.Context, key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	ticket, ok := s.tickets[key]
+	delete(s.tickets, key)
+	if !ok || !s.now.Before(ticket.expiresAt) {
+		return nil, nil
+	}
+
+	return ticket.value, nil
+}
+
+func (s *memoryTickets) advance(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.now = s.now.Add(d)
+}
+
+func TestTicketService_Redeem(t *testing.T) {
+	claims := &auth.UserClaims{UserId: "42", Permissions: []permissions.Permission{permissions.PodsView}}
+	binding := models.CreateTicketParams{Route: models.TicketRoutePodLogs, Namespace: "payments", Pod: "web"}
+
+	issue := func(t *testing.T, service TicketService) string {
+		t.Helper()
+		ticket, err := service.Issue(context.Background(), claims, binding)
+		require.NoError(t, err)
+		return ticket.Ticket
+	}
+
+	type testCase struct {
+		name    string
+		binding models.CreateTicketParams
+	}
+
+	mismatches := []testCase{
+		{name: "Other route", binding: models.CreateTicketParams{Route: models.TicketRoutePodExec, Namespace: "payments", Pod: "web"}},
+		{name: "Other namespace", binding: models.CreateTicketParams{Route: models.TicketRoutePodLogs, Namespace: "default", Pod: "web"}},
+		{name: "Other pod", binding: models.CreateTicketParams{Route: models.TicketRoutePodLogs, Namespace: "payments", Pod: "db"}},
+	}
+
+	t.Run("Matching binding returns the claims", func(t *testing.T) {
+		service := NewTicketService(newMemoryTickets())
+
+		redeemed, err := service.Redeem(context.Background(), issue(t, service), binding)
+		require.NoError(t, err)
+		assert.Equal(t, claims, redeemed)
+	})
+
+	t.Run("Tickets are single use", func(t *testing.T) {
+		service := NewTicketService(newMemoryTickets())
+		ticket := issue(t, service)
+
+		_, err := service.Redeem(context.Background(), ticket, binding)
+		require.NoError(t, err)
+
+		_, err = service.Redeem(context.Background(), ticket, binding)
+		assert.ErrorIs(t, err, ErrInvalidTicket)
+	})
+
+	t.Run("Concurrent redemptions succeed once", func(t *testing.T) {
+		service := NewTicketService(newMemoryTickets())
+		ticket := issue(t, service)
+
+		var wg sync.WaitGroup
+		var redeemed atomic.Int32
+		for range 10 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := service.Redeem(context.Background(), ticket, binding); err == nil {
+					redeemed.Add(1)
+				}
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, int32(1), redeemed.Load())
+	})
+
+	t.Run("Expired ticket", func(t *testing.T) {
+		storage := newMemoryTickets()
+		service := NewTicketService(storage)
+		ticket := issue(t, service)
+
+		storage.advance(ticketTTL)
+
+		_, err := service.Redeem(context.Background(), ticket, binding)
+		assert.ErrorIs(t, err, ErrInvalidTicket)
+	})
+
+	t.Run("Unknown ticket", func(t *testing.T) {
+		service := NewTicketService(newMemoryTickets())
+
+		_, err := service.Redeem(context.Background(), "forged", binding)
+		assert.ErrorIs(t, err, ErrInvalidTicket)
+	})
+
+	for _, tt := range mismatches {
+		t.Run(tt.name+" is rejected and burns the ticket", func(t *testing.T) {
+			service := NewTicketService(newMemoryTickets())
+			ticket := issue(t, service)
+
+			_, err := service.Redeem(context.Background(), ticket, tt.binding)
+			assert.ErrorIs(t, err, ErrInvalidTicket)
+
+			_, err = service.Redeem(context.Background(), ticket, binding)
+			assert.ErrorIs(t, err, ErrInvalidTicket)
+		})
+	}
+
+	t.Run("Storage errors are not invalid tickets", func(t *testing.T) {
+		storage := newMemoryTickets()
+		service := NewTicketService(storage)
+		ticket := issue(t, service)
+
+		storage.err = errRedisDown
+
+		_, err := service.Redeem(context.Background(), ticket, binding)
+		assert.ErrorIs(t, err, errRedisDown)
+		assert.NotErrorIs(t, err, ErrInvalidTicket)
+	})
+}
+
+func TestTicketService_Issue(t *testing.T) {
+	storage := newMemoryTickets()
+	service := NewTicketService(storage)
+
+	ticket, err := service.Issue(context.Background(), &auth.UserClaims{UserId: "42"}, models.CreateTicketParams{Route: models.TicketRouteEvents})
+	require.NoError(t, err)
+
+	assert.WithinDuration(t, time.Now().Add(ticketTTL), ticket.ExpiresAt, time.Second)
+	assert.NotContains(t, storage.tickets, ticket.Ticket, "tickets are stored by digest")
+	assert.Contains(t, storage.tickets, ticketKey(ticket.Ticket))
+}
+
+func TestTicketService_EventStreamTicketIsSingleUse(t *testing.T) {
+	service := NewTicketService(newMemoryTickets())
+	binding := models.CreateTicketParams{Route: models.TicketRouteEvents}
+
+	ticket, err := service.Issue(context.Background(), &auth.UserClaims{UserId: "42"}, binding)
+	require.NoError(t, err)
+
+	_, err = service.Redeem(context.Background(), ticket.Ticket, binding)
+	require.NoError(t, err)
+
+	_, err = service.Redeem(context.Background(), ticket.Ticket, binding)
+	assert.ErrorIs(t, err, ErrInvalidTicket, "a reconnecting stream needs a new ticket")
+}
@@ -0,0 +1,140 @@
+package internal
+
+import (
+	"bytes"
+	"cluster-agent/internal/config"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// newTLSConfig returns nil when TLS is not configured. With a client CA,
+// certificates are verified when presented but remain optional, so callers
+// without one can still authenticate with a token. The server certificate
+// comes from the returned reloader, which must be run to follow rotations.
+func newTLSConfig(cfg *config.Config) (*tls.Config, *certificateReloader, error) {
+	if cfg.TLSCertPath == "" {
+		return nil, nil, nil
+	}
+
+	certificates, err := newCertificateReloader(cfg.TLSCertPath, cfg.TLSKeyPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tlsConfig := &tls.Config{
+		GetCertificate: certificates.GetCertificate,
+		MinVersion:     cfg.TLSMinVersion,
+	}
+
+	if cfg.TLSClientCAPath != "" {
+		caPEM, err := os.ReadFile(cfg.TLSClientCAPath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read client CA: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, nil, fmt.Errorf("no certificates found in client CA %s", cfg.TLSClientCAPath)
+		}
+
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+
+	return tlsConfig, certificates, nil
+}
+
+// certificateReloader serves the certificate and key files as they are now,
+// so rotations by cert-manager or similar tools need no restart. Handshakes
+// already in progress keep the certificate they started with.
+type certificateReloader struct {
+	certPath string
+	keyPath  string
+	current  atomic.Pointer[tls.Certificate]
+
+	// certPEM and keyPEM are the contents last loaded successfully.
+	certPEM []byte
+	keyPEM  []byte
+}
+
+func newCertificateReloader(certPath, keyPath string) (*certificateReloader, error) {
+	r := &certificateReloader{
+		certPath: certPath,
+		keyPath:  keyPath,
+	}
+
+	if _, err := r.reload(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (r *certificateReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return r.current.Load(), nil
+}
+
+// Run checks the files every interval until ctx is canceled. A zero interval
+// keeps the certificate loaded at startup.
+func (r *certificateReloader) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reloaded, err := r.reload()
+			if err != nil {
+				// The files may be mid-rotation, with the certificate written
+				// but not yet the key; the next tick retries.
+				log.Printf("TLS certificate reload failed, keeping the current one: %v", err)
+				continue
+			}
+
+			if reloaded {
+				log.Printf("TLS certificate reloaded, expires at %s", r.current.Load().Leaf.NotAfter.Format(time.RFC3339))
+			}
+		}
+	}
+}
+
+// reload loads the files when they differ from the last loaded contents and
+// reports whether the certificate was replaced.
+func (r *certificateReloader) reload() (bool, error) {
+	certPEM, err := os.ReadFile(r.certPath)
+	if err != nil {
+		return false, fmt.Errorf("failed to read TLS certificate: %w", err)
+	}
+
+	keyPEM, err := os.ReadFile(r.keyPath)
+	if err != nil {
+		return false, fmt.Errorf("failed to read TLS key: %w", err)
+	}
+
+	if bytes.Equal(certPEM, r.certPEM) && bytes.Equal(keyPEM, r.keyPEM) {
+		return false, nil
+	}
+
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return false, fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+
+	r.current.Store(&cert)
+	r.certPEM = certPEM
+	r.keyPEM = keyPEM
+
+	return true, nil
+}
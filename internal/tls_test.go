@@ -0,0 +1,210 @@
+package internal
+
+import (
+	"cluster-agent/internal/config"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testCertificate returns a self-signed certificate and its key as PEM,
+// identified by serial.
+func testCertificate(t *testing.T, serial int64) ([]byte, []byte) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "cluster-agent"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IsCA:         true,
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+
+		BasicConstraintsValid: true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+}
+
+func writeCertificate(t *testing.T, certPath, keyPath string, serial int64) {
+	t.Helper()
+
+	certPEM, keyPEM := testCertificate(t, serial)
+	require.NoError(t, os.WriteFile(certPath, certPEM, 0o600))
+	require.NoError(t, os.WriteFile(keyPath, keyPEM, 0o600))
+}
+
+func servedSerial(t *testing.T, r *certificateReloader) int64 {
+	t.Helper()
+
+	cert, err := r.GetCertificate(nil)
+	require.NoError(t, err)
+	return cert.Leaf.SerialNumber.Int64()
+}
+
+func TestCertificateReloader_Reload(t *testing.T) {
+	dir := t.TempDir()
+	certPath := filepath.Join(dir, "tls.crt")
+	keyPath := filepath.Join(dir, "tls.key")
+	writeCertificate(t, certPath, keyPath, 1)
+
+	reloader, err := newCertificateReloader(certPath, keyPath)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), servedSerial(t, reloader))
+
+	t.Run("Unchanged files are not reloaded", func(t *testing.T) {
+		reloaded, err := reloader.reload()
+		require.NoError(t, err)
+		assert.False(t, reloaded)
+	})
+
+	t.Run("Rotated files replace the certificate", func(t *testing.T) {
+		writeCertificate(t, certPath, keyPath, 2)
+
+		reloaded, err := reloader.reload()
+		require.NoError(t, err)
+		assert.True(t, reloaded)
+		assert.Equal(t, int64(2), servedSerial(t, reloader))
+	})
+
+	t.Run("Half-written rotation keeps the current certificate", func(t *testing.T) {
+		certPEM, _ := testCertificate(t, 3)
+		require.NoError(t, os.WriteFile(certPath, certPEM, 0o600))
+
+		_, err := reloader.reload()
+		assert.ErrorContains(t, err, "failed to load TLS certificate")
+		assert.Equal(t, int64(2), servedSerial(t, reloader))
+	})
+
+	t.Run("Missing key keeps the current certificate", func(t *testing.T) {
+		require.NoError(t, os.Remove(keyPath))
+
+		_, err := reloader.reload()
+		assert.ErrorContains(t, err, "failed to read TLS key")
+		assert.Equal(t, int64(2), servedSerial(t, reloader))
+	})
+}
+
+func TestCertificateReloader_Run(t *testing.T) {
+	dir := t.TempDir()
+	certPath := filepath.Join(dir, "tls.crt")
+	keyPath := filepath.Join(dir, "tls.key")
+	writeCertificate(t, certPath, keyPath, 1)
+
+	reloader, err := newCertificateReloader(certPath, keyPath)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		reloader.Run(ctx, 10*time.Millisecond)
+		close(done)
+	}()
+
+	writeCertificate(t, certPath, keyPath, 2)
+	assert.Eventually(t, func() bool {
+		return servedSerial(t, reloader) == 2
+	}, time.Second, 10*time.Millisecond)
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after ctx was canceled")
+	}
+}
+
+func TestNewTLSConfig(t *testing.T) {
+	dir := t.TempDir()
+	certPath := filepath.Join(dir, "tls.crt")
+	keyPath := filepath.Join(dir, "tls.key")
+	writeCertificate(t, certPath, keyPath, 1)
+
+	caPath := filepath.Join(dir, "ca.crt")
+	caPEM, _ := testCertificate(t, 10)
+	require.NoError(t, os.WriteFile(caPath, caPEM, 0o600))
+
+	badCAPath := filepath.Join(dir, "bad-ca.crt")
+	require.NoError(t, os.WriteFile(badCAPath, []byte("not a certificate"), 0o600))
+
+	type testCase struct {
+		name          string
+		cfg           *config.Config
+		verify        func(t *testing.T, tlsConfig *tls.Config)
+		expectedError string
+	}
+
+	tests := []testCase{
+		{
+			name: "TLS disabled",
+			cfg:  &config.Config{},
+			verify: func(t *testing.T, tlsConfig *tls.Config) {
+				assert.Nil(t, tlsConfig)
+			},
+		},
+		{
+			name: "Server certificate only",
+			cfg:  &config.Config{TLSCertPath: certPath, TLSKeyPath: keyPath, TLSMinVersion: tls.VersionTLS13},
+			verify: func(t *testing.T, tlsConfig *tls.Config) {
+				assert.Equal(t, uint16(tls.VersionTLS13), tlsConfig.MinVersion)
+				assert.Equal(t, tls.NoClientCert, tlsConfig.ClientAuth)
+				assert.Nil(t, tlsConfig.ClientCAs)
+			},
+		},
+		{
+			name: "Optional client certificates",
+			cfg:  &config.Config{TLSCertPath: certPath, TLSKeyPath: keyPath, TLSClientCAPath: caPath},
+			verify: func(t *testing.T, tlsConfig *tls.Config) {
+				assert.Equal(t, tls.VerifyClientCertIfGiven, tlsConfig.ClientAuth)
+				assert.NotNil(t, tlsConfig.ClientCAs)
+			},
+		},
+		{
+			name:          "Client CA without certificates",
+			cfg:           &config.Config{TLSCertPath: certPath, TLSKeyPath: keyPath, TLSClientCAPath: badCAPath},
+			expectedError: "no certificates found in client CA",
+		},
+		{
+			name:          "Missing key",
+			cfg:           &config.Config{TLSCertPath: certPath, TLSKeyPath: filepath.Join(dir, "missing.key")},
+			expectedError: "failed to read TLS key",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tlsConfig, _, err := newTLSConfig(tt.cfg)
+
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			tt.verify(t, tlsConfig)
+		})
+	}
+}